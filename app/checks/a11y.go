@@ -0,0 +1,393 @@
+package checks
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/govenue/fsintra"
+)
+
+// The rules checked by CheckA11y.
+const (
+	RuleImageAlt      = "image-alt"
+	RuleHeadingOrder  = "heading-order"
+	RuleLinkName      = "link-name"
+	RuleHTMLLang      = "html-lang"
+	RuleDuplicateID   = "duplicate-id"
+	RuleMediaCaptions = "media-captions"
+	RuleColorContrast = "color-contrast"
+)
+
+// minContrastRatio is the WCAG AA minimum contrast ratio for normal text.
+const minContrastRatio = 4.5
+
+// A11yIssue is an accessibility issue found in a page.
+type A11yIssue struct {
+	// The 1-based line of the element, 0 if it applies to the whole page.
+	Line int `json:"line"`
+
+	// One of the Rule constants.
+	Rule string `json:"rule"`
+
+	Message string `json:"message"`
+}
+
+func (i A11yIssue) String() string {
+	return fmt.Sprintf("%d: %s: %s", i.Line, i.Rule, i.Message)
+}
+
+// A11yReport is the accessibility issues found in a page.
+type A11yReport struct {
+	// The HTML file, relative to the publish dir.
+	Filename string `json:"filename"`
+
+	Issues []A11yIssue `json:"issues"`
+}
+
+// CheckA11y audits every HTML file in publishDir and returns a report for
+// every page with issues, ordered by filename. Redirect pages, e.g. the
+// alias pages, are skipped.
+func CheckA11y(fs fsintra.Fs, publishDir string) ([]*A11yReport, error) {
+	var reports []*A11yReport
+
+	walker := func(filename string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !isHTML(filename) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(publishDir, filename)
+		if err != nil {
+			return err
+		}
+
+		b, err := fsintra.ReadFile(fs, filename)
+		if err != nil {
+			return err
+		}
+
+		if issues := CheckA11yDocument(ParseHTML(b)); len(issues) > 0 {
+			reports = append(reports, &A11yReport{Filename: filepath.ToSlash(rel), Issues: issues})
+		}
+
+		return nil
+	}
+
+	if err := fsintra.Walk(fs, publishDir, walker); err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// a11yLink is an open <a> element.
+type a11yLink struct {
+	line    int
+	hasName bool
+}
+
+// a11yMedia is an open <audio> or <video> element.
+type a11yMedia struct {
+	tok         Token
+	hasCaptions bool
+}
+
+// CheckA11yDocument audits the given document and returns the issues found,
+// in document order. Nil is returned for redirect pages.
+//
+// Only inline styles that set both the foreground and the background color
+// of an element are checked for contrast, as anything else depends on the
+// stylesheets.
+func CheckA11yDocument(doc *Document) []A11yIssue {
+	var (
+		issues []A11yIssue
+
+		hasHTML      bool
+		lastHeading  int
+		ids          = make(map[string]bool)
+		link         *a11yLink
+		media        *a11yMedia
+		mediaIssues  []A11yIssue
+		describedIDs []string
+	)
+
+	add := func(line int, rule, format string, args ...interface{}) {
+		issues = append(issues, A11yIssue{Line: line, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, tok := range doc.Tokens {
+		switch tok.Type {
+		case Text:
+			if link != nil && strings.TrimSpace(tok.Data) != "" {
+				link.hasName = true
+			}
+			continue
+		case EndTag:
+			switch tok.Name {
+			case "a":
+				if link != nil && !link.hasName {
+					add(link.line, RuleLinkName, "link has no text")
+				}
+				link = nil
+			case "audio", "video":
+				if media != nil && media.tok.Name == tok.Name {
+					if !media.hasCaptions {
+						mediaIssues = append(mediaIssues, mediaIssue(media.tok))
+						describedIDs = append(describedIDs, mediaDescribedBy(media.tok))
+					}
+					media = nil
+				}
+			}
+			continue
+		}
+
+		if isRefresh(tok) {
+			return nil
+		}
+
+		if id, found := tok.Attr("id"); found && id != "" {
+			if ids[id] {
+				add(tok.Line, RuleDuplicateID, "duplicate id %q", id)
+			}
+			ids[id] = true
+		}
+
+		if style, found := tok.Attr("style"); found {
+			if ratio, ok := inlineContrast(style); ok && ratio < minContrastRatio {
+				add(tok.Line, RuleColorContrast, "<%s> has a contrast ratio of %.2f:1, at least %.1f:1 is needed", tok.Name, ratio, minContrastRatio)
+			}
+		}
+
+		switch tok.Name {
+		case "html":
+			if !hasHTML {
+				hasHTML = true
+				if lang, _ := tok.Attr("lang"); strings.TrimSpace(lang) == "" {
+					add(tok.Line, RuleHTMLLang, "<html> has no lang attribute")
+				}
+			}
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			level := int(tok.Name[1] - '0')
+			if lastHeading > 0 && level > lastHeading+1 {
+				add(tok.Line, RuleHeadingOrder, "heading level skipped from h%d to h%d", lastHeading, level)
+			}
+			lastHeading = level
+		case "img":
+			if isHidden(tok) {
+				break
+			}
+			if _, found := tok.Attr("alt"); !found && !hasLabel(tok) {
+				add(tok.Line, RuleImageAlt, "<img> has no alt attribute")
+			}
+			if alt, _ := tok.Attr("alt"); link != nil && strings.TrimSpace(alt) != "" {
+				link.hasName = true
+			}
+		case "a":
+			if _, found := tok.Attr("href"); !found || isHidden(tok) {
+				break
+			}
+			link = &a11yLink{line: tok.Line, hasName: hasLabel(tok)}
+		case "audio", "video":
+			media = &a11yMedia{tok: tok}
+			if tok.SelfClosing {
+				mediaIssues = append(mediaIssues, mediaIssue(tok))
+				describedIDs = append(describedIDs, mediaDescribedBy(tok))
+				media = nil
+			}
+		case "track":
+			if kind, _ := tok.Attr("kind"); media != nil && (kind == "captions" || kind == "subtitles" || kind == "descriptions") {
+				media.hasCaptions = true
+			}
+		}
+	}
+
+	if !hasHTML {
+		add(0, RuleHTMLLang, "document has no <html> element with a lang attribute")
+	}
+
+	// A media element may refer to its transcript with aria-describedby,
+	// which can come later in the document.
+	for i, issue := range mediaIssues {
+		if describedIDs[i] != "" && ids[describedIDs[i]] {
+			continue
+		}
+		issues = append(issues, issue)
+	}
+
+	sort.Stable(a11yIssuesByLine(issues))
+
+	return issues
+}
+
+func mediaIssue(tok Token) A11yIssue {
+	return A11yIssue{Line: tok.Line, Rule: RuleMediaCaptions, Message: fmt.Sprintf("<%s> has no captions track or transcript", tok.Name)}
+}
+
+// mediaDescribedBy returns the first ID referenced by the aria-describedby
+// or aria-details attribute of tok, if any.
+func mediaDescribedBy(tok Token) string {
+	for _, attr := range []string{"aria-describedby", "aria-details"} {
+		v, _ := tok.Attr(attr)
+		if fields := strings.Fields(v); len(fields) > 0 {
+			return fields[0]
+		}
+	}
+	return ""
+}
+
+func isRefresh(tok Token) bool {
+	if tok.Name != "meta" {
+		return false
+	}
+	v, _ := tok.Attr("http-equiv")
+	return strings.EqualFold(v, "refresh")
+}
+
+// isHidden returns whether tok is hidden from assistive technology.
+func isHidden(tok Token) bool {
+	if v, _ := tok.Attr("aria-hidden"); v == "true" {
+		return true
+	}
+	role, _ := tok.Attr("role")
+	return role == "presentation" || role == "none"
+}
+
+// hasLabel returns whether tok has an accessible name set by an attribute.
+func hasLabel(tok Token) bool {
+	for _, attr := range []string{"aria-label", "aria-labelledby", "title"} {
+		if v, _ := tok.Attr(attr); strings.TrimSpace(v) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// inlineContrast returns the contrast ratio between the color and the
+// background color set in the given inline style, if both are set.
+func inlineContrast(style string) (float64, bool) {
+	var (
+		fg, bg       rgb
+		hasFg, hasBg bool
+	)
+
+	for _, decl := range strings.Split(style, ";") {
+		parts := strings.SplitN(decl, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		prop := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(parts[1]), "!important"))
+
+		switch prop {
+		case "color":
+			fg, hasFg = parseColor(value)
+		case "background-color", "background":
+			bg, hasBg = parseColor(value)
+		}
+	}
+
+	if !hasFg || !hasBg {
+		return 0, false
+	}
+
+	l1, l2 := fg.luminance(), bg.luminance()
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+
+	return (l1 + 0.05) / (l2 + 0.05), true
+}
+
+type rgb struct {
+	r, g, b float64
+}
+
+// luminance returns the relative luminance as defined by WCAG 2.0.
+func (c rgb) luminance() float64 {
+	channel := func(v float64) float64 {
+		v /= 255
+		if v <= 0.03928 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(c.r) + 0.7152*channel(c.g) + 0.0722*channel(c.b)
+}
+
+// namedColors are the basic CSS color keywords.
+var namedColors = map[string]rgb{
+	"black":   {0, 0, 0},
+	"silver":  {192, 192, 192},
+	"gray":    {128, 128, 128},
+	"grey":    {128, 128, 128},
+	"white":   {255, 255, 255},
+	"maroon":  {128, 0, 0},
+	"red":     {255, 0, 0},
+	"purple":  {128, 0, 128},
+	"fuchsia": {255, 0, 255},
+	"green":   {0, 128, 0},
+	"lime":    {0, 255, 0},
+	"olive":   {128, 128, 0},
+	"yellow":  {255, 255, 0},
+	"navy":    {0, 0, 128},
+	"blue":    {0, 0, 255},
+	"teal":    {0, 128, 128},
+	"aqua":    {0, 255, 255},
+	"orange":  {255, 165, 0},
+}
+
+// parseColor parses an opaque CSS color: a basic color keyword, #rgb,
+// #rrggbb or rgb(r, g, b).
+func parseColor(s string) (rgb, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	if c, found := namedColors[s]; found {
+		return c, true
+	}
+
+	if strings.HasPrefix(s, "#") {
+		hex := s[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			return rgb{}, false
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return rgb{}, false
+		}
+		return rgb{float64(v >> 16 & 0xff), float64(v >> 8 & 0xff), float64(v & 0xff)}, true
+	}
+
+	if strings.HasPrefix(s, "rgb(") && strings.HasSuffix(s, ")") {
+		parts := strings.Split(s[4:len(s)-1], ",")
+		if len(parts) != 3 {
+			return rgb{}, false
+		}
+		var c [3]float64
+		for i, p := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+			if err != nil || v < 0 || v > 255 {
+				return rgb{}, false
+			}
+			c[i] = v
+		}
+		return rgb{c[0], c[1], c[2]}, true
+	}
+
+	return rgb{}, false
+}
+
+type a11yIssuesByLine []A11yIssue
+
+func (a a11yIssuesByLine) Len() int           { return len(a) }
+func (a a11yIssuesByLine) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a a11yIssuesByLine) Less(i, j int) bool { return a[i].Line < a[j].Line }
@@ -0,0 +1,106 @@
+package checks
+
+import (
+	"testing"
+
+	"github.com/govenue/fsintra"
+	"github.com/govenue/require"
+)
+
+func TestCheckA11yDocument(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	issues := CheckA11yDocument(ParseHTML([]byte(`<!DOCTYPE html>
+<html>
+<body>
+<h1 id="title">Episode 1</h1>
+<h3>Show notes</h3>
+<img src="/a.png">
+<img src="/b.png" alt="">
+<img src="/c.png" aria-hidden="true">
+<a href="/"><img src="/home.png" alt=""></a>
+<a href="/"><img src="/home.png" alt="Home"></a>
+<a href="/about/"> </a>
+<a href="/about/" aria-label="About"></a>
+<a name="anchor"></a>
+<p id="title">Duplicate</p>
+<audio src="/ep1.mp3"></audio>
+<audio src="/ep1.mp3" aria-describedby="transcript"></audio>
+<video src="/ep1.mp4"><track kind="captions" src="/ep1.vtt"></video>
+<video src="/ep1.mp4"><track kind="chapters" src="/ep1.vtt"></video>
+<p style="color: #777; background-color: #fff">Low</p>
+<p style="color: black; background: white">High</p>
+<p style="color: #777">Unknown background</p>
+<audio src="/ep2.mp3" aria-describedby=" "></audio>
+<div id="transcript">Transcript</div>
+</body>
+</html>`)))
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+
+	assert.Equal([]string{
+		`2: html-lang: <html> has no lang attribute`,
+		`5: heading-order: heading level skipped from h1 to h3`,
+		`6: image-alt: <img> has no alt attribute`,
+		`9: link-name: link has no text`,
+		`11: link-name: link has no text`,
+		`14: duplicate-id: duplicate id "title"`,
+		`15: media-captions: <audio> has no captions track or transcript`,
+		`18: media-captions: <video> has no captions track or transcript`,
+		`19: color-contrast: <p> has a contrast ratio of 4.48:1, at least 4.5:1 is needed`,
+		`22: media-captions: <audio> has no captions track or transcript`,
+	}, got)
+}
+
+func TestCheckA11y(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	fs := fsintra.NewMemMapFs()
+
+	writeFile(t, fs, "/public/index.html", `<html lang="en"><h1>Home</h1></html>`)
+	writeFile(t, fs, "/public/posts/index.html", `<html lang="en"><h1>Posts</h1><h4>Oops</h4></html>`)
+	writeFile(t, fs, "/public/posts/p1/index.html", `<p>No html element</p>`)
+	writeFile(t, fs, "/public/old/index.html", `<!DOCTYPE html><html><head><meta http-equiv="refresh" content="0; url=/posts/"></head></html>`)
+
+	reports, err := CheckA11y(fs, "/public")
+	assert.NoError(err)
+	assert.Len(reports, 2)
+
+	assert.Equal("posts/index.html", reports[0].Filename)
+	assert.Equal([]A11yIssue{{Line: 1, Rule: RuleHeadingOrder, Message: "heading level skipped from h1 to h4"}}, reports[0].Issues)
+
+	assert.Equal("posts/p1/index.html", reports[1].Filename)
+	assert.Equal([]A11yIssue{{Line: 0, Rule: RuleHTMLLang, Message: "document has no <html> element with a lang attribute"}}, reports[1].Issues)
+}
+
+func TestParseColor(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	for _, test := range []struct {
+		in     string
+		expect rgb
+		ok     bool
+	}{
+		{"#fff", rgb{255, 255, 255}, true},
+		{"#1A2b3C", rgb{26, 43, 60}, true},
+		{"rgb(1, 2, 3)", rgb{1, 2, 3}, true},
+		{" Navy ", rgb{0, 0, 128}, true},
+		{"#ffff", rgb{}, false},
+		{"rgb(1, 2)", rgb{}, false},
+		{"url(a.png)", rgb{}, false},
+		{"transparent", rgb{}, false},
+	} {
+		c, ok := parseColor(test.in)
+		assert.Equal(test.ok, ok, test.in)
+		assert.Equal(test.expect, c, test.in)
+	}
+}
@@ -0,0 +1,352 @@
+package checks
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/govenue/fsintra"
+)
+
+// The status of an output file compared to a previous build.
+const (
+	OutputAdded   = "added"
+	OutputRemoved = "removed"
+	OutputChanged = "changed"
+)
+
+// diffContext is the number of unchanged lines around the changes in a
+// unified diff.
+const diffContext = 3
+
+// maxDiffCells limits the memory used to diff the changed part of a file.
+// Above this the changed parts are shown as removed and added in full.
+const maxDiffCells = 4 << 20
+
+// volatileOutput matches content that changes on every build or with the
+// Gean version, and what it is replaced with before comparing.
+var volatileOutput = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`(?i)<meta\s+name=["']?generator["']?[^>]*>`), `<meta name="generator">`},
+	{regexp.MustCompile(`(?i)<generator>[^<]*</generator>`), `<generator></generator>`},
+	// RFC 3339, as used in sitemaps, Atom and JSON.
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`), `<timestamp>`},
+	// RFC 1123, as used in RSS.
+	{regexp.MustCompile(`(Mon|Tue|Wed|Thu|Fri|Sat|Sun), \d{2} (Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) \d{4} \d{2}:\d{2}:\d{2} ([+-]\d{4}|[A-Z]{3})`), `<timestamp>`},
+}
+
+// OutputDiff is an output file that differs from a previous build.
+type OutputDiff struct {
+	// The file, slash separated and relative to the publish dir.
+	Filename string
+
+	// One of OutputAdded, OutputRemoved or OutputChanged.
+	Status string
+
+	// The unified diff for changed text files, empty for binary files and
+	// for files added or removed.
+	Diff string
+}
+
+// ReadOutput reads every file below dir in fs, mapped from its slash
+// separated path relative to dir.
+func ReadOutput(fs fsintra.Fs, dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+
+	err := fsintra.Walk(fs, dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		b, err := fsintra.ReadFile(fs, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = b
+
+		return nil
+	})
+
+	return files, err
+}
+
+// WriteSnapshot replaces the content of dir in fs with files, e.g. as read
+// with ReadOutput.
+func WriteSnapshot(fs fsintra.Fs, dir string, files map[string][]byte) error {
+	if err := fs.RemoveAll(dir); err != nil {
+		return err
+	}
+
+	for name, b := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := fs.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+			return err
+		}
+		if err := fsintra.WriteFile(fs, filename, b, 0666); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DiffOutput compares the output of a build, current, to that of a previous
+// one, previous, after normalizing volatile content such as the generator
+// tag and timestamps. Both map slash separated filenames to their content.
+// The differences are ordered by filename.
+func DiffOutput(previous, current map[string][]byte) []*OutputDiff {
+	var diffs []*OutputDiff
+
+	for name, b := range current {
+		old, found := previous[name]
+		if !found {
+			diffs = append(diffs, &OutputDiff{Filename: name, Status: OutputAdded})
+			continue
+		}
+
+		if bytes.Equal(old, b) {
+			continue
+		}
+
+		if !isText(old) || !isText(b) {
+			diffs = append(diffs, &OutputDiff{Filename: name, Status: OutputChanged})
+			continue
+		}
+
+		old, b = NormalizeOutput(old), NormalizeOutput(b)
+		if bytes.Equal(old, b) {
+			continue
+		}
+
+		diffs = append(diffs, &OutputDiff{
+			Filename: name,
+			Status:   OutputChanged,
+			Diff:     UnifiedDiff("a/"+name, "b/"+name, string(old), string(b)),
+		})
+	}
+
+	for name := range previous {
+		if _, found := current[name]; !found {
+			diffs = append(diffs, &OutputDiff{Filename: name, Status: OutputRemoved})
+		}
+	}
+
+	sort.Sort(outputDiffsByFilename(diffs))
+
+	return diffs
+}
+
+// NormalizeOutput replaces the content in b that changes on every build or
+// with the Gean version with placeholders.
+func NormalizeOutput(b []byte) []byte {
+	for _, v := range volatileOutput {
+		b = v.re.ReplaceAll(b, []byte(v.repl))
+	}
+	return b
+}
+
+func isText(b []byte) bool {
+	return utf8.Valid(b) && bytes.IndexByte(b, 0) == -1
+}
+
+// diffOp is a line in a diff: kept (' '), removed ('-') or added ('+').
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// UnifiedDiff returns the unified diff of a and b, named oldName and
+// newName in the header, or an empty string if they are equal.
+func UnifiedDiff(oldName, newName, a, b string) string {
+	if a == b {
+		return ""
+	}
+
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", oldName, newName)
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// Extend the hunk while the next change is within the context of
+		// the previous one.
+		end := i
+		for j := i; j < len(ops) && j <= end+2*diffContext+1; j++ {
+			if ops[j].kind != ' ' {
+				end = j
+			}
+		}
+
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		stop := end + 1 + diffContext
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+
+		writeHunk(&buf, ops, start, stop)
+
+		i = stop
+	}
+
+	return buf.String()
+}
+
+func writeHunk(buf *bytes.Buffer, ops []diffOp, start, stop int) {
+	var oldLine, newLine, oldCount, newCount int
+
+	for i, op := range ops[:stop] {
+		inHunk := i >= start
+		if op.kind != '+' {
+			if inHunk {
+				oldCount++
+			} else {
+				oldLine++
+			}
+		}
+		if op.kind != '-' {
+			if inHunk {
+				newCount++
+			} else {
+				newLine++
+			}
+		}
+	}
+
+	// The start line is that of the first line in the hunk, or the line
+	// before if the hunk is empty on that side.
+	if oldCount > 0 {
+		oldLine++
+	}
+	if newCount > 0 {
+		newLine++
+	}
+
+	fmt.Fprintf(buf, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+
+	for _, op := range ops[start:stop] {
+		buf.WriteByte(op.kind)
+		buf.WriteString(op.line)
+		if !strings.HasSuffix(op.line, "\n") {
+			buf.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// splitLines splits s after every newline.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the shortest edit from a to b. The lines common to the
+// start and the end are skipped before looking for the longest common
+// subsequence of the rest, as changes are usually local.
+func diffLines(a, b []string) []diffOp {
+	var prefix, suffix int
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, diffLCS(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+
+	return ops
+}
+
+func diffLCS(a, b []string) []diffOp {
+	var ops []diffOp
+
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var i, j int
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+
+	return ops
+}
+
+type outputDiffsByFilename []*OutputDiff
+
+func (o outputDiffsByFilename) Len() int           { return len(o) }
+func (o outputDiffsByFilename) Swap(i, j int)      { o[i], o[j] = o[j], o[i] }
+func (o outputDiffsByFilename) Less(i, j int) bool { return o[i].Filename < o[j].Filename }
@@ -0,0 +1,135 @@
+package checks
+
+import (
+	"testing"
+
+	"github.com/govenue/fsintra"
+	"github.com/govenue/require"
+)
+
+func TestDiffOutput(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	previous := map[string][]byte{
+		"index.html":      []byte("<html>\n<meta name=\"generator\" content=\"Gean 0.30\">\n<p>Hello</p>\n</html>\n"),
+		"post/index.html": []byte("<p>One</p>\n<p>Two</p>\n"),
+		"sitemap.xml":     []byte("<lastmod>2017-01-02T10:00:00+01:00</lastmod>\n"),
+		"index.xml":       []byte("<lastBuildDate>Mon, 02 Jan 2017 10:00:00 +0100</lastBuildDate>\n"),
+		"old/index.html":  []byte("<p>Old</p>\n"),
+		"logo.png":        {0x89, 'P', 'N', 'G', 0, 1},
+	}
+
+	current := map[string][]byte{
+		"index.html":      []byte("<html>\n<meta name=\"generator\" content=\"Gean 0.31\">\n<p>Hello</p>\n</html>\n"),
+		"post/index.html": []byte("<p>One</p>\n<p>2</p>\n"),
+		"sitemap.xml":     []byte("<lastmod>2017-03-04T11:30:00Z</lastmod>\n"),
+		"index.xml":       []byte("<lastBuildDate>Sat, 04 Mar 2017 11:30:00 UTC</lastBuildDate>\n"),
+		"new/index.html":  []byte("<p>New</p>\n"),
+		"logo.png":        {0x89, 'P', 'N', 'G', 0, 2},
+	}
+
+	diffs := DiffOutput(previous, current)
+
+	var got []string
+	for _, d := range diffs {
+		got = append(got, d.Status+" "+d.Filename)
+	}
+
+	assert.Equal([]string{
+		"changed logo.png",
+		"added new/index.html",
+		"removed old/index.html",
+		"changed post/index.html",
+	}, got)
+
+	assert.Equal("", diffs[0].Diff)
+	assert.Equal(`--- a/post/index.html
++++ b/post/index.html
+@@ -1,2 +1,2 @@
+ <p>One</p>
+-<p>Two</p>
++<p>2</p>
+`, diffs[3].Diff)
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	assert.Equal("", UnifiedDiff("a", "b", "same\n", "same\n"))
+
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n18\n19\n20\n"
+	b := "1\n2\n3\nfour\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n19\n20\n21"
+
+	assert.Equal(`--- a
++++ b
+@@ -1,7 +1,7 @@
+ 1
+ 2
+ 3
+-4
++four
+ 5
+ 6
+ 7
+@@ -15,6 +15,6 @@
+ 15
+ 16
+ 17
+-18
+ 19
+ 20
++21
+\ No newline at end of file
+`, UnifiedDiff("a", "b", a, b))
+
+	// Changes with up to twice the context between them share a hunk.
+	assert.Equal(`--- a
++++ b
+@@ -1,9 +1,9 @@
+-1
++one
+ 2
+ 3
+ 4
+ 5
+ 6
+ 7
+-8
++eight
+ 9
+`, UnifiedDiff("a", "b", "1\n2\n3\n4\n5\n6\n7\n8\n9\n", "one\n2\n3\n4\n5\n6\n7\neight\n9\n"))
+
+	assert.Equal(`--- a
++++ b
+@@ -0,0 +1,1 @@
++new
+`, UnifiedDiff("a", "b", "", "new\n"))
+}
+
+func TestReadOutputWriteSnapshot(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	fs := fsintra.NewMemMapFs()
+	writeFile(t, fs, "public/index.html", "<p>Home</p>")
+	writeFile(t, fs, "public/post/index.html", "<p>Post</p>")
+	writeFile(t, fs, "snapshots/last/stale.html", "<p>Stale</p>")
+
+	files, err := ReadOutput(fs, "public")
+	assert.NoError(err)
+	assert.Equal(map[string][]byte{
+		"index.html":      []byte("<p>Home</p>"),
+		"post/index.html": []byte("<p>Post</p>"),
+	}, files)
+
+	assert.NoError(WriteSnapshot(fs, "snapshots/last", files))
+
+	snapshot, err := ReadOutput(fs, "snapshots/last")
+	assert.NoError(err)
+	assert.Equal(files, snapshot)
+}
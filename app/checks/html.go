@@ -0,0 +1,282 @@
+// Package checks implements the site verifications run by "gean check".
+package checks
+
+import (
+	"bytes"
+	"html"
+	"strings"
+)
+
+// TokenType is the type of an HTML token.
+type TokenType int
+
+const (
+	// StartTag is an opening tag, e.g. <a href="/">.
+	StartTag TokenType = iota
+
+	// EndTag is a closing tag, e.g. </a>.
+	EndTag
+
+	// Text is the text between tags, with entities unescaped.
+	Text
+)
+
+// Token is a token in an HTML document.
+type Token struct {
+	Type TokenType
+
+	// The lower case tag name for StartTag and EndTag.
+	Name string
+
+	// The attributes of a StartTag. The names are lower case, the values
+	// have entities unescaped.
+	Attrs map[string]string
+
+	// Set for self closing start tags, e.g. <br/>.
+	SelfClosing bool
+
+	// The content for Text tokens.
+	Data string
+
+	// The 1-based line the token starts on.
+	Line int
+}
+
+// Attr returns the value of the named attribute and whether it's set.
+func (t Token) Attr(name string) (string, bool) {
+	v, found := t.Attrs[name]
+	return v, found
+}
+
+// Document is a tokenized HTML document.
+type Document struct {
+	Tokens []Token
+
+	// The element IDs in the document, including the names of <a name="">
+	// anchors.
+	IDs map[string]bool
+}
+
+// rawTextElements are elements whose content isn't HTML.
+var rawTextElements = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"textarea": true,
+	"title":    true,
+}
+
+// ParseHTML tokenizes the given HTML document. It is a lenient scanner
+// meant for the checks in this package, not a conforming HTML parser:
+// comments, doctypes and processing instructions are skipped and malformed
+// markup is read as text.
+func ParseHTML(b []byte) *Document {
+	d := &Document{IDs: make(map[string]bool)}
+
+	var (
+		pos  int
+		line = 1
+	)
+
+	advance := func(to int) {
+		line += bytes.Count(b[pos:to], []byte("\n"))
+		pos = to
+	}
+
+	addText := func(to int) {
+		if to > pos {
+			d.Tokens = append(d.Tokens, Token{Type: Text, Data: html.UnescapeString(string(b[pos:to])), Line: line})
+			advance(to)
+		}
+	}
+
+	for pos < len(b) {
+		lt := bytes.IndexByte(b[pos:], '<')
+		if lt == -1 {
+			addText(len(b))
+			break
+		}
+		addText(pos + lt)
+
+		rest := b[pos:]
+
+		switch {
+		case bytes.HasPrefix(rest, []byte("<!--")):
+			end := bytes.Index(rest[4:], []byte("-->"))
+			if end == -1 {
+				advance(len(b))
+			} else {
+				advance(pos + 4 + end + 3)
+			}
+			continue
+		case bytes.HasPrefix(rest, []byte("<!")), bytes.HasPrefix(rest, []byte("<?")):
+			end := bytes.IndexByte(rest, '>')
+			if end == -1 {
+				advance(len(b))
+			} else {
+				advance(pos + end + 1)
+			}
+			continue
+		}
+
+		tok, n := scanTag(rest)
+		if n == 0 {
+			// Not a tag, e.g. "a < b".
+			d.Tokens = append(d.Tokens, Token{Type: Text, Data: "<", Line: line})
+			advance(pos + 1)
+			continue
+		}
+
+		tok.Line = line
+		d.Tokens = append(d.Tokens, tok)
+		advance(pos + n)
+
+		if tok.Type != StartTag {
+			continue
+		}
+
+		if id, found := tok.Attrs["id"]; found && id != "" {
+			d.IDs[id] = true
+		}
+		if name, found := tok.Attrs["name"]; found && name != "" && tok.Name == "a" {
+			d.IDs[name] = true
+		}
+
+		if rawTextElements[tok.Name] && !tok.SelfClosing {
+			end := indexEndTag(b[pos:], tok.Name)
+			if end == -1 {
+				end = len(b) - pos
+			}
+			raw := string(b[pos : pos+end])
+			if tok.Name == "title" || tok.Name == "textarea" {
+				raw = html.UnescapeString(raw)
+			}
+			if raw != "" {
+				d.Tokens = append(d.Tokens, Token{Type: Text, Data: raw, Line: line})
+			}
+			advance(pos + end)
+		}
+	}
+
+	return d
+}
+
+// scanTag scans the start or end tag at the beginning of b, returning the
+// token and the number of bytes read. It returns 0 if b doesn't start with
+// a tag.
+func scanTag(b []byte) (Token, int) {
+	var tok Token
+
+	i := 1
+	if i < len(b) && b[i] == '/' {
+		tok.Type = EndTag
+		i++
+	}
+
+	start := i
+	for i < len(b) && isNameChar(b[i]) {
+		i++
+	}
+	if i == start || !isLetter(b[start]) {
+		return tok, 0
+	}
+	tok.Name = strings.ToLower(string(b[start:i]))
+
+	if tok.Type == StartTag {
+		tok.Attrs = make(map[string]string)
+	}
+
+	for i < len(b) {
+		i = skipSpace(b, i)
+		if i >= len(b) {
+			break
+		}
+
+		switch b[i] {
+		case '>':
+			return tok, i + 1
+		case '/':
+			if i+1 < len(b) && b[i+1] == '>' {
+				tok.SelfClosing = true
+				return tok, i + 2
+			}
+			i++
+			continue
+		}
+
+		// Attribute name.
+		start := i
+		for i < len(b) && !isSpace(b[i]) && b[i] != '=' && b[i] != '>' && !(b[i] == '/' && i+1 < len(b) && b[i+1] == '>') {
+			i++
+		}
+		name := strings.ToLower(string(b[start:i]))
+		if name == "" {
+			i++
+			continue
+		}
+
+		var value string
+		i = skipSpace(b, i)
+		if i < len(b) && b[i] == '=' {
+			i = skipSpace(b, i+1)
+			if i < len(b) && (b[i] == '"' || b[i] == '\'') {
+				q := b[i]
+				end := bytes.IndexByte(b[i+1:], q)
+				if end == -1 {
+					return tok, 0
+				}
+				value = string(b[i+1 : i+1+end])
+				i += end + 2
+			} else {
+				start := i
+				for i < len(b) && !isSpace(b[i]) && b[i] != '>' {
+					i++
+				}
+				value = string(b[start:i])
+			}
+		}
+
+		if tok.Attrs != nil {
+			if _, found := tok.Attrs[name]; !found {
+				tok.Attrs[name] = html.UnescapeString(value)
+			}
+		}
+	}
+
+	// Unterminated tag.
+	return tok, 0
+}
+
+// indexEndTag returns the index of the end tag with the given name in b,
+// matched case insensitively, or -1 if not found.
+func indexEndTag(b []byte, name string) int {
+	for i := 0; ; {
+		j := bytes.Index(b[i:], []byte("</"))
+		if j == -1 {
+			return -1
+		}
+		i += j
+		if end := i + 2 + len(name); end <= len(b) && strings.EqualFold(string(b[i+2:end]), name) {
+			return i
+		}
+		i += 2
+	}
+}
+
+func skipSpace(b []byte, i int) int {
+	for i < len(b) && isSpace(b[i]) {
+		i++
+	}
+	return i
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isNameChar(c byte) bool {
+	return isLetter(c) || '0' <= c && c <= '9' || c == '-' || c == ':'
+}
@@ -0,0 +1,69 @@
+package checks
+
+import (
+	"testing"
+
+	"github.com/govenue/require"
+)
+
+func TestParseHTML(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	doc := ParseHTML([]byte(`<!DOCTYPE html>
+<html lang="en">
+<!-- <a href="/commented/"> -->
+<head><title>A &amp; B</title>
+<script>if (a < b && "</div>") {}</script>
+</head>
+<body>
+<h1 id="top" class=title>Hello &amp; welcome</h1>
+<a NAME="anchor"></a>
+<img src='/img/a.png' alt="">
+<br/>
+<a href="/posts/?a=1&amp;b=2"
+   data-x>Posts</a>
+</body>
+</html>`))
+
+	var starts []Token
+	for _, tok := range doc.Tokens {
+		if tok.Type == StartTag {
+			starts = append(starts, tok)
+		}
+	}
+
+	var names []string
+	for _, tok := range starts {
+		names = append(names, tok.Name)
+	}
+	assert.Equal([]string{"html", "head", "title", "script", "body", "h1", "a", "img", "br", "a"}, names)
+
+	assert.Equal("en", starts[0].Attrs["lang"])
+	assert.Equal("title", starts[5].Attrs["class"])
+	assert.Equal(8, starts[5].Line)
+	assert.Equal("/img/a.png", starts[7].Attrs["src"])
+	alt, found := starts[7].Attr("alt")
+	assert.True(found)
+	assert.Equal("", alt)
+	assert.True(starts[8].SelfClosing)
+	assert.Equal("/posts/?a=1&b=2", starts[9].Attrs["href"])
+	assert.Equal(12, starts[9].Line)
+	_, found = starts[9].Attr("data-x")
+	assert.True(found)
+
+	assert.True(doc.IDs["top"])
+	assert.True(doc.IDs["anchor"])
+	assert.False(doc.IDs["title"])
+
+	var texts []string
+	for _, tok := range doc.Tokens {
+		if tok.Type == Text && tok.Data != "\n" {
+			texts = append(texts, tok.Data)
+		}
+	}
+	assert.Contains(texts, "A & B")
+	assert.Contains(texts, "Hello & welcome")
+	assert.Contains(texts, `if (a < b && "</div>") {}`)
+}
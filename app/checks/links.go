@@ -0,0 +1,391 @@
+package checks
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/govenue/fsintra"
+)
+
+// linkAttrs are the attributes holding links, per element.
+var linkAttrs = map[string]string{
+	"a":      "href",
+	"area":   "href",
+	"link":   "href",
+	"img":    "src",
+	"script": "src",
+	"iframe": "src",
+	"embed":  "src",
+	"source": "src",
+	"track":  "src",
+	"audio":  "src",
+	"video":  "src",
+}
+
+// LinkError is a broken link found by LinkChecker.
+type LinkError struct {
+	// The HTML file, relative to the publish dir, and the 1-based line of the
+	// element with the link.
+	Filename string
+	Line     int
+
+	// The link as written.
+	Link string
+
+	// Why the link is broken.
+	Reason string
+}
+
+func (e *LinkError) Error() string {
+	return fmt.Sprintf("%s:%d: %q: %s", e.Filename, e.Line, e.Link, e.Reason)
+}
+
+// LinkChecker validates the links in the HTML files of a published site.
+type LinkChecker struct {
+	fs         fsintra.Fs
+	publishDir string
+	baseURL    *url.URL
+
+	// Links matching any of these are not checked.
+	Ignore []*regexp.Regexp
+
+	// Whether to check links to other sites.
+	External bool
+
+	// If set, external links are requested from this base URL instead of
+	// their own host, with the host as the first path element, i.e.
+	// https://example.org/a is requested as <ExternalBase>/example.org/a.
+	// This allows a local stand-in to be used, e.g. in CI.
+	ExternalBase string
+
+	// The client used for external links.
+	Client *http.Client
+
+	mu   sync.Mutex
+	docs map[string]*Document
+}
+
+// NewLinkChecker creates a new LinkChecker for the site published to
+// publishDir in fs. Absolute links to baseURL are treated as internal.
+func NewLinkChecker(fs fsintra.Fs, publishDir, baseURL string) (*LinkChecker, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid baseURL %q: %s", baseURL, err)
+	}
+
+	return &LinkChecker{
+		fs:         fs,
+		publishDir: publishDir,
+		baseURL:    u,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+		docs:       make(map[string]*Document),
+	}, nil
+}
+
+type externalLink struct {
+	filename string
+	line     int
+	link     string
+}
+
+// Check checks every HTML file in the publish dir and returns the broken
+// links, ordered by filename and line.
+func (c *LinkChecker) Check() ([]*LinkError, error) {
+	var (
+		errs     []*LinkError
+		external = make(map[string][]externalLink)
+	)
+
+	walker := func(filename string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !isHTML(filename) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(c.publishDir, filename)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		doc, err := c.document(rel)
+		if err != nil {
+			return err
+		}
+
+		for _, tok := range doc.Tokens {
+			if tok.Type != StartTag {
+				continue
+			}
+			attr, found := linkAttrs[tok.Name]
+			if !found {
+				continue
+			}
+			link, found := tok.Attr(attr)
+			if !found || c.ignored(link) {
+				continue
+			}
+			if tok.Name == "link" {
+				if r, _ := tok.Attr("rel"); r == "preconnect" || r == "dns-prefetch" {
+					continue
+				}
+			}
+
+			isExternal, reason := c.checkLink(rel, link)
+			if isExternal {
+				if c.External {
+					external[link] = append(external[link], externalLink{rel, tok.Line, link})
+				}
+				continue
+			}
+			if reason != "" {
+				errs = append(errs, &LinkError{Filename: rel, Line: tok.Line, Link: link, Reason: reason})
+			}
+		}
+
+		return nil
+	}
+
+	if err := fsintra.Walk(c.fs, c.publishDir, walker); err != nil {
+		return nil, err
+	}
+
+	errs = append(errs, c.checkExternal(external)...)
+
+	sort.Sort(linkErrorsByPosition(errs))
+
+	return errs, nil
+}
+
+func (c *LinkChecker) ignored(link string) bool {
+	for _, re := range c.Ignore {
+		if re.MatchString(link) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLink checks a link in the HTML file rel. It returns whether the link
+// is external, and why the link is broken if it isn't.
+func (c *LinkChecker) checkLink(rel, link string) (bool, string) {
+	link = strings.TrimSpace(link)
+	if link == "" || link == "#" {
+		return false, ""
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return false, "invalid URL"
+	}
+
+	switch u.Scheme {
+	case "", "http", "https":
+	default:
+		// mailto:, tel:, data: etc.
+		return false, ""
+	}
+
+	var target string
+
+	if u.Host != "" || strings.HasPrefix(u.Path, "/") {
+		if u.Host != "" && u.Host != c.baseURL.Host {
+			return true, ""
+		}
+		p := u.Path
+		if base := strings.TrimSuffix(c.baseURL.Path, "/"); base != "" {
+			if p != base && !strings.HasPrefix(p, base+"/") {
+				return false, "outside of baseURL"
+			}
+			p = strings.TrimPrefix(p, base)
+		}
+		target = p
+	} else if u.Path == "" {
+		// Fragment only.
+		target = rel
+	} else {
+		target = path.Join(path.Dir(rel), u.Path)
+		if strings.HasSuffix(u.Path, "/") {
+			target += "/"
+		}
+		if strings.HasPrefix(target, "../") || target == ".." {
+			return false, "outside of publish dir"
+		}
+	}
+
+	filename, found := c.resolve(target)
+	if !found {
+		return false, "not found"
+	}
+
+	if u.Fragment != "" && isHTML(filename) {
+		doc, err := c.document(filename)
+		if err != nil {
+			return false, err.Error()
+		}
+		if !doc.IDs[u.Fragment] {
+			return false, fmt.Sprintf("no element with id %q in %s", u.Fragment, filename)
+		}
+	}
+
+	return false, ""
+}
+
+// resolve finds the published file for the slash separated target path
+// relative to the publish dir.
+func (c *LinkChecker) resolve(target string) (string, bool) {
+	target = strings.TrimPrefix(target, "/")
+	if target == "." {
+		target = ""
+	}
+
+	candidates := []string{target}
+	if target == "" || strings.HasSuffix(target, "/") {
+		candidates = []string{target + "index.html"}
+	} else if path.Ext(target) == "" {
+		candidates = append(candidates, target+"/index.html")
+	}
+
+	for _, candidate := range candidates {
+		fi, err := c.fs.Stat(filepath.Join(c.publishDir, filepath.FromSlash(candidate)))
+		if err == nil && !fi.IsDir() {
+			return candidate, true
+		}
+	}
+
+	return "", false
+}
+
+// document returns the parsed HTML file rel, relative to the publish dir.
+func (c *LinkChecker) document(rel string) (*Document, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if doc, found := c.docs[rel]; found {
+		return doc, nil
+	}
+
+	b, err := fsintra.ReadFile(c.fs, filepath.Join(c.publishDir, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil, err
+	}
+
+	doc := ParseHTML(b)
+	c.docs[rel] = doc
+
+	return doc, nil
+}
+
+func (c *LinkChecker) checkExternal(links map[string][]externalLink) []*LinkError {
+	var (
+		errs []*LinkError
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		jobs = make(chan string)
+	)
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for link := range jobs {
+				reason := c.fetch(link)
+				if reason == "" {
+					continue
+				}
+				mu.Lock()
+				for _, l := range links[link] {
+					errs = append(errs, &LinkError{Filename: l.filename, Line: l.line, Link: l.link, Reason: reason})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for link := range links {
+		jobs <- link
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	return errs
+}
+
+// fetch requests the external link and returns why it failed, or an empty
+// string if it didn't.
+func (c *LinkChecker) fetch(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "invalid URL"
+	}
+	if u.Scheme == "" {
+		u.Scheme = "https"
+	}
+	u.Fragment = ""
+
+	if c.ExternalBase != "" {
+		base, err := url.Parse(c.ExternalBase)
+		if err != nil {
+			return fmt.Sprintf("invalid external base %q", c.ExternalBase)
+		}
+		u.Path = strings.TrimSuffix(base.Path, "/") + "/" + u.Host + u.Path
+		u.RawPath = ""
+		u.Scheme = base.Scheme
+		u.Host = base.Host
+	}
+
+	for _, method := range []string{"HEAD", "GET"} {
+		req, err := http.NewRequest(method, u.String(), nil)
+		if err != nil {
+			return err.Error()
+		}
+		resp, err := c.Client.Do(req)
+		if err != nil {
+			return err.Error()
+		}
+		resp.Body.Close()
+
+		if method == "HEAD" && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+			// Try again with GET.
+			continue
+		}
+
+		if resp.StatusCode >= 400 {
+			return resp.Status
+		}
+		break
+	}
+
+	return ""
+}
+
+func isHTML(filename string) bool {
+	ext := strings.ToLower(path.Ext(filename))
+	return ext == ".html" || ext == ".htm"
+}
+
+type linkErrorsByPosition []*LinkError
+
+func (l linkErrorsByPosition) Len() int      { return len(l) }
+func (l linkErrorsByPosition) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+func (l linkErrorsByPosition) Less(i, j int) bool {
+	if l[i].Filename != l[j].Filename {
+		return l[i].Filename < l[j].Filename
+	}
+	if l[i].Line != l[j].Line {
+		return l[i].Line < l[j].Line
+	}
+	return l[i].Link < l[j].Link
+}
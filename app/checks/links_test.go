@@ -0,0 +1,94 @@
+package checks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/govenue/fsintra"
+	"github.com/govenue/require"
+)
+
+func writeFile(t *testing.T, fs fsintra.Fs, filename, content string) {
+	require.NoError(t, fsintra.WriteFile(fs, filepath.FromSlash(filename), []byte(content), 0755))
+}
+
+func TestLinkChecker(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	var (
+		mu        sync.Mutex
+		requested []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		if r.URL.Path == "/example.org/missing" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	fs := fsintra.NewMemMapFs()
+
+	writeFile(t, fs, "/public/index.html", `<html>
+<a href="/posts/">Posts</a>
+<a href="posts/p1/">P1</a>
+<a href="http://example.com/blog/posts/p1/#intro">P1 intro</a>
+<a href="/posts/p1/#nope">Bad fragment</a>
+<a href="/posts/p2/">Missing</a>
+<a href="#main">Main</a>
+<a href="#missing">Missing on same page</a>
+<a href="mailto:a@example.com">Mail</a>
+<img src="/img/a.png">
+<img src="/img/b.png">
+<a href="/ignored/page/">Ignored</a>
+<a href="https://example.org/ok">OK</a>
+<a href="https://example.org/missing">Missing external</a>
+<div id="main"></div>
+</html>`)
+	writeFile(t, fs, "/public/posts/index.html", `<a href="../">Home</a><a href="p1">P1</a><a href="../../up/">Up</a>`)
+	writeFile(t, fs, "/public/posts/p1/index.html", `<h2 id="intro">Intro</h2>`)
+	writeFile(t, fs, "/public/img/a.png", "png")
+
+	c, err := NewLinkChecker(fs, "/public", "http://example.com/blog/")
+	assert.NoError(err)
+
+	// Root relative links must include the path of the baseURL.
+	errs, err := c.Check()
+	assert.NoError(err)
+	assert.Len(errs, 8)
+	assert.Equal("outside of baseURL", errs[0].Reason)
+
+	c, err = NewLinkChecker(fs, "/public", "http://example.com/")
+	assert.NoError(err)
+	c.Ignore = []*regexp.Regexp{regexp.MustCompile("^/ignored/")}
+	c.External = true
+	c.ExternalBase = srv.URL
+
+	errs, err = c.Check()
+	assert.NoError(err)
+
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Error())
+	}
+
+	assert.Equal([]string{
+		`index.html:4: "http://example.com/blog/posts/p1/#intro": not found`,
+		`index.html:5: "/posts/p1/#nope": no element with id "nope" in posts/p1/index.html`,
+		`index.html:6: "/posts/p2/": not found`,
+		`index.html:8: "#missing": no element with id "missing" in index.html`,
+		`index.html:11: "/img/b.png": not found`,
+		`index.html:14: "https://example.org/missing": 404 Not Found`,
+		`posts/index.html:1: "../../up/": outside of publish dir`,
+	}, got)
+
+	assert.Contains(requested, "HEAD /example.org/ok")
+}
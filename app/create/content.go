@@ -57,6 +57,15 @@ func NewContent(
 
 	notepad.FEEDBACK.Println(contentPath, "created")
 
+	// Report where the archetype doesn't match the schema for the type, if any.
+	fmErrs, err := s.ValidateFrontMatter(targetPath, content)
+	if err != nil {
+		notepad.WARN.Printf("Failed to validate the front matter of %q: %s", targetPath, err)
+	}
+	for _, e := range fmErrs {
+		notepad.WARN.Println(e)
+	}
+
 	editor := s.Cfg.GetString("newContentEditor")
 	if editor != "" {
 		notepad.FEEDBACK.Printf("Editing %s with %q ...\n", targetPath, editor)
@@ -0,0 +1,266 @@
+package deploy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/govenue/mapstructure"
+)
+
+const defaultMaxDeletes = 256
+
+// Config is the top level configuration used by "gean deploy".
+//
+// An example site config.toml:
+//
+//	[deploy]
+//	maxDeletes = 100
+//
+//	[[deploy.targets]]
+//	name = "staging"
+//	url = "file:///mnt/www/staging"
+//
+//	[[deploy.targets]]
+//	name = "production"
+//	url = "s3://my-bucket/site?region=eu-west-1&endpoint=http://localhost:9000"
+//	deleteStale = true
+//	exclude = "**/*.map"
+//	invalidateURL = "http://localhost:8081/purge"
+//
+//	[[deploy.matchers]]
+//	pattern = "**/*.{css,js}"
+//	cacheControl = "max-age=31536000, immutable"
+//	contentEncoding = "gzip"
+//
+//	[[deploy.matchers]]
+//	pattern = "**/*.html"
+//	cacheControl = "max-age=300"
+type Config struct {
+	Targets  []*Target
+	Matchers []*Matcher
+
+	// Ask for confirmation if a deploy would delete more files than this.
+	MaxDeletes int
+}
+
+// Target configures a deployment target.
+type Target struct {
+	// Used to select the target with --target.
+	Name string
+
+	// The target location. Supported schemes are file:// for a local or
+	// mounted directory, and s3:// for an S3-compatible endpoint.
+	// For S3 the bucket is the host, the path an optional key prefix and
+	// the query may set region and endpoint.
+	URL string
+
+	// Delete remote files that are not in the local publish dir.
+	DeleteStale bool
+
+	// Files matching these glob patterns are neither uploaded nor deleted.
+	Include string
+	Exclude string
+
+	// Run after a deploy that changed files.
+	// The command gets the changed paths, one per line, on stdin.
+	InvalidateCommand string
+
+	// Receives a POST with the changed paths as JSON after a deploy
+	// that changed files.
+	InvalidateURL string
+
+	includeRe *regexp.Regexp
+	excludeRe *regexp.Regexp
+}
+
+// Matcher applies upload settings to files matching Pattern.
+// The first matching matcher is used.
+type Matcher struct {
+	// Glob pattern matched against the slash separated path relative to the
+	// publish dir. "*" matches within a path segment, "**" across segments
+	// and "{a,b}" either alternative.
+	Pattern string
+
+	// Cache-Control header to set on upload.
+	CacheControl string
+
+	// Content-Encoding to set on upload. Only "gzip" is supported; the file
+	// is compressed before upload.
+	ContentEncoding string
+
+	// Content-Type to set on upload. Defaults to the type derived from the
+	// file extension.
+	ContentType string
+
+	// Always upload matching files, even if they are unchanged.
+	Force bool
+
+	re *regexp.Regexp
+}
+
+// Matches returns whether the given slash separated path matches.
+func (m *Matcher) Matches(path string) bool {
+	return m.re.MatchString(path)
+}
+
+func (t *Target) included(path string) bool {
+	if t.includeRe != nil && !t.includeRe.MatchString(path) {
+		return false
+	}
+	if t.excludeRe != nil && t.excludeRe.MatchString(path) {
+		return false
+	}
+	return true
+}
+
+// DecodeConfig decodes the deploy section of the site config.
+func DecodeConfig(in interface{}) (Config, error) {
+	c := Config{MaxDeletes: defaultMaxDeletes}
+
+	if in == nil {
+		return c, errors.New("no deploy config provided")
+	}
+
+	m, ok := in.(map[string]interface{})
+	if !ok {
+		return c, fmt.Errorf("expected map[string]interface {} got %T", in)
+	}
+
+	if err := mapstructure.WeakDecode(m, &c); err != nil {
+		return c, err
+	}
+
+	names := make(map[string]bool)
+
+	for _, t := range c.Targets {
+		if t.Name == "" {
+			return c, errors.New("deploy target must have a name")
+		}
+		if names[t.Name] {
+			return c, fmt.Errorf("deploy target %q is defined more than once", t.Name)
+		}
+		names[t.Name] = true
+
+		if t.URL == "" {
+			return c, fmt.Errorf("deploy target %q must have a URL", t.Name)
+		}
+
+		var err error
+		if t.Include != "" {
+			if t.includeRe, err = globToRegexp(t.Include); err != nil {
+				return c, fmt.Errorf("deploy target %q: invalid include pattern: %s", t.Name, err)
+			}
+		}
+		if t.Exclude != "" {
+			if t.excludeRe, err = globToRegexp(t.Exclude); err != nil {
+				return c, fmt.Errorf("deploy target %q: invalid exclude pattern: %s", t.Name, err)
+			}
+		}
+	}
+
+	for _, m := range c.Matchers {
+		if m.Pattern == "" {
+			return c, errors.New("deploy matcher must have a pattern")
+		}
+		if m.ContentEncoding != "" && m.ContentEncoding != "gzip" {
+			return c, fmt.Errorf("deploy matcher %q: unsupported content encoding %q", m.Pattern, m.ContentEncoding)
+		}
+		var err error
+		if m.re, err = globToRegexp(m.Pattern); err != nil {
+			return c, fmt.Errorf("deploy matcher %q: %s", m.Pattern, err)
+		}
+	}
+
+	return c, nil
+}
+
+// Target returns the target with the given name, or the first target if
+// name is empty.
+func (c Config) Target(name string) (*Target, error) {
+	if len(c.Targets) == 0 {
+		return nil, errors.New("no deploy targets configured")
+	}
+
+	if name == "" {
+		return c.Targets[0], nil
+	}
+
+	for _, t := range c.Targets {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("deploy target %q not found", name)
+}
+
+// matcher returns the first matcher matching path, nil if none.
+func (c Config) matcher(path string) *Matcher {
+	for _, m := range c.Matchers {
+		if m.Matches(path) {
+			return m
+		}
+	}
+	return nil
+}
+
+// globToRegexp converts a glob pattern to an anchored regular expression.
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var (
+		b       bytes.Buffer
+		inGroup bool
+	)
+
+	b.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// "**/" matches zero or more directories.
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '{':
+			if inGroup {
+				return nil, fmt.Errorf("nested braces in %q", pattern)
+			}
+			inGroup = true
+			b.WriteString("(?:")
+		case '}':
+			if !inGroup {
+				return nil, fmt.Errorf("unbalanced braces in %q", pattern)
+			}
+			inGroup = false
+			b.WriteString(")")
+		case ',':
+			if inGroup {
+				b.WriteString("|")
+			} else {
+				b.WriteString(",")
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	if inGroup {
+		return nil, fmt.Errorf("unbalanced braces in %q", pattern)
+	}
+
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
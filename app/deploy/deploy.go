@@ -0,0 +1,521 @@
+// Package deploy syncs a published site to a deployment target.
+package deploy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/geego/gean/app/geanfs"
+	"github.com/govenue/fsintra"
+)
+
+// RemoteFile is a file as listed by a deploy store.
+type RemoteFile struct {
+	// Slash separated path relative to the target root.
+	Path string
+	MD5  []byte
+	Size int64
+}
+
+// LocalFile is a file in the publish dir, prepared for upload.
+// The content is read from the file when opened, so only the files being
+// uploaded are held in memory, and only in part.
+type LocalFile struct {
+	// Slash separated path relative to the publish dir.
+	Path string
+
+	// The size and MD5 of the content to upload, encoded as set in
+	// ContentEncoding.
+	Size int64
+	MD5  []byte
+
+	CacheControl    string
+	ContentEncoding string
+	ContentType     string
+
+	force bool
+
+	fs       fsintra.Fs
+	filename string
+}
+
+// Open opens the content to upload, encoded as set in ContentEncoding.
+func (lf *LocalFile) Open() (io.ReadCloser, error) {
+	f, err := lf.fs.Open(lf.filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if lf.ContentEncoding != "gzip" {
+		return f, nil
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		err := gzipTo(pw, f)
+		f.Close()
+		pw.CloseWithError(err)
+	}()
+
+	return pr, nil
+}
+
+// gzipTo writes the gzipped content of r to w. The gzip header has no
+// timestamp, so the output is stable between builds and the MD5 can be
+// compared with the remote.
+func gzipTo(w io.Writer, r io.Reader) error {
+	gz, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(gz, r); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// store is the storage backend of a deploy target.
+type store interface {
+	// List lists all files in the store.
+	List() (map[string]*RemoteFile, error)
+
+	// Put creates or replaces the given file.
+	Put(f *LocalFile) error
+
+	// Delete deletes the file with the given path.
+	Delete(path string) error
+}
+
+// Upload is a planned upload.
+type Upload struct {
+	*LocalFile
+
+	// Why this file is uploaded.
+	Reason string
+}
+
+// Plan holds the changes needed to bring a target in sync.
+type Plan struct {
+	Uploads   []*Upload
+	Deletes   []string
+	Unchanged int
+
+	// Files in the target that will be kept even if they no longer exist
+	// locally, because DeleteStale isn't set.
+	Stale int
+}
+
+// Changed returns the paths uploaded or deleted by this plan.
+func (p *Plan) Changed() []string {
+	var paths []string
+	for _, u := range p.Uploads {
+		paths = append(paths, "/"+u.Path)
+	}
+	for _, d := range p.Deletes {
+		paths = append(paths, "/"+d)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Deployer deploys the content of a publish dir to a target.
+type Deployer struct {
+	cfg    Config
+	target *Target
+	store  store
+
+	// The file system and dir to deploy from.
+	fs         fsintra.Fs
+	publishDir string
+
+	// Only print what would be done.
+	DryRun bool
+
+	// Skip the confirmation when deleting more than MaxDeletes files.
+	Force bool
+
+	// Used to confirm deletes above the MaxDeletes threshold. If nil, such
+	// deploys fail unless Force is set.
+	Confirm func(prompt string) bool
+
+	// Where progress is reported.
+	Out io.Writer
+
+	// The number of concurrent uploads.
+	Workers int
+}
+
+// New creates a new Deployer for the named target. If targetName is empty,
+// the first target is used.
+func New(cfg Config, targetName string, fs fsintra.Fs, publishDir string) (*Deployer, error) {
+	t, err := cfg.Target(targetName)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := newStore(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Deployer{
+		cfg:        cfg,
+		target:     t,
+		store:      st,
+		fs:         fs,
+		publishDir: publishDir,
+		Out:        ioutil.Discard,
+		Workers:    8,
+	}, nil
+}
+
+// Target returns the target deployed to.
+func (d *Deployer) Target() *Target {
+	return d.target
+}
+
+func newStore(t *Target) (store, error) {
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		return nil, fmt.Errorf("deploy target %q: invalid URL: %s", t.Name, err)
+	}
+
+	switch u.Scheme {
+	case "file":
+		dir := filepath.FromSlash(u.Host + u.Path)
+		if dir == "" {
+			return nil, fmt.Errorf("deploy target %q: no directory in URL", t.Name)
+		}
+		return newDirStore(geanfs.Os, dir, t), nil
+	case "s3":
+		return newS3Store(u)
+	default:
+		return nil, fmt.Errorf("deploy target %q: unsupported URL scheme %q", t.Name, u.Scheme)
+	}
+}
+
+// Plan compares the publish dir with the target and returns the changes
+// needed.
+func (d *Deployer) Plan() (*Plan, error) {
+	local, err := d.walkLocal()
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := d.store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list target %q: %s", d.target.Name, err)
+	}
+
+	return d.plan(local, remote), nil
+}
+
+func (d *Deployer) plan(local map[string]*LocalFile, remote map[string]*RemoteFile) *Plan {
+	p := &Plan{}
+
+	for _, lf := range local {
+		rf, found := remote[lf.Path]
+		var reason string
+		switch {
+		case !found:
+			reason = "new"
+		case lf.force:
+			reason = "forced"
+		case rf.Size != lf.Size:
+			reason = "size changed"
+		case !bytes.Equal(rf.MD5, lf.MD5):
+			reason = "content changed"
+		default:
+			p.Unchanged++
+			continue
+		}
+		p.Uploads = append(p.Uploads, &Upload{LocalFile: lf, Reason: reason})
+	}
+
+	for path := range remote {
+		if _, found := local[path]; found || !d.target.included(path) {
+			continue
+		}
+		if d.target.DeleteStale {
+			p.Deletes = append(p.Deletes, path)
+		} else {
+			p.Stale++
+		}
+	}
+
+	sort.Sort(uploadsByPath(p.Uploads))
+	sort.Strings(p.Deletes)
+
+	return p
+}
+
+// Deploy syncs the publish dir to the target.
+func (d *Deployer) Deploy() (*Plan, error) {
+	p, err := d.Plan()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(d.Out, "Deploying to %q: %d to upload, %d to delete, %d unchanged", d.target.Name, len(p.Uploads), len(p.Deletes), p.Unchanged)
+	if p.Stale > 0 {
+		fmt.Fprintf(d.Out, ", %d stale kept", p.Stale)
+	}
+	fmt.Fprintln(d.Out)
+
+	if d.DryRun {
+		for _, u := range p.Uploads {
+			fmt.Fprintf(d.Out, "  upload %s (%s)\n", u.Path, u.Reason)
+		}
+		for _, path := range p.Deletes {
+			fmt.Fprintf(d.Out, "  delete %s\n", path)
+		}
+		return p, nil
+	}
+
+	if len(p.Deletes) > d.cfg.MaxDeletes && !d.Force {
+		prompt := fmt.Sprintf("This will delete %d files from %q. Continue?", len(p.Deletes), d.target.Name)
+		if d.Confirm == nil || !d.Confirm(prompt) {
+			return p, fmt.Errorf("deploy would delete %d files, more than maxDeletes (%d); use --force to deploy anyway", len(p.Deletes), d.cfg.MaxDeletes)
+		}
+	}
+
+	if err := d.upload(p.Uploads); err != nil {
+		return p, err
+	}
+
+	for _, path := range p.Deletes {
+		fmt.Fprintf(d.Out, "  delete %s\n", path)
+		if err := d.store.Delete(path); err != nil {
+			return p, fmt.Errorf("failed to delete %q: %s", path, err)
+		}
+	}
+
+	if len(p.Uploads)+len(p.Deletes) > 0 {
+		if err := d.invalidate(p.Changed()); err != nil {
+			return p, err
+		}
+	}
+
+	return p, nil
+}
+
+func (d *Deployer) upload(uploads []*Upload) error {
+	if len(uploads) == 0 {
+		return nil
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		errs    []string
+		jobs    = make(chan *Upload)
+		workers = d.Workers
+	)
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range jobs {
+				err := d.store.Put(u.LocalFile)
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Sprintf("%s: %s", u.Path, err))
+				} else {
+					fmt.Fprintf(d.Out, "  upload %s (%s)\n", u.Path, u.Reason)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, u := range uploads {
+		jobs <- u
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to upload %d file(s):\n%s", len(errs), strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
+
+// invalidate runs the target's invalidation hooks with the changed paths.
+func (d *Deployer) invalidate(paths []string) error {
+	if d.target.InvalidateCommand != "" {
+		fields := strings.Fields(d.target.InvalidateCommand)
+		cmd := exec.Command(fields[0], fields[1:]...)
+		cmd.Stdin = strings.NewReader(strings.Join(paths, "\n") + "\n")
+		cmd.Stdout = d.Out
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("invalidate command failed: %s", err)
+		}
+	}
+
+	if d.target.InvalidateURL != "" {
+		b, err := json.Marshal(map[string]interface{}{"target": d.target.Name, "paths": paths})
+		if err != nil {
+			return err
+		}
+		resp, err := http.Post(d.target.InvalidateURL, "application/json", bytes.NewReader(b))
+		if err != nil {
+			return fmt.Errorf("invalidate request failed: %s", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("invalidate request failed: %s", resp.Status)
+		}
+	}
+
+	return nil
+}
+
+// walkLocal hashes all files in the publish dir and prepares them for upload.
+func (d *Deployer) walkLocal() (map[string]*LocalFile, error) {
+	files := make(map[string]*LocalFile)
+
+	walker := func(filename string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if filename != d.publishDir && isDotFile(info.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(d.publishDir, filename)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if isDotFile(info.Name()) || !d.target.included(rel) {
+			return nil
+		}
+
+		lf, err := d.newLocalFile(rel, filename)
+		if err != nil {
+			return err
+		}
+
+		files[rel] = lf
+
+		return nil
+	}
+
+	if err := fsintra.Walk(d.fs, d.publishDir, walker); err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files found in %q; build the site before deploying", d.publishDir)
+	}
+
+	return files, nil
+}
+
+// newLocalFile prepares the file with the given filename in the publish dir for
+// upload as rel.
+func (d *Deployer) newLocalFile(rel, filename string) (*LocalFile, error) {
+	lf := &LocalFile{Path: rel, fs: d.fs, filename: filename}
+
+	if m := d.cfg.matcher(rel); m != nil {
+		lf.CacheControl = m.CacheControl
+		lf.ContentEncoding = m.ContentEncoding
+		lf.ContentType = m.ContentType
+		lf.force = m.Force
+	}
+
+	if lf.ContentType == "" {
+		lf.ContentType = mime.TypeByExtension(path.Ext(rel))
+	}
+
+	if lf.ContentType == "" {
+		contentType, err := detectContentType(d.fs, filename)
+		if err != nil {
+			return nil, err
+		}
+		lf.ContentType = contentType
+	}
+
+	r, err := lf.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	h := md5.New()
+	if lf.Size, err = io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	lf.MD5 = h.Sum(nil)
+
+	return lf, nil
+}
+
+// detectContentType sniffs the content type from the start of the file.
+func detectContentType(fs fsintra.Fs, filename string) (string, error) {
+	f, err := fs.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	// DetectContentType considers at most 512 bytes.
+	b := make([]byte, 512)
+	n, err := io.ReadFull(f, b)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	return http.DetectContentType(b[:n]), nil
+}
+
+// isDotFile returns whether the named file or dir is hidden, e.g. .DS_Store
+// or .git. These are never deployed nor deleted.
+func isDotFile(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+// hasDotSegment returns whether any dir or file in the slash separated path
+// is a dotfile, e.g. .well-known/security.txt.
+func hasDotSegment(path string) bool {
+	for _, name := range strings.Split(path, "/") {
+		if isDotFile(name) {
+			return true
+		}
+	}
+	return false
+}
+
+type uploadsByPath []*Upload
+
+func (u uploadsByPath) Len() int           { return len(u) }
+func (u uploadsByPath) Swap(i, j int)      { u[i], u[j] = u[j], u[i] }
+func (u uploadsByPath) Less(i, j int) bool { return u[i].Path < u[j].Path }
@@ -0,0 +1,258 @@
+package deploy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/govenue/fsintra"
+	"github.com/govenue/require"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range []struct {
+		pattern string
+		path    string
+		expect  bool
+	}{
+		{"*.html", "index.html", true},
+		{"*.html", "posts/index.html", false},
+		{"**/*.html", "index.html", true},
+		{"**/*.html", "posts/a/index.html", true},
+		{"posts/**", "posts/a/index.html", true},
+		{"posts/**", "index.html", false},
+		{"**/*.{css,js}", "css/main.css", true},
+		{"**/*.{css,js}", "js/main.js", true},
+		{"**/*.{css,js}", "js/main.json", false},
+		{"img/?.png", "img/a.png", true},
+		{"img/?.png", "img/ab.png", false},
+		{"a+b.txt", "a+b.txt", true},
+	} {
+		re, err := globToRegexp(test.pattern)
+		require.NoError(t, err)
+		require.Equal(t, test.expect, re.MatchString(test.path), "[%d] %s %s", i, test.pattern, test.path)
+	}
+
+	_, err := globToRegexp("*.{css,js")
+	require.Error(t, err)
+}
+
+func TestDecodeConfig(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	cfg, err := DecodeConfig(map[string]interface{}{
+		"targets": []map[string]interface{}{
+			{"name": "staging", "url": "file:///tmp/staging"},
+			{"name": "production", "url": "s3://bucket", "deleteStale": true, "exclude": "**/*.map"},
+		},
+		"matchers": []map[string]interface{}{
+			{"pattern": "**/*.css", "cacheControl": "max-age=300", "contentEncoding": "gzip"},
+		},
+	})
+
+	assert.NoError(err)
+	assert.Equal(defaultMaxDeletes, cfg.MaxDeletes)
+	assert.Len(cfg.Targets, 2)
+	assert.True(cfg.Targets[1].DeleteStale)
+	assert.False(cfg.Targets[1].included("js/main.js.map"))
+	assert.True(cfg.Targets[1].included("js/main.js"))
+
+	tgt, err := cfg.Target("")
+	assert.NoError(err)
+	assert.Equal("staging", tgt.Name)
+	tgt, err = cfg.Target("production")
+	assert.NoError(err)
+	assert.Equal("production", tgt.Name)
+	_, err = cfg.Target("nope")
+	assert.Error(err)
+
+	m := cfg.matcher("css/main.css")
+	assert.NotNil(m)
+	assert.Equal("max-age=300", m.CacheControl)
+	assert.Nil(cfg.matcher("index.html"))
+
+	for _, in := range []map[string]interface{}{
+		{"targets": []map[string]interface{}{{"url": "file:///tmp"}}},
+		{"targets": []map[string]interface{}{{"name": "a"}}},
+		{"targets": []map[string]interface{}{{"name": "a", "url": "file:///a"}, {"name": "a", "url": "file:///b"}}},
+		{"matchers": []map[string]interface{}{{"pattern": "*.css", "contentEncoding": "br"}}},
+	} {
+		_, err := DecodeConfig(in)
+		assert.Error(err)
+	}
+}
+
+func newTestDeployer(t *testing.T, deleteStale bool) (*Deployer, fsintra.Fs) {
+	cfg, err := DecodeConfig(map[string]interface{}{
+		"maxDeletes": 1,
+		"targets": []map[string]interface{}{
+			{"name": "test", "url": "file:///remote", "deleteStale": deleteStale},
+		},
+		"matchers": []map[string]interface{}{
+			{"pattern": "**/*.css", "cacheControl": "max-age=300", "contentEncoding": "gzip"},
+		},
+	})
+	require.NoError(t, err)
+
+	fs := fsintra.NewMemMapFs()
+	d := &Deployer{
+		cfg:        cfg,
+		target:     cfg.Targets[0],
+		store:      newDirStore(fs, "/remote", cfg.Targets[0]),
+		fs:         fs,
+		publishDir: "/public",
+		Out:        ioutil.Discard,
+		Workers:    2,
+	}
+
+	return d, fs
+}
+
+func writeFile(t *testing.T, fs fsintra.Fs, filename, content string) {
+	require.NoError(t, fsintra.WriteFile(fs, filepath.FromSlash(filename), []byte(content), 0755))
+}
+
+func readFile(t *testing.T, fs fsintra.Fs, filename string) string {
+	b, err := fsintra.ReadFile(fs, filepath.FromSlash(filename))
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestDeploy(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	d, fs := newTestDeployer(t, true)
+
+	writeFile(t, fs, "/public/index.html", "<html>Home</html>")
+	writeFile(t, fs, "/public/posts/p1/index.html", "<html>P1</html>")
+	writeFile(t, fs, "/public/css/main.css", "body { color: blue; }")
+	writeFile(t, fs, "/public/.DS_Store", "junk")
+
+	p, err := d.Deploy()
+	assert.NoError(err)
+	assert.Len(p.Uploads, 3)
+	assert.Len(p.Deletes, 0)
+	assert.Equal("css/main.css", p.Uploads[0].Path)
+	assert.Equal("text/css; charset=utf-8", p.Uploads[0].ContentType)
+	assert.Equal("max-age=300", p.Uploads[0].CacheControl)
+
+	assert.Equal("<html>P1</html>", readFile(t, fs, "/remote/posts/p1/index.html"))
+
+	gz, err := gzip.NewReader(bytes.NewReader([]byte(readFile(t, fs, "/remote/css/main.css"))))
+	assert.NoError(err)
+	css, err := ioutil.ReadAll(gz)
+	assert.NoError(err)
+	assert.Equal("body { color: blue; }", string(css))
+
+	exists, _ := fsintra.Exists(fs, filepath.FromSlash("/remote/.DS_Store"))
+	assert.False(exists)
+
+	// Nothing changed, including the gzipped file.
+	p, err = d.Deploy()
+	assert.NoError(err)
+	assert.Len(p.Uploads, 0)
+	assert.Len(p.Deletes, 0)
+	assert.Equal(3, p.Unchanged)
+
+	// Change one file, delete another.
+	writeFile(t, fs, "/public/index.html", "<html>Home!</html>")
+	assert.NoError(fs.Remove(filepath.FromSlash("/public/posts/p1/index.html")))
+
+	d.DryRun = true
+	p, err = d.Deploy()
+	assert.NoError(err)
+	assert.Len(p.Uploads, 1)
+	assert.Equal("size changed", p.Uploads[0].Reason)
+	assert.Equal([]string{"posts/p1/index.html"}, p.Deletes)
+	assert.Equal("<html>Home</html>", readFile(t, fs, "/remote/index.html"))
+
+	d.DryRun = false
+	p, err = d.Deploy()
+	assert.NoError(err)
+	assert.Equal([]string{"/index.html", "/posts/p1/index.html"}, p.Changed())
+	assert.Equal("<html>Home!</html>", readFile(t, fs, "/remote/index.html"))
+	exists, _ = fsintra.Exists(fs, filepath.FromSlash("/remote/posts/p1/index.html"))
+	assert.False(exists)
+}
+
+func TestDeployMaxDeletes(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	d, fs := newTestDeployer(t, true)
+
+	writeFile(t, fs, "/public/index.html", "Home")
+	writeFile(t, fs, "/remote/a.html", "A")
+	writeFile(t, fs, "/remote/b.html", "B")
+
+	_, err := d.Deploy()
+	assert.Error(err)
+	assert.Equal("B", readFile(t, fs, "/remote/b.html"))
+
+	var prompted string
+	d.Confirm = func(prompt string) bool {
+		prompted = prompt
+		return false
+	}
+	_, err = d.Deploy()
+	assert.Error(err)
+	assert.Contains(prompted, "delete 2 files")
+
+	d.Force = true
+	p, err := d.Deploy()
+	assert.NoError(err)
+	assert.Len(p.Deletes, 2)
+}
+
+func TestDeployKeepStale(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	d, fs := newTestDeployer(t, false)
+
+	writeFile(t, fs, "/public/index.html", "Home")
+	writeFile(t, fs, "/remote/a.html", "A")
+
+	p, err := d.Deploy()
+	assert.NoError(err)
+	assert.Len(p.Deletes, 0)
+	assert.Equal(1, p.Stale)
+	assert.Equal("A", readFile(t, fs, "/remote/a.html"))
+}
+
+func TestDeployDirTargetSkipsDotFiles(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	d, fs := newTestDeployer(t, true)
+	d.target.Exclude = "uploads/**"
+	var err error
+	d.target.excludeRe, err = globToRegexp(d.target.Exclude)
+	assert.NoError(err)
+
+	writeFile(t, fs, "/public/index.html", "Home")
+	writeFile(t, fs, "/remote/.git/HEAD", "ref: refs/heads/master")
+	writeFile(t, fs, "/remote/.nojekyll", "")
+	writeFile(t, fs, "/remote/uploads/a.mp3", "audio")
+
+	files, err := d.store.List()
+	assert.NoError(err)
+	assert.Len(files, 0)
+
+	p, err := d.Deploy()
+	assert.NoError(err)
+	assert.Len(p.Deletes, 0)
+	assert.Equal("ref: refs/heads/master", readFile(t, fs, "/remote/.git/HEAD"))
+	assert.Equal("audio", readFile(t, fs, "/remote/uploads/a.mp3"))
+}
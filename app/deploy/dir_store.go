@@ -0,0 +1,99 @@
+package deploy
+
+import (
+	"crypto/md5"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/govenue/fsintra"
+)
+
+var _ store = (*dirStore)(nil)
+
+// dirStore is a store backed by a local or mounted directory.
+// Headers such as Cache-Control have no meaning here, but the content
+// encoding is applied, so the files can be served pre-compressed.
+type dirStore struct {
+	fs  fsintra.Fs
+	dir string
+
+	// Used to skip the files not deployed, as in the publish dir.
+	target *Target
+}
+
+func newDirStore(fs fsintra.Fs, dir string, target *Target) *dirStore {
+	return &dirStore{fs: fs, dir: filepath.Clean(dir), target: target}
+}
+
+// List lists the files in the directory, skipping dotfiles, e.g. .git, and the
+// files excluded from the target, so they are never deleted as stale.
+func (s *dirStore) List() (map[string]*RemoteFile, error) {
+	files := make(map[string]*RemoteFile)
+
+	if exists, err := fsintra.DirExists(s.fs, s.dir); err != nil || !exists {
+		// Nothing deployed yet.
+		return files, err
+	}
+
+	walker := func(filename string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if filename != s.dir && isDotFile(info.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.dir, filename)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if isDotFile(info.Name()) || !s.target.included(rel) {
+			return nil
+		}
+
+		f, err := s.fs.Open(filename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		h := md5.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+
+		files[rel] = &RemoteFile{Path: rel, MD5: h.Sum(nil), Size: info.Size()}
+
+		return nil
+	}
+
+	if err := fsintra.Walk(s.fs, s.dir, walker); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+func (s *dirStore) Put(f *LocalFile) error {
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return fsintra.WriteReader(s.fs, s.filename(f.Path), r)
+}
+
+func (s *dirStore) Delete(path string) error {
+	return s.fs.Remove(s.filename(path))
+}
+
+func (s *dirStore) filename(path string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(path))
+}
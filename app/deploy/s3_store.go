@@ -0,0 +1,357 @@
+package deploy
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+var _ store = (*s3Store)(nil)
+
+// s3Store is a store backed by an S3-compatible object storage.
+// It uses path-style requests signed with AWS Signature Version 4, which
+// works with AWS and most compatible servers, e.g. MinIO.
+//
+// The credentials are read from the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+// and, if set, AWS_SESSION_TOKEN environment variables.
+type s3Store struct {
+	endpoint *url.URL
+	bucket   string
+	prefix   string
+	region   string
+
+	accessKey    string
+	secretKey    string
+	sessionToken string
+
+	client *http.Client
+
+	// Used in tests.
+	now func() time.Time
+}
+
+// newS3Store creates a new store from an URL on the form
+// s3://bucket/prefix?region=us-east-1&endpoint=http://localhost:9000
+func newS3Store(u *url.URL) (*s3Store, error) {
+	if u.Host == "" {
+		return nil, errors.New("s3: no bucket in URL")
+	}
+
+	q := u.Query()
+
+	region := q.Get("region")
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	endpoint := q.Get("endpoint")
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+
+	eu, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("s3: invalid endpoint: %s", err)
+	}
+
+	s := &s3Store{
+		endpoint:     eu,
+		bucket:       u.Host,
+		prefix:       strings.Trim(u.Path, "/"),
+		region:       region,
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		client:       &http.Client{Timeout: 5 * time.Minute},
+		now:          time.Now,
+	}
+
+	if s.accessKey == "" || s.secretKey == "" {
+		return nil, errors.New("s3: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+
+	return s, nil
+}
+
+type s3ListResult struct {
+	Contents []struct {
+		Key  string
+		ETag string
+		Size int64
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+func (s *s3Store) List() (map[string]*RemoteFile, error) {
+	files := make(map[string]*RemoteFile)
+
+	prefix := s.prefix
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	var token string
+
+	for {
+		q := url.Values{}
+		q.Set("list-type", "2")
+		if prefix != "" {
+			q.Set("prefix", prefix)
+		}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+
+		resp, err := s.do("GET", "", q, nil, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("s3: failed to decode listing: %s", err)
+		}
+
+		for _, c := range result.Contents {
+			path := strings.TrimPrefix(c.Key, prefix)
+			if path == "" || strings.HasSuffix(path, "/") || hasDotSegment(path) {
+				continue
+			}
+			// The ETag is the MD5 of the content for all but multipart
+			// uploads, which we don't do. Those will just be uploaded again.
+			sum, _ := hex.DecodeString(strings.Trim(c.ETag, `"`))
+			files[path] = &RemoteFile{Path: path, MD5: sum, Size: c.Size}
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+
+	return files, nil
+}
+
+func (s *s3Store) Put(f *LocalFile) error {
+	header := http.Header{}
+	header.Set("Content-Type", f.ContentType)
+	header.Set("Content-MD5", base64.StdEncoding.EncodeToString(f.MD5))
+	if f.CacheControl != "" {
+		header.Set("Cache-Control", f.CacheControl)
+	}
+	if f.ContentEncoding != "" {
+		header.Set("Content-Encoding", f.ContentEncoding)
+	}
+
+	body, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	resp, err := s.do("PUT", s.key(f.Path), nil, header, body, f.Size)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *s3Store) Delete(path string) error {
+	resp, err := s.do("DELETE", s.key(path), nil, nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *s3Store) key(path string) string {
+	if s.prefix == "" {
+		return path
+	}
+	return s.prefix + "/" + path
+}
+
+// do performs a signed request for the given object key, or the bucket
+// if key is empty. The body, if any, is streamed with the given size and is
+// not part of the signature; its integrity is checked with Content-MD5.
+func (s *s3Store) do(method, key string, query url.Values, header http.Header, body io.Reader, size int64) (*http.Response, error) {
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawPath = escapePath(u.Path)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	payloadHash := emptyPayloadHash
+	if body != nil {
+		req.ContentLength = size
+		if size == 0 {
+			// Else the request would be sent chunked.
+			req.Body = http.NoBody
+		}
+		payloadHash = unsignedPayload
+	}
+
+	s.sign(req, u.RawPath, payloadHash)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("s3: %s %s: %s: %s", method, u.Path, resp.Status, strings.TrimSpace(string(b)))
+	}
+
+	return resp, nil
+}
+
+const (
+	// The SHA-256 of an empty payload.
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	// Used in place of the payload hash for streamed uploads.
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// sign adds an AWS Signature Version 4 Authorization header to req.
+func (s *s3Store) sign(req *http.Request, canonicalURI string, payloadHashHex string) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHashHex)
+	if s.sessionToken != "" {
+		req.Header.Set("x-amz-security-token", s.sessionToken)
+	}
+
+	signed := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-date":           amzDate,
+		"x-amz-content-sha256": payloadHashHex,
+	}
+	for _, h := range []string{"Content-Type", "Content-MD5", "x-amz-security-token"} {
+		if v := req.Header.Get(h); v != "" {
+			signed[strings.ToLower(h)] = v
+		}
+	}
+
+	var names []string
+	for k := range signed {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders bytes.Buffer
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + strings.TrimSpace(signed[k]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHashHex,
+	}, "\n")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	crHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(crHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalQuery encodes the query as required by Signature Version 4.
+func canonicalQuery(q url.Values) string {
+	if len(q) == 0 {
+		return ""
+	}
+
+	var keys []string
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		for _, v := range q[k] {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+func escapePath(p string) string {
+	return uriEncode(p, false)
+}
+
+// uriEncode escapes everything but the unreserved characters, and
+// the slash if encodeSlash is false.
+func uriEncode(s string, encodeSlash bool) string {
+	var b bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
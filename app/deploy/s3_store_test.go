@@ -0,0 +1,133 @@
+package deploy
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/govenue/fsintra"
+	"github.com/govenue/require"
+)
+
+// fakeS3 is a minimal in-memory stand-in for an S3-compatible server.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	headers map[string]http.Header
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+		http.Error(w, "unauthorized", http.StatusForbidden)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+
+	switch r.Method {
+	case "GET":
+		prefix := r.URL.Query().Get("prefix")
+		var keys []string
+		for k := range s.objects {
+			if strings.HasPrefix(k, prefix) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		fmt.Fprint(w, "<ListBucketResult>")
+		for _, k := range keys {
+			sum := md5.Sum(s.objects[k])
+			fmt.Fprintf(w, `<Contents><Key>%s</Key><ETag>"%s"</ETag><Size>%d</Size></Contents>`, k, hex.EncodeToString(sum[:]), len(s.objects[k]))
+		}
+		fmt.Fprint(w, "<IsTruncated>false</IsTruncated></ListBucketResult>")
+	case "PUT":
+		b, _ := ioutil.ReadAll(r.Body)
+		s.objects[key] = b
+		s.headers[key] = r.Header
+	case "DELETE":
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "unsupported", http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	assert := require.New(t)
+
+	fake := &fakeS3{
+		objects: map[string][]byte{
+			"site/old.html":                 []byte("old"),
+			"site/.htaccess":                []byte("deny"),
+			"site/.well-known/security.txt": []byte("contact"),
+			"site/a/.hidden":                []byte("hidden"),
+			"other/keep.html":               []byte("keep"),
+		},
+		headers: make(map[string]http.Header),
+	}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	os.Setenv("AWS_ACCESS_KEY_ID", "key")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	defer os.Unsetenv("AWS_ACCESS_KEY_ID")
+	defer os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+
+	u, err := url.Parse("s3://bucket/site?region=eu-west-1&endpoint=" + url.QueryEscape(srv.URL))
+	assert.NoError(err)
+
+	s, err := newS3Store(u)
+	assert.NoError(err)
+	assert.Equal("site", s.prefix)
+	assert.Equal("eu-west-1", s.region)
+
+	// The dotfiles are neither listed nor deleted as stale.
+	files, err := s.List()
+	assert.NoError(err)
+	assert.Len(files, 1)
+	assert.Equal(int64(3), files["old.html"].Size)
+
+	fs := fsintra.NewMemMapFs()
+	assert.NoError(fsintra.WriteFile(fs, filepath.FromSlash("/public/a b/index"), []byte("<html>Hi</html>"), 0755))
+
+	d := &Deployer{fs: fs}
+	lf, err := d.newLocalFile("a b/index.html", filepath.FromSlash("/public/a b/index"))
+	assert.NoError(err)
+	lf.CacheControl = "max-age=60"
+
+	assert.NoError(s.Put(lf))
+	assert.Equal("<html>Hi</html>", string(fake.objects["site/a b/index.html"]))
+	assert.Equal("max-age=60", fake.headers["site/a b/index.html"].Get("Cache-Control"))
+	assert.Equal("text/html; charset=utf-8", fake.headers["site/a b/index.html"].Get("Content-Type"))
+
+	files, err = s.List()
+	assert.NoError(err)
+	assert.Len(files, 2)
+	assert.Equal(lf.MD5, files["a b/index.html"].MD5)
+
+	assert.NoError(s.Delete("old.html"))
+	_, found := fake.objects["site/old.html"]
+	assert.False(found)
+	assert.Contains(fake.objects, "other/keep.html")
+}
+
+func TestURIEncode(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "/a%20b/c~d.html", escapePath("/a b/c~d.html"))
+	require.Equal(t, "a%2Fb%0A", uriEncode("a/b\n", true))
+	require.Equal(t, "list-type=2&prefix=site%2F", canonicalQuery(url.Values{"prefix": {"site/"}, "list-type": {"2"}}))
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/geego/gean/app/helpers"
 	"github.com/geego/gean/app/metrics"
 	"github.com/geego/gean/app/output"
+	"github.com/geego/gean/app/security"
 	"github.com/geego/gean/app/tpl"
 	"github.com/govenue/notepad"
 )
@@ -36,6 +37,9 @@ type Deps struct {
 	// The configuration to use
 	Cfg config.Provider `json:"-"`
 
+	// The security policy of the template functions. Nil is the default policy.
+	Security *security.Policy `json:"-"`
+
 	// The translation func to use
 	Translate func(translationID string, args ...interface{}) string `json:"-"`
 
@@ -50,6 +54,9 @@ type Deps struct {
 	translationProvider ResourceProvider
 
 	Metrics metrics.Provider
+
+	// Optional observer of partial executions.
+	PartialObserver tpl.PartialObserver `json:"-"`
 }
 
 // ResourceProvider is used to create and refresh, and clone resources needed.
@@ -117,7 +124,12 @@ func New(cfg DepsCfg) (*Deps, error) {
 		return nil, err
 	}
 
-	contentSpec, err := helpers.NewContentSpec(cfg.Language)
+	securityPolicy, err := security.DecodeConfig(cfg.Language.Get("security"))
+	if err != nil {
+		return nil, err
+	}
+
+	contentSpec, err := helpers.NewContentSpecWithSecurity(cfg.Language, securityPolicy)
 	if err != nil {
 		return nil, err
 	}
@@ -130,6 +142,7 @@ func New(cfg DepsCfg) (*Deps, error) {
 		WithTemplate:        cfg.WithTemplate,
 		PathSpec:            ps,
 		ContentSpec:         contentSpec,
+		Security:            securityPolicy,
 		Cfg:                 cfg.Language,
 		Language:            cfg.Language,
 	}
@@ -151,7 +164,7 @@ func (d Deps) ForLanguage(l *helpers.Language) (*Deps, error) {
 		return nil, err
 	}
 
-	d.ContentSpec, err = helpers.NewContentSpec(l)
+	d.ContentSpec, err = helpers.NewContentSpecWithSecurity(l, d.Security)
 	if err != nil {
 		return nil, err
 	}
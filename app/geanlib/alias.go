@@ -94,7 +94,12 @@ func (s *Site) publishDestAlias(allowRoot bool, path, permalink string, p *Page)
 		return err
 	}
 
-	return s.publish(targetPath, aliasContent)
+	source := fmt.Sprintf("redirect to %s", permalink)
+	if p != nil {
+		source = fmt.Sprintf("alias %s of %s", path, p.sourceDescription())
+	}
+
+	return s.publish(source, targetPath, aliasContent)
 
 }
 
@@ -153,6 +153,7 @@ func loadDefaultSettingsFor(v *configurator.Configurator) error {
 	}
 
 	v.SetDefault("cleanDestinationDir", false)
+	v.SetDefault("cleanStaleOutput", true)
 	v.SetDefault("watch", false)
 	v.SetDefault("metaDataFormat", "toml")
 	v.SetDefault("disable404", false)
@@ -174,6 +175,9 @@ func loadDefaultSettingsFor(v *configurator.Configurator) error {
 	v.SetDefault("uglyURLs", false)
 	v.SetDefault("verbose", false)
 	v.SetDefault("ignoreCache", false)
+	v.SetDefault("offline", false)
+	v.SetDefault("remoteDataTimeout", "30s")
+	v.SetDefault("remoteDataMaxAge", -1)
 	v.SetDefault("canonifyURLs", false)
 	v.SetDefault("relativeURLs", false)
 	v.SetDefault("removePathAccents", false)
@@ -213,6 +217,9 @@ func loadDefaultSettingsFor(v *configurator.Configurator) error {
 	v.SetDefault("disableAliases", false)
 	v.SetDefault("debug", false)
 	v.SetDefault("disableFastRender", false)
+	v.SetDefault("atomicPublish", false)
+	v.SetDefault("keepBuilds", 5)
+	v.SetDefault("strict", false)
 
 	return loadLanguageSettings(v, nil)
 }
@@ -0,0 +1,344 @@
+package geanlib
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/geego/gean/app/helpers"
+	"github.com/geego/gean/app/parser"
+	"github.com/geego/gean/app/schema"
+	"github.com/govenue/fsintra"
+	"github.com/govenue/fsnotify"
+)
+
+// frontMatterKeys are the front matter keys with a meaning to Gean, see
+// Page.update. These are always accepted by a schema.
+var frontMatterKeys = []string{
+	"aliases", "authors", "date", "description", "draft", "expirydate",
+	"ext", "extension", "iscjklanguage", "keywords", "lastmod", "layout",
+	"linktitle", "markdown", "markup", "menu", "modified", "outputs",
+	"pubdate", "publishdate", "published", "series", "seriesweight",
+	"sitemap", "slug", "status", "title", "translationkey", "type",
+	"unpublishdate", "url", "weight",
+}
+
+// schemaFileSuffix is the suffix of the schema files in the archetype dirs,
+// e.g. archetypes/episode.schema.yaml.
+const schemaFileSuffix = ".schema.yaml"
+
+// FrontMatterError is a front matter value in a content file that doesn't
+// match the schema for its content type.
+type FrontMatterError struct {
+	// The content file, including the content dir, and the 1-based line
+	// of the key, or of the start of the front matter if the key is missing.
+	Filename string
+	Line     int
+
+	// The lower case front matter key and what's wrong with it.
+	Key     string
+	Message string
+}
+
+func (e *FrontMatterError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.Filename, e.Line, e.Message)
+}
+
+// frontMatterSchemas holds the schemas of a site, per content type. Those
+// in the site config are decoded up front, those in the archetype dirs are
+// read when first needed.
+type frontMatterSchemas struct {
+	mu      sync.Mutex
+	config  schema.Schemas
+	schemas schema.Schemas
+	known   map[string]bool
+}
+
+func newFrontMatterSchemas(schemas schema.Schemas, taxonomies map[string]string) *frontMatterSchemas {
+	known := make(map[string]bool)
+	for _, k := range frontMatterKeys {
+		known[k] = true
+	}
+	for _, plural := range taxonomies {
+		known[strings.ToLower(plural)] = true
+	}
+
+	s := &frontMatterSchemas{config: schemas, known: known}
+	s.reset()
+
+	return s
+}
+
+// reset drops the schemas read from the archetype dirs, so they are read
+// again when an archetype file changes in watch mode.
+func (s *frontMatterSchemas) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.schemas = make(schema.Schemas)
+	for contentType, sc := range s.config {
+		s.schemas[contentType] = sc
+	}
+}
+
+// frontMatterSchema returns the schema for the given content type, nil if
+// there is none.
+func (s *Site) frontMatterSchema(contentType string) (*schema.Schema, error) {
+	if s.schemas == nil {
+		return nil, nil
+	}
+
+	contentType = strings.ToLower(contentType)
+
+	s.schemas.mu.Lock()
+	defer s.schemas.mu.Unlock()
+
+	if sc, found := s.schemas.schemas[contentType]; found {
+		return sc, nil
+	}
+
+	// Misses are cached too, so a broken schema file is only reported once.
+	sc, err := s.readSchemaFile(contentType)
+	s.schemas.schemas[contentType] = sc
+
+	return sc, err
+}
+
+// archetypeDirs returns the project's and the theme's archetype dirs.
+func (s *Site) archetypeDirs() []string {
+	dirs := []string{s.PathSpec.AbsPathify(s.Cfg.GetString("archetypeDir"))}
+	if themeDir := s.PathSpec.GetThemeDir(); themeDir != "" {
+		dirs = append(dirs, filepath.Join(themeDir, "archetypes"))
+	}
+	return dirs
+}
+
+// isSchemaFileEvent reports whether e is for a schema file in one of the
+// archetype dirs.
+func (s *Site) isSchemaFileEvent(e fsnotify.Event) bool {
+	if !strings.HasSuffix(e.Name, schemaFileSuffix) {
+		return false
+	}
+	for _, dir := range s.archetypeDirs() {
+		if s.getRealDir(dir, e.Name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// readSchemaFile reads the schema for the given content type from the
+// project's or the theme's archetype dir, nil if not found.
+func (s *Site) readSchemaFile(contentType string) (*schema.Schema, error) {
+	for _, dir := range s.archetypeDirs() {
+		filename := filepath.Join(dir, contentType+schemaFileSuffix)
+
+		b, err := fsintra.ReadFile(s.Fs.Source, filename)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		m, err := parser.HandleYAMLMetaData(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse schema %q: %s", filename, err)
+		}
+
+		sc, err := schema.Decode(m)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema %q: %s", filename, err)
+		}
+
+		return sc, nil
+	}
+
+	return nil, nil
+}
+
+// ValidateFrontMatter validates the front matter in the given content
+// against the schema for its content type, if any. The content type is
+// taken from the type key in the front matter or from the section of
+// filename, which is relative to the content dir.
+func (s *Site) ValidateFrontMatter(filename string, content []byte) ([]*FrontMatterError, error) {
+	psr, err := parser.ReadFrom(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := psr.Metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := meta.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	p := s.newPage(filename)
+
+	return p.validateFrontMatter(psr, m)
+}
+
+// validateFrontMatter validates m, the front matter of p, against the schema
+// for its content type, and then sets the defaults in the schema.
+// Only regular pages are validated, as the list pages are of the type of
+// the pages they list.
+func (p *Page) validateFrontMatter(psr parser.Page, m map[string]interface{}) ([]*FrontMatterError, error) {
+	if p.Kind != KindPage {
+		return nil, nil
+	}
+
+	contentType := p.Source.Section()
+	for k, v := range m {
+		if strings.EqualFold(k, "type") {
+			contentType = fmt.Sprint(v)
+		}
+	}
+	if contentType == "" {
+		contentType = "page"
+	}
+
+	sc, err := p.s.frontMatterSchema(contentType)
+	if err != nil || sc == nil {
+		return nil, err
+	}
+
+	var (
+		errs     []*FrontMatterError
+		filename = filepath.ToSlash(filepath.Join(p.s.Cfg.GetString("contentDir"), p.File.Path()))
+	)
+
+	for _, e := range sc.Validate(m, p.s.schemas.known) {
+		line := psr.FrontMatterLine()
+		if keyLine := parser.FrontMatterKeyLine(psr.FrontMatter(), e.Key); keyLine > 0 {
+			line += keyLine - 1
+		}
+		errs = append(errs, &FrontMatterError{Filename: filename, Line: line, Key: e.Key, Message: e.Message})
+	}
+
+	sc.ApplyDefaults(m)
+
+	sort.Stable(frontMatterErrorsByLine(errs))
+
+	return errs, nil
+}
+
+// checkFrontMatter validates the front matter of p while parsing and
+// records the errors found.
+func (p *Page) checkFrontMatter(psr parser.Page, m map[string]interface{}) {
+	errs, err := p.validateFrontMatter(psr, m)
+	if err != nil {
+		p.s.Log.ERROR.Println(err)
+		return
+	}
+
+	for _, e := range errs {
+		p.s.Log.ERROR.Println(e)
+	}
+
+	if p.s.owner != nil && p.s.owner.frontMatterErrors != nil {
+		p.s.owner.frontMatterErrors.set(p.File.Path(), errs)
+	}
+}
+
+// frontMatterErrors holds the front matter errors per content file.
+type frontMatterErrors struct {
+	mu     sync.Mutex
+	errors map[string][]*FrontMatterError
+}
+
+func newFrontMatterErrors() *frontMatterErrors {
+	return &frontMatterErrors{errors: make(map[string][]*FrontMatterError)}
+}
+
+// set replaces the errors for filename, as a page is validated again when
+// changed in watch mode.
+func (f *frontMatterErrors) set(filename string, errs []*FrontMatterError) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(errs) == 0 {
+		delete(f.errors, filename)
+		return
+	}
+	f.errors[filename] = errs
+}
+
+// removePrefix removes the errors for the files at or below path, as for a
+// REMOVE event we don't always get the individual files.
+func (f *frontMatterErrors) removePrefix(path string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for filename := range f.errors {
+		if filename == path || strings.HasPrefix(filename, path+helpers.FilePathSeparator) {
+			delete(f.errors, filename)
+		}
+	}
+}
+
+// removeFrontMatterErrors removes the errors for the removed content files
+// at or below path, relative to the content dir.
+func (s *Site) removeFrontMatterErrors(path string) {
+	if s.owner != nil && s.owner.frontMatterErrors != nil {
+		s.owner.frontMatterErrors.removePrefix(path)
+	}
+}
+
+// FrontMatterErrors returns the front matter errors found by the schemas,
+// ordered by filename and line.
+func (h *HugoSites) FrontMatterErrors() []*FrontMatterError {
+	if h.frontMatterErrors == nil {
+		return nil
+	}
+
+	h.frontMatterErrors.mu.Lock()
+	var errs []*FrontMatterError
+	for _, e := range h.frontMatterErrors.errors {
+		errs = append(errs, e...)
+	}
+	h.frontMatterErrors.mu.Unlock()
+
+	sort.Sort(frontMatterErrorsByPosition(errs))
+
+	return errs
+}
+
+// checkFrontMatterErrors fails the build if strict is set and there are
+// front matter errors. These are already logged.
+func (h *HugoSites) checkFrontMatterErrors() error {
+	if !h.Cfg.GetBool("strict") {
+		return nil
+	}
+
+	if n := len(h.FrontMatterErrors()); n > 0 {
+		return fmt.Errorf("%d front matter error(s) found", n)
+	}
+
+	return nil
+}
+
+type frontMatterErrorsByLine []*FrontMatterError
+
+func (f frontMatterErrorsByLine) Len() int           { return len(f) }
+func (f frontMatterErrorsByLine) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+func (f frontMatterErrorsByLine) Less(i, j int) bool { return f[i].Line < f[j].Line }
+
+type frontMatterErrorsByPosition []*FrontMatterError
+
+func (f frontMatterErrorsByPosition) Len() int      { return len(f) }
+func (f frontMatterErrorsByPosition) Swap(i, j int) { f[i], f[j] = f[j], f[i] }
+func (f frontMatterErrorsByPosition) Less(i, j int) bool {
+	if f[i].Filename != f[j].Filename {
+		return f[i].Filename < f[j].Filename
+	}
+	if f[i].Line != f[j].Line {
+		return f[i].Line < f[j].Line
+	}
+	return f[i].Key < f[j].Key
+}
@@ -0,0 +1,136 @@
+package geanlib
+
+import (
+	"testing"
+
+	"github.com/govenue/fsintra"
+	"github.com/govenue/fsnotify"
+	"github.com/govenue/require"
+)
+
+const frontMatterSchemaConfig = `
+baseURL = "http://example.com/"
+disableKinds = ["sitemap", "robotsTXT", "404", "RSS"]
+
+[schemas.episode.fields.episode]
+type = "int"
+required = true
+[schemas.episode.fields.status]
+enum = ["draft", "live", "archived"]
+default = "draft"
+`
+
+func TestFrontMatterSchema(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	mf := fsintra.NewMemMapFs()
+	writeToFs(t, mf, "content/episode/ok.md", "---\ntitle: OK\nepisode: 1\ntags: [\"go\"]\nseries: Season 1\nseriesWeight: 1\n---\nOK")
+	writeToFs(t, mf, "content/episode/bad.md", "---\ntitle: Bad\nepsiode: 2\nstatus: gone\n---\nBad")
+	writeToFs(t, mf, "content/other/page.md", "+++\ntitle = \"Other\"\ntype = \"episode\"\n+++\nOther")
+	writeToFs(t, mf, "content/post/free.md", "---\ntitle: Free\nwhatever: 3\n---\nFree")
+	writeToFs(t, mf, "archetypes/post.schema.yaml", "open: true\nfields:\n  rating: {type: float, default: 3}\n")
+
+	_, h := newTestSitesFromConfig(t, mf, frontMatterSchemaConfig,
+		"layouts/_default/single.html", "Single|{{ .Title }}|{{ .Params.status }}|{{ .Params.rating }}",
+		"layouts/_default/list.html", "List|{{ .Title }}",
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	var errs []string
+	for _, e := range h.FrontMatterErrors() {
+		errs = append(errs, e.Error())
+	}
+
+	assert.Equal([]string{
+		`content/episode/bad.md:1: required key "episode" is missing`,
+		`content/episode/bad.md:3: unknown key "epsiode", did you mean "episode"?`,
+		`content/episode/bad.md:4: "status" must be one of draft, live, archived, got "gone"`,
+		`content/other/page.md:1: required key "episode" is missing`,
+	}, errs)
+
+	s := h.Sites[0]
+
+	ok := s.getPage(KindPage, "episode/ok.md")
+	assert.NotNil(ok)
+	assert.Equal("draft", ok.Params["status"])
+
+	free := s.getPage(KindPage, "post/free.md")
+	assert.NotNil(free)
+	assert.Equal(3, free.Params["rating"])
+}
+
+func TestFrontMatterSchemaStrict(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	mf := fsintra.NewMemMapFs()
+	writeToFs(t, mf, "content/episode/bad.md", "---\ntitle: Bad\nepsiode: 2\n---\nBad")
+
+	_, h := newTestSitesFromConfig(t, mf, frontMatterSchemaConfig+"\nstrict = true\n",
+		"layouts/_default/single.html", "Single|{{ .Title }}",
+		"layouts/_default/list.html", "List|{{ .Title }}",
+	)
+
+	err := h.Build(BuildCfg{})
+	assert.Error(err)
+	assert.Contains(err.Error(), "2 front matter error(s) found")
+}
+
+func TestFrontMatterSchemaRebuild(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	mf := fsintra.NewMemMapFs()
+	writeToFs(t, mf, "content/post/a.md", "---\ntitle: A\n---\nA")
+	writeToFs(t, mf, "content/post/b.md", "---\ntitle: B\n---\nB")
+	writeToFs(t, mf, "archetypes/post.schema.yaml", "fields:\n  rating: {type: float, required: true}\n")
+
+	_, h := newTestSitesFromConfig(t, mf, frontMatterSchemaConfig,
+		"layouts/_default/single.html", "Single|{{ .Title }}",
+		"layouts/_default/list.html", "List|{{ .Title }}",
+	)
+
+	cfg := BuildCfg{Watching: true}
+
+	assert.NoError(h.Build(cfg))
+	assert.Len(h.FrontMatterErrors(), 2)
+
+	// The errors of removed files are dropped.
+	assert.NoError(mf.Remove("content/post/b.md"))
+	assert.NoError(h.Build(cfg, fsnotify.Event{Name: "content/post/b.md", Op: fsnotify.Remove}))
+	assert.Len(h.FrontMatterErrors(), 1)
+
+	// A changed schema is read again and the pages validated against it.
+	writeToFs(t, mf, "archetypes/post.schema.yaml", "fields:\n  rating: {type: float, default: 3}\n")
+	assert.NoError(h.Build(cfg, fsnotify.Event{Name: "archetypes/post.schema.yaml", Op: fsnotify.Write}))
+	assert.Len(h.FrontMatterErrors(), 0)
+}
+
+func TestValidateFrontMatter(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	mf := fsintra.NewMemMapFs()
+
+	_, h := newTestSitesFromConfig(t, mf, frontMatterSchemaConfig,
+		"layouts/_default/single.html", "Single|{{ .Title }}",
+		"layouts/_default/list.html", "List|{{ .Title }}",
+	)
+
+	s := h.Sites[0]
+
+	errs, err := s.ValidateFrontMatter("episode/new.md", []byte("---\ntitle: New\nepisode: 1\n---\n"))
+	assert.NoError(err)
+	assert.Len(errs, 0)
+
+	errs, err = s.ValidateFrontMatter("episode/new.md", []byte("---\ntitle: New\nepisode: one\n---\n"))
+	assert.NoError(err)
+	assert.Len(errs, 1)
+	assert.Equal(`content/episode/new.md:3: "episode" must be an integer, got string`, errs[0].Error())
+}
@@ -24,6 +24,18 @@ type HugoSites struct {
 	// Multihost is set if multilingual and baseURL set on the language level.
 	multihost bool
 
+	// Set when render info tracking is enabled, see RenderInfo.
+	renderInfo *renderInfoCollector
+
+	// The files written during the current full build.
+	published *publishedFiles
+
+	// The failed ref and relref lookups in the current full build.
+	refErrors *refErrors
+
+	// The front matter schema errors, per content file.
+	frontMatterErrors *frontMatterErrors
+
 	*deps.Deps
 }
 
@@ -81,6 +93,8 @@ func newHugoSites(cfg deps.DepsCfg, sites ...*Site) (*HugoSites, error) {
 
 	h.Deps = sites[0].Deps
 
+	h.initRenderInfo()
+
 	return h, nil
 }
 
@@ -227,6 +241,8 @@ func (h *HugoSites) createSitesFromConfig() error {
 
 	h.Deps = sites[0].Deps
 
+	h.initRenderInfo()
+
 	h.multilingual = langConfig
 	h.multihost = h.Deps.Cfg.GetBool("multihost")
 
@@ -296,7 +312,7 @@ func (h *HugoSites) renderCrossSitesArtifacts() error {
 func (h *HugoSites) assignMissingTranslations() error {
 	// This looks heavy, but it should be a small number of nodes by now.
 	allPages := h.findAllPagesByKindNotIn(KindPage)
-	for _, nodeType := range []string{KindHome, KindSection, KindTaxonomy, KindTaxonomyTerm} {
+	for _, nodeType := range []string{KindHome, KindSection, KindTaxonomy, KindTaxonomyTerm, KindArchive, KindSeries} {
 		nodes := h.findPagesByKindIn(nodeType, allPages)
 
 		// Assign translations
@@ -343,6 +359,11 @@ func (h *HugoSites) createMissingPages() error {
 		s.Pages = append(s.Pages, newSections...)
 		newPages = append(newPages, newSections...)
 
+		// Archive pages for the periods with content.
+		newArchives := s.assembleArchives()
+		s.Pages = append(s.Pages, newArchives...)
+		newPages = append(newPages, newArchives...)
+
 		// taxonomy list and terms pages
 		taxonomies := s.Language.GetStringMapString("taxonomies")
 		if len(taxonomies) > 0 {
@@ -374,7 +395,7 @@ func (h *HugoSites) createMissingPages() error {
 							key = s.PathSpec.MakePathSanitized(key)
 						}
 						for _, p := range taxonomyPages {
-							if p.sections[0] == plural && p.sections[1] == key {
+							if p.sections[0] == plural && p.termKey() == key {
 								foundTaxonomyPage = true
 								break
 							}
@@ -388,7 +409,14 @@ func (h *HugoSites) createMissingPages() error {
 					}
 				}
 			}
+
+			s.assembleTaxonomyTrees()
 		}
+
+		// Series need the taxonomy pages, if used as landing pages.
+		newSeries := s.assembleSeries()
+		s.Pages = append(s.Pages, newSeries...)
+		newPages = append(newPages, newSeries...)
 	}
 
 	if len(newPages) > 0 {
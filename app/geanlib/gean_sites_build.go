@@ -32,6 +32,9 @@ func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) error {
 			return err
 		}
 	} else {
+		h.published = newPublishedFiles()
+		h.refErrors = &refErrors{}
+		h.frontMatterErrors = newFrontMatterErrors()
 		if err := h.init(conf); err != nil {
 			return err
 		}
@@ -41,6 +44,10 @@ func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) error {
 		return err
 	}
 
+	if err := h.checkFrontMatterErrors(); err != nil {
+		return err
+	}
+
 	if err := h.assemble(conf); err != nil {
 		return err
 	}
@@ -49,6 +56,12 @@ func (h *HugoSites) Build(config BuildCfg, events ...fsnotify.Event) error {
 		return err
 	}
 
+	for _, s := range h.Sites {
+		if err := s.relatedDocsHandler.saveContentCache(); err != nil {
+			h.Log.WARN.Println("Failed to save the related content cache:", err)
+		}
+	}
+
 	if config.PrintStats {
 		h.Log.FEEDBACK.Printf("total in %v ms\n", int(1000*time.Since(t0).Seconds()))
 	}
@@ -194,8 +207,9 @@ func (h *HugoSites) assemble(config *BuildCfg) error {
 				return err
 			}
 		}
-		s.assembleMenus()
+		// The menus may look up pages by path.
 		s.refreshPageCaches()
+		s.assembleMenus()
 		s.setupSitePages()
 	}
 
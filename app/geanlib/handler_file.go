@@ -26,7 +26,7 @@ type defaultHandler struct{ basicFileHandler }
 
 func (h defaultHandler) Extensions() []string { return []string{"*"} }
 func (h defaultHandler) FileConvert(f *source.File, s *Site) HandledResult {
-	err := s.publish(f.Path(), f.Contents)
+	err := s.publish(s.contentFileDescription(f), f.Path(), f.Contents)
 	if err != nil {
 		return HandledResult{err: err}
 	}
@@ -38,7 +38,7 @@ type cssHandler struct{ basicFileHandler }
 func (h cssHandler) Extensions() []string { return []string{"css"} }
 func (h cssHandler) FileConvert(f *source.File, s *Site) HandledResult {
 	x := cssmin.Minify(f.Bytes())
-	err := s.publish(f.Path(), bytes.NewReader(x))
+	err := s.publish(s.contentFileDescription(f), f.Path(), bytes.NewReader(x))
 	if err != nil {
 		return HandledResult{err: err}
 	}
@@ -8,8 +8,8 @@ import (
 	"github.com/govenue/assist"
 )
 
-// MenuEntry represents a menu item defined in either Page front matter
-// or in the site config.
+// MenuEntry represents a menu item defined in Page front matter, the site
+// config or a data file, or generated from the section tree.
 type MenuEntry struct {
 	URL        string
 	Name       string
@@ -20,6 +20,9 @@ type MenuEntry struct {
 	Weight     int
 	Parent     string
 	Children   Menu
+
+	// The page this entry links to, if defined by or for a page.
+	Page *Page
 }
 
 // Menu is a collection of menu entries.
@@ -43,6 +46,17 @@ func (m *MenuEntry) HasChildren() bool {
 	return m.Children != nil
 }
 
+// hasDescendantPage returns whether any of this entry's descendants links
+// to the given page.
+func (m *MenuEntry) hasDescendantPage(p *Page) bool {
+	for _, child := range m.Children {
+		if child.Page == p || child.hasDescendantPage(p) {
+			return true
+		}
+	}
+	return false
+}
+
 // KeyName returns the key used to identify this menu entry.
 func (m *MenuEntry) KeyName() string {
 	if m.Identifier != "" {
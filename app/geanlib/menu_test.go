@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/geego/gean/app/deps"
+	"github.com/geego/gean/app/geanfs"
 	"github.com/govenue/fsintra"
 	"github.com/govenue/require"
 )
@@ -79,3 +81,94 @@ Menu Main:  {{ partial "menu.html" (dict "page" . "menu" "main") }}`,
 		"Menu Sect:  /sect5/|Section Five|10|-|-|/sect1/|Section One|100|-|-|/sect2/|Sect2s|0|-|HasMenuCurrent|/sect3/|Sect3s|0|-|-|")
 
 }
+
+func TestDataAndSectionMenus(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	siteConfig := `
+baseURL = "http://example.com/"
+defaultContentLanguage = "en"
+
+[languages]
+[languages.en]
+weight = 1
+[languages.fr]
+weight = 2
+
+[sectionMenus.docs]
+section = "docs"
+depth = 2
+exclude = ["docs/internal"]
+pages = true
+`
+
+	mf := fsintra.NewMemMapFs()
+
+	writeToFs(t, mf, "data/menus/main.yaml", `
+about:
+  page: /about
+  weight: 20
+docs:
+  page: /docs
+  weight: 10
+github:
+  name: GitHub
+  url: https://github.com/geego/gean
+  weight: 30
+`)
+	writeToFs(t, mf, "data/menus/main.fr.yaml", `
+github:
+  name: GitHub FR
+`)
+
+	writeToFs(t, mf, "content/about.md", "---\ntitle: About\n---\n")
+	writeToFs(t, mf, "content/about.fr.md", "---\ntitle: A propos\n---\n")
+	writeToFs(t, mf, "content/docs/_index.md", "---\ntitle: Docs\n---\n")
+	writeToFs(t, mf, "content/docs/guide/_index.md", "---\ntitle: Guide\nweight: 1\n---\n")
+	writeToFs(t, mf, "content/docs/guide/install.md", "---\ntitle: Install\nweight: 1\n---\n")
+	writeToFs(t, mf, "content/docs/guide/advanced/_index.md", "---\ntitle: Advanced\n---\n")
+	writeToFs(t, mf, "content/docs/guide/advanced/tuning.md", "---\ntitle: Tuning\n---\n")
+	writeToFs(t, mf, "content/docs/guide/advanced/deep/_index.md", "---\ntitle: Deep\n---\n")
+	writeToFs(t, mf, "content/docs/reference/_index.md", "---\ntitle: Reference\nweight: 2\n---\n")
+	writeToFs(t, mf, "content/docs/internal/_index.md", "---\ntitle: Internal\n---\n")
+	writeToFs(t, mf, "content/docs/internal/more/_index.md", "---\ntitle: More\n---\n")
+
+	th, h := newTestSitesFromConfig(t, mf, siteConfig,
+		"layouts/partials/menu.html", `{{ range . }}{{ .Name }}:{{ .URL }}:{{ with .Page }}{{ .Kind }}{{ end }}[{{ partial "menu.html" .Children }}]{{ end }}`,
+		"layouts/_default/single.html", `Single|{{ .Title }}|Main:{{ partial "menu.html" .Site.Menus.main }}|Docs:{{ partial "menu.html" .Site.Menus.docs }}|
+{{- range .Site.Menus.main }}{{ if $.IsMenuCurrent "main" . }}Current:{{ .Name }}{{ end }}{{ end }}|
+{{- range .Site.Menus.docs }}{{ if $.HasMenuCurrent "docs" . }}Has:{{ .Name }}{{ end }}{{ end }}|`,
+		"layouts/_default/list.html", "List|{{ .Title }}",
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/about/index.html",
+		"Main:Docs:/docs/:section[]About:/about/:page[]GitHub:https://github.com/geego/gean:[]|",
+		"Docs:Guide:/docs/guide/:section[Install:/docs/guide/install/:page[]Advanced:/docs/guide/advanced/:section[]]Reference:/docs/reference/:section[]|",
+		"|Current:About|",
+	)
+	th.assertFileContent("public/docs/guide/install/index.html", "|Has:Guide|")
+	th.assertFileContent("public/fr/about/index.html",
+		"A propos:/fr/about/:page[]GitHub FR:https://github.com/geego/gean:[]|",
+		"|Current:A propos|",
+	)
+
+	menus, err := decodeSectionMenus(map[string]interface{}{"docs": map[string]interface{}{"depth": 3, "include": []interface{}{"docs/*"}}})
+	assert.NoError(err)
+	assert.Equal(sectionMenuConfig{depth: 3, include: []string{"docs/*"}}, menus["docs"])
+
+	_, err = decodeSectionMenus(map[string]interface{}{"docs": map[string]interface{}{"levels": 3}})
+	assert.Error(err)
+
+	mf = fsintra.NewMemMapFs()
+	writeToFs(t, mf, "config.toml", "baseURL = \"http://example.com/\"\n[sectionMenus.docs]\nlevels = 3\n")
+	cfg, err := LoadConfig(mf, "", "config.toml")
+	assert.NoError(err)
+
+	_, err = NewHugoSites(deps.DepsCfg{Fs: geanfs.NewFrom(mf, cfg), Cfg: cfg})
+	assert.Error(err)
+	assert.Contains(err.Error(), "levels")
+}
@@ -0,0 +1,84 @@
+package geanlib
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/geego/gean/app/source"
+)
+
+// OutputCollision is an output file written by more than one source in the
+// same build, where all but the last write are lost.
+type OutputCollision struct {
+	// The absolute filename in the destination filesystem.
+	Filename string
+
+	// Descriptions of the sources that wrote the file, sorted, e.g.
+	// "content/post/a.md" or "alias /old/ of content/post/b.md".
+	Sources []string
+}
+
+// OutputCollisions returns the files written by more than one source in the
+// last full build, sorted by filename. The files in extra, absolute
+// filenames mapped to a description of their source, are included. This is
+// used for the files copied from the static dirs.
+func (h *HugoSites) OutputCollisions(extra map[string]string) []*OutputCollision {
+	if h.published == nil {
+		return nil
+	}
+
+	h.published.mu.Lock()
+	defer h.published.mu.Unlock()
+
+	var collisions []*OutputCollision
+
+	for filename, sources := range h.published.files {
+		sources = append([]string(nil), sources...)
+		if source, found := extra[filename]; found {
+			sources = append(sources, source)
+		}
+		if len(sources) > 1 {
+			sort.Strings(sources)
+			collisions = append(collisions, &OutputCollision{Filename: filename, Sources: sources})
+		}
+	}
+
+	sort.Sort(outputCollisionsByFilename(collisions))
+
+	return collisions
+}
+
+// sourceDescription describes p for messages about its output: the content
+// file if it has one, else its kind and URL.
+func (p *Page) sourceDescription() string {
+	if p.File.Path() != "" {
+		return filepath.ToSlash(filepath.Join(p.s.Cfg.GetString("contentDir"), p.File.Path()))
+	}
+	return fmt.Sprintf("%s page %s", p.Kind, p.RelPermalink())
+}
+
+// sourceDescription describes p for messages about its output, including
+// the output format and the pager number if not the first.
+func (p *PageOutput) sourceDescription() string {
+	s := p.Page.sourceDescription()
+	if p.outputFormat.Name != "HTML" {
+		s = fmt.Sprintf("%s (%s)", s, p.outputFormat.Name)
+	}
+	if p.paginator != nil && p.paginator.PageNumber() > 1 {
+		s = fmt.Sprintf("%s, page %d", s, p.paginator.PageNumber())
+	}
+	return s
+}
+
+// contentFileDescription describes a non-page file copied from the content
+// dir.
+func (s *Site) contentFileDescription(f *source.File) string {
+	return filepath.ToSlash(filepath.Join(s.Cfg.GetString("contentDir"), f.Path()))
+}
+
+type outputCollisionsByFilename []*OutputCollision
+
+func (o outputCollisionsByFilename) Len() int           { return len(o) }
+func (o outputCollisionsByFilename) Swap(i, j int)      { o[i], o[j] = o[j], o[i] }
+func (o outputCollisionsByFilename) Less(i, j int) bool { return o[i].Filename < o[j].Filename }
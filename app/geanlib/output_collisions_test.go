@@ -0,0 +1,54 @@
+package geanlib
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/govenue/fsintra"
+	"github.com/govenue/require"
+)
+
+func TestOutputCollisions(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	siteConfig := `
+baseURL = "http://example.com/"
+disableKinds = ["sitemap", "robotsTXT", "404", "RSS"]
+`
+
+	mf := fsintra.NewMemMapFs()
+	writeToFs(t, mf, "content/a.md", "---\ntitle: A\nurl: /same/\n---\nA")
+	writeToFs(t, mf, "content/b.md", "---\ntitle: B\nurl: /same/\n---\nB")
+	writeToFs(t, mf, "content/c.md", "---\ntitle: C\naliases: [\"/d/\"]\n---\nC")
+	writeToFs(t, mf, "content/d.md", "---\ntitle: D\nurl: /d/\n---\nD")
+	writeToFs(t, mf, "content/e.md", "---\ntitle: E\nurl: /tags/go/\ntags: [\"go\"]\n---\nE")
+
+	_, h := newTestSitesFromConfig(t, mf, siteConfig,
+		"layouts/_default/single.html", "Single|{{ .Title }}",
+		"layouts/_default/list.html", "List|{{ .Title }}",
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	abs := func(name string) string {
+		return filepath.Join(h.Sites[0].absPublishDir(), filepath.FromSlash(name))
+	}
+
+	collisions := h.OutputCollisions(map[string]string{
+		abs("css/style.css"):   "static file css/style.css",
+		abs("same/index.html"): "static file same/index.html",
+	})
+
+	assert.Len(collisions, 3)
+
+	assert.Equal(abs("d/index.html"), collisions[0].Filename)
+	assert.Equal([]string{"alias /d/ of content/c.md", "content/d.md"}, collisions[0].Sources)
+
+	assert.Equal(abs("same/index.html"), collisions[1].Filename)
+	assert.Equal([]string{"content/a.md", "content/b.md", "static file same/index.html"}, collisions[1].Sources)
+
+	assert.Equal(abs("tags/go/index.html"), collisions[2].Filename)
+	assert.Equal([]string{"content/e.md", "taxonomy page /tags/go/"}, collisions[2].Sources)
+}
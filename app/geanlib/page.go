@@ -33,7 +33,7 @@ var (
 	cjk = regexp.MustCompile(`\p{Han}|\p{Hangul}|\p{Hiragana}|\p{Katakana}`)
 
 	// This is all the kinds we can expect to find in .Site.Pages.
-	allKindsInPages = []string{KindPage, KindHome, KindSection, KindTaxonomy, KindTaxonomyTerm}
+	allKindsInPages = []string{KindPage, KindHome, KindSection, KindTaxonomy, KindTaxonomyTerm, KindArchive, KindSeries}
 
 	allKinds = append(allKindsInPages, []string{kindRSS, kindSitemap, kindRobotsTXT, kind404}...)
 
@@ -42,7 +42,8 @@ var (
 	_ compare.Eqer = (*PageOutput)(nil)
 
 	// Assert that it implements the interface needed for related searches.
-	_ related.Document = (*Page)(nil)
+	_ related.Document        = (*Page)(nil)
+	_ related.ContentDocument = (*Page)(nil)
 )
 
 const (
@@ -54,6 +55,8 @@ const (
 	KindSection      = "section"
 	KindTaxonomy     = "taxonomy"
 	KindTaxonomyTerm = "taxonomyTerm"
+	KindArchive      = "archive"
+	KindSeries       = "series"
 
 	// Temporary state.
 	kindUnknown = "unknown"
@@ -185,6 +188,16 @@ type Page struct {
 	// Will only be set for section pages and the home page.
 	subSections Pages
 
+	// Will only be set for the term pages of hierarchical taxonomies.
+	childTerms Pages
+
+	// Will only be set for archive pages.
+	prevPeriod *Page
+	nextPeriod *Page
+
+	// Will only be set for regular pages in a series.
+	series *Series
+
 	s *Site
 
 	// Pulled over from old Node. TODO(bep) reorg and group (embed)
@@ -236,6 +249,12 @@ func (p *Page) SearchKeywords(cfg related.IndexConfig) ([]related.Keyword, error
 	return cfg.ToKeywords(v)
 }
 
+// SearchContent implements the related.ContentDocument interface needed for
+// content based page searches.
+func (p *Page) SearchContent(cfg related.IndexConfig) (string, error) {
+	return p.Title + " " + p.Plain(), nil
+}
+
 // PubDate is when this page was or will be published.
 // NOTE: This is currently used for search only and is not meant to be used
 // directly in templates. We need to consolidate the dates in this struct.
@@ -266,6 +285,10 @@ func (p *Page) createLayoutDescriptor() output.LayoutDescriptor {
 		section = p.sections[0]
 	case KindTaxonomy, KindTaxonomyTerm:
 		section = p.s.taxonomiesPluralSingular[p.sections[0]]
+	case KindArchive:
+		section = p.sections[0]
+	case KindSeries:
+		section = p.sections[1]
 	default:
 	}
 
@@ -746,7 +769,7 @@ func (p *Page) Type() string {
 // since Hugo 0.22 we support nested sections, but this will always be the first
 // element of any nested path.
 func (p *Page) Section() string {
-	if p.Kind == KindSection || p.Kind == KindTaxonomy || p.Kind == KindTaxonomyTerm {
+	if p.Kind == KindSection || p.Kind == KindTaxonomy || p.Kind == KindTaxonomyTerm || p.Kind == KindArchive || p.Kind == KindSeries {
 		return p.sections[0]
 	}
 	return p.Source.Section()
@@ -1254,6 +1277,10 @@ func (p *Page) HasMenuCurrent(menuID string, me *MenuEntry) bool {
 		return false
 	}
 
+	if me.hasDescendantPage(p) {
+		return true
+	}
+
 	menus := p.Menus()
 
 	if m, ok := menus[menuID]; ok {
@@ -1292,6 +1319,10 @@ func (p *Page) HasMenuCurrent(menuID string, me *MenuEntry) bool {
 
 func (p *Page) IsMenuCurrent(menuID string, inme *MenuEntry) bool {
 
+	if inme.Page == p {
+		return true
+	}
+
 	menus := p.Menus()
 
 	if me, ok := menus[menuID]; ok {
@@ -1353,7 +1384,7 @@ func (p *Page) Menus() PageMenus {
 		if ms, ok := p.Params["menu"]; ok {
 			link := p.RelPermalink()
 
-			me := MenuEntry{Name: p.LinkTitle(), Weight: p.Weight, URL: link}
+			me := MenuEntry{Name: p.LinkTitle(), Weight: p.Weight, URL: link, Page: p}
 
 			// Could be the name of the menu to attach it to
 			mname, err := assist.ToStringE(ms)
@@ -1383,7 +1414,7 @@ func (p *Page) Menus() PageMenus {
 			}
 
 			for name, menu := range menus {
-				menuEntry := MenuEntry{Name: p.LinkTitle(), URL: link, Weight: p.Weight, Menu: name}
+				menuEntry := MenuEntry{Name: p.LinkTitle(), URL: link, Weight: p.Weight, Menu: name, Page: p}
 				if menu != nil {
 					p.s.Log.DEBUG.Printf("found menu: %q, in %q\n", name, p.Title)
 					ime, err := assist.ToStringMapE(menu)
@@ -1434,6 +1465,10 @@ func (p *Page) parse(reader io.Reader) error {
 		return fmt.Errorf("failed to parse page metadata for %q: %s", p.File.Path(), err)
 	}
 
+	if m, ok := meta.(map[string]interface{}); ok {
+		p.checkFrontMatter(psr, m)
+	}
+
 	if meta != nil {
 		if err = p.update(meta); err != nil {
 			return err
@@ -1567,7 +1602,7 @@ func (p *Page) prepareData(s *Site) error {
 			pages = s.RegularPages
 		case KindTaxonomy:
 			plural := p.sections[0]
-			term := p.sections[1]
+			term := p.termKey()
 
 			if s.Info.preserveTaxonomyNames {
 				if v, ok := s.taxonomiesOrigKey[fmt.Sprintf("%s-%s", plural, term)]; ok {
@@ -1600,6 +1635,26 @@ func (p *Page) prepareData(s *Site) error {
 					pages = append(pages, p)
 				}
 			}
+		case KindArchive:
+			period, start := archivePeriod(p.sections)
+
+			p.Data["Section"] = p.sections[0]
+			p.Data["Period"] = period
+			p.Data["Year"] = start.Year()
+			if period == archiveMonth {
+				p.Data["Month"] = start.Month()
+			}
+
+			for _, rp := range s.RegularPages {
+				if inArchive(rp, p.sections) {
+					pages = append(pages, rp)
+				}
+			}
+		case KindSeries:
+			if sp, found := s.seriesByKey[p.sections[1]]; found {
+				p.Data["Series"] = sp.name
+				pages = sp.pages
+			}
 		}
 
 		p.Data["Pages"] = pages
@@ -1673,20 +1728,34 @@ func (p *Page) Ref(refs ...string) (string, error) {
 	if len(refs) == 0 {
 		return "", nil
 	}
+	var (
+		link string
+		err  error
+	)
 	if len(refs) > 1 {
-		return p.Site.Ref(refs[0], nil, refs[1])
+		link, err = p.Site.Ref(refs[0], nil, refs[1])
+	} else {
+		link, err = p.Site.Ref(refs[0], nil)
 	}
-	return p.Site.Ref(refs[0], nil)
+	p.recordRefError(refs[0], err)
+	return link, err
 }
 
 func (p *Page) RelRef(refs ...string) (string, error) {
 	if len(refs) == 0 {
 		return "", nil
 	}
+	var (
+		link string
+		err  error
+	)
 	if len(refs) > 1 {
-		return p.Site.RelRef(refs[0], nil, refs[1])
+		link, err = p.Site.RelRef(refs[0], nil, refs[1])
+	} else {
+		link, err = p.Site.RelRef(refs[0], nil)
 	}
-	return p.Site.RelRef(refs[0], nil)
+	p.recordRefError(refs[0], err)
+	return link, err
 }
 
 func (p *Page) String() string {
@@ -2,17 +2,24 @@ package geanlib
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/govenue/assist"
 )
 
 // PageGroup represents a group of pages, grouped by the key.
 // The key is typically a year or similar.
+// For nested groupings, see GroupByKeys, Groups holds the page groups on the
+// next level, and Pages all the pages in them.
 type PageGroup struct {
 	Key interface{}
 	Pages
+
+	Groups PagesGroup
 }
 
 type mapKeyValues []reflect.Value
@@ -70,6 +77,30 @@ var (
 	pagePtrType = reflect.TypeOf((*Page)(nil))
 )
 
+// pageGroupField returns the Page method or field with the given name, as
+// a reflect.Method or a reflect.StructField, if it can be grouped by.
+func pageGroupField(key string) (interface{}, error) {
+	m, ok := pagePtrType.MethodByName(key)
+	if ok {
+		if m.Type.NumIn() != 1 || m.Type.NumOut() == 0 || m.Type.NumOut() > 2 {
+			return nil, errors.New(key + " is a Page method but you can't use it with GroupBy")
+		}
+		if m.Type.NumOut() == 1 && m.Type.Out(0).Implements(errorType) {
+			return nil, errors.New(key + " is a Page method but you can't use it with GroupBy")
+		}
+		if m.Type.NumOut() == 2 && !m.Type.Out(1).Implements(errorType) {
+			return nil, errors.New(key + " is a Page method but you can't use it with GroupBy")
+		}
+		return m, nil
+	}
+
+	f, ok := pagePtrType.Elem().FieldByName(key)
+	if !ok {
+		return nil, errors.New(key + " is neither a field nor a method of Page")
+	}
+	return f, nil
+}
+
 // GroupBy groups by the value in the given field or method name and with the given order.
 // Valid values for order is asc, desc, rev and reverse.
 func (p Pages) GroupBy(key string, order ...string) (PagesGroup, error) {
@@ -83,24 +114,9 @@ func (p Pages) GroupBy(key string, order ...string) (PagesGroup, error) {
 		direction = "desc"
 	}
 
-	var ft interface{}
-	m, ok := pagePtrType.MethodByName(key)
-	if ok {
-		if m.Type.NumIn() != 1 || m.Type.NumOut() == 0 || m.Type.NumOut() > 2 {
-			return nil, errors.New(key + " is a Page method but you can't use it with GroupBy")
-		}
-		if m.Type.NumOut() == 1 && m.Type.Out(0).Implements(errorType) {
-			return nil, errors.New(key + " is a Page method but you can't use it with GroupBy")
-		}
-		if m.Type.NumOut() == 2 && !m.Type.Out(1).Implements(errorType) {
-			return nil, errors.New(key + " is a Page method but you can't use it with GroupBy")
-		}
-		ft = m
-	} else {
-		ft, ok = pagePtrType.Elem().FieldByName(key)
-		if !ok {
-			return nil, errors.New(key + " is neither a field nor a method of Page")
-		}
+	ft, err := pageGroupField(key)
+	if err != nil {
+		return nil, err
 	}
 
 	var tmp reflect.Value
@@ -283,3 +299,284 @@ func (p Pages) GroupByParamDate(key string, format string, order ...string) (Pag
 	}
 	return p.groupByDateField(sorter, formatter, order...)
 }
+
+// pageGroupKey is one level in a GroupByKeys grouping.
+type pageGroupKey struct {
+	spec string
+	desc bool
+
+	// values returns the page's keys on this level, and the values to sort
+	// the groups by, e.g. the dates for keys formatted from dates.
+	values func(p *Page) (keys []interface{}, sortValues []interface{}, err error)
+}
+
+// newPageGroupKey creates a grouping level from a key spec, see GroupByKeys.
+func newPageGroupKey(spec string) (*pageGroupKey, error) {
+	k := &pageGroupKey{spec: spec}
+
+	key := strings.TrimSpace(spec)
+	order := ""
+	if i := strings.LastIndex(key, " "); i != -1 {
+		switch strings.ToLower(key[i+1:]) {
+		case "asc", "desc", "rev", "reverse":
+			order = strings.ToLower(key[i+1:])
+			key = strings.TrimSpace(key[:i])
+		}
+	}
+
+	format := ""
+	if i := strings.Index(key, ":"); i != -1 {
+		key, format = key[:i], key[i+1:]
+		// Dates are by default grouped with the newest first, as in GroupByDate.
+		k.desc = true
+	}
+
+	if order != "" {
+		k.desc = order != "asc"
+	}
+
+	if key == "" {
+		return nil, fmt.Errorf("invalid group key %q", spec)
+	}
+
+	var values func(p *Page) ([]interface{}, error)
+
+	lkey := strings.ToLower(key)
+	switch {
+	case strings.HasPrefix(lkey, "params."):
+		param := key[len("params."):]
+		values = func(p *Page) ([]interface{}, error) {
+			return pageGroupValues(p.getParam(param, false)), nil
+		}
+	case strings.HasPrefix(lkey, "taxonomies."):
+		plural := strings.ToLower(key[len("taxonomies."):])
+		values = func(p *Page) ([]interface{}, error) {
+			terms := assist.ToStringSlice(p.getParam(plural, !p.s.Info.preserveTaxonomyNames))
+			var keys []interface{}
+			for _, term := range terms {
+				keys = append(keys, p.s.getTaxonomyKey(term))
+			}
+			return keys, nil
+		}
+	default:
+		ft, err := pageGroupField(key)
+		if err != nil {
+			return nil, err
+		}
+		values = func(p *Page) ([]interface{}, error) {
+			ppv := reflect.ValueOf(p)
+			var fv reflect.Value
+			switch ft.(type) {
+			case reflect.StructField:
+				fv = ppv.Elem().FieldByName(key)
+			case reflect.Method:
+				out := ppv.MethodByName(key).Call([]reflect.Value{})
+				if len(out) == 2 && !out[1].IsNil() {
+					return nil, out[1].Interface().(error)
+				}
+				fv = out[0]
+			}
+			if !fv.IsValid() {
+				return nil, nil
+			}
+			return pageGroupValues(fv.Interface()), nil
+		}
+	}
+
+	k.values = func(p *Page) ([]interface{}, []interface{}, error) {
+		keys, err := values(p)
+		if err != nil || format == "" {
+			return keys, keys, err
+		}
+
+		var dates, sortValues []interface{}
+		for _, v := range keys {
+			if t, ok := v.(time.Time); ok {
+				dates = append(dates, t.Format(format))
+				sortValues = append(sortValues, t)
+			}
+		}
+		return dates, sortValues, nil
+	}
+
+	return k, nil
+}
+
+// pageGroupValues returns the group keys for the given value, one per element
+// for slices.
+func pageGroupValues(v interface{}) []interface{} {
+	if v == nil {
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return []interface{}{v}
+	}
+
+	var values []interface{}
+	for i := 0; i < rv.Len(); i++ {
+		values = append(values, rv.Index(i).Interface())
+	}
+	return values
+}
+
+// comparePageGroupKeys compares the group sort values a and b, and returns
+// -1, 0 or 1. Numbers are compared by value, whatever their type.
+func comparePageGroupKeys(a, b interface{}) int {
+	if fa, err := assist.ToFloat64E(a); err == nil {
+		if fb, err := assist.ToFloat64E(b); err == nil {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			}
+			return 0
+		}
+	}
+
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			switch {
+			case ta.Before(tb):
+				return -1
+			case ta.After(tb):
+				return 1
+			}
+			return 0
+		}
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// pageGroupSorter sorts page groups by their sort values.
+type pageGroupSorter struct {
+	groups     PagesGroup
+	sortValues []interface{}
+	desc       bool
+}
+
+func (s pageGroupSorter) Len() int { return len(s.groups) }
+
+func (s pageGroupSorter) Swap(i, j int) {
+	s.groups[i], s.groups[j] = s.groups[j], s.groups[i]
+	s.sortValues[i], s.sortValues[j] = s.sortValues[j], s.sortValues[i]
+}
+
+func (s pageGroupSorter) Less(i, j int) bool {
+	c := comparePageGroupKeys(s.sortValues[i], s.sortValues[j])
+	if s.desc {
+		return c > 0
+	}
+	return c < 0
+}
+
+// GroupByKeys groups by a chain of keys, e.g. by season, then by guest,
+// and returns a tree of page groups, see PageGroup's Groups. The pages keep
+// their order within the groups.
+//
+// A key is a Page field or method name, as in GroupBy, a page param,
+// e.g. "Params.season", or a taxonomy, e.g. "Taxonomies.guests". Date values
+// are grouped in the format given after a colon, e.g. "Date:2006" or
+// "Params.recorded:January 2006", and ordered by date. A page with several
+// values, e.g. several guests, is placed in the group for each of them. Pages
+// without a value for a key are left out.
+//
+// A key may be followed by its level's order: asc, desc, rev or reverse.
+// The default is asc, or desc for dates. For example:
+//
+//	{{ range .Pages.GroupByKeys "Date:2006" "Date:January desc" }}
+func (p Pages) GroupByKeys(keys ...string) (PagesGroup, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("GroupByKeys needs at least one key")
+	}
+
+	levels := make([]*pageGroupKey, len(keys))
+	for i, spec := range keys {
+		k, err := newPageGroupKey(spec)
+		if err != nil {
+			return nil, err
+		}
+		levels[i] = k
+	}
+
+	return p.groupByKeys(levels)
+}
+
+func (p Pages) groupByKeys(levels []*pageGroupKey) (PagesGroup, error) {
+	if len(p) < 1 {
+		return nil, nil
+	}
+
+	var (
+		level      = levels[0]
+		r          PagesGroup
+		sortValues []interface{}
+		index      = make(map[interface{}]int)
+	)
+
+	for _, e := range p {
+		keys, svs, err := level.values(e)
+		if err != nil {
+			return nil, fmt.Errorf("failed to group by %q: %s", level.spec, err)
+		}
+
+		// Once per group.
+		added := make(map[interface{}]bool)
+
+		for i, key := range keys {
+			if key == nil || !reflect.TypeOf(key).Comparable() || added[key] {
+				continue
+			}
+			added[key] = true
+
+			gi, found := index[key]
+			if !found {
+				gi = len(r)
+				index[key] = gi
+				r = append(r, PageGroup{Key: key})
+				sortValues = append(sortValues, svs[i])
+			} else if comparePageGroupKeys(svs[i], sortValues[gi]) < 0 {
+				sortValues[gi] = svs[i]
+			}
+			r[gi].Pages = append(r[gi].Pages, e)
+		}
+	}
+
+	sort.Stable(pageGroupSorter{groups: r, sortValues: sortValues, desc: level.desc})
+
+	if len(levels) == 1 {
+		return r, nil
+	}
+
+	var nested PagesGroup
+	for _, g := range r {
+		groups, err := g.Pages.groupByKeys(levels[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(groups) == 0 {
+			continue
+		}
+
+		// Leave out the pages without a value on the next levels.
+		grouped := make(map[*Page]bool)
+		for _, gg := range groups {
+			for _, e := range gg.Pages {
+				grouped[e] = true
+			}
+		}
+		var pages Pages
+		for _, e := range g.Pages {
+			if grouped[e] {
+				pages = append(pages, e)
+			}
+		}
+
+		nested = append(nested, PageGroup{Key: g.Key, Pages: pages, Groups: groups})
+	}
+
+	return nested, nil
+}
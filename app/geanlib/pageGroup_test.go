@@ -442,3 +442,104 @@ func TestGroupByParamDateWithEmptyPages(t *testing.T) {
 		t.Errorf("PagesGroup isn't empty. It should be %#v, got %#v", nil, groups)
 	}
 }
+
+func TestGroupByKeys(t *testing.T) {
+	t.Parallel()
+	pages := preparePageGroupTestPages(t)
+	expect := PagesGroup{
+		{Key: "section1", Pages: Pages{pages[0], pages[1], pages[2]}, Groups: PagesGroup{
+			{Key: "foo", Pages: Pages{pages[0], pages[2]}},
+			{Key: "bar", Pages: Pages{pages[1]}},
+		}},
+		{Key: "section2", Pages: Pages{pages[3], pages[4]}, Groups: PagesGroup{
+			{Key: "baz", Pages: Pages{pages[4]}},
+			{Key: "bar", Pages: Pages{pages[3]}},
+		}},
+	}
+
+	groups, err := pages.GroupByKeys("Section", "Params.custom_param desc")
+	if err != nil {
+		t.Fatalf("Unable to make PagesGroup array: %s", err)
+	}
+	if !reflect.DeepEqual(groups, expect) {
+		t.Errorf("PagesGroup has unexpected groups. It should be %#v, got %#v", expect, groups)
+	}
+}
+
+func TestGroupByKeysWithDates(t *testing.T) {
+	t.Parallel()
+	pages := preparePageGroupTestPages(t)
+	expect := PagesGroup{
+		{Key: "2012", Pages: Pages{pages[0], pages[1], pages[2], pages[3], pages[4]}, Groups: PagesGroup{
+			{Key: "January", Pages: Pages{pages[1]}},
+			{Key: "March", Pages: Pages{pages[3]}},
+			{Key: "April", Pages: Pages{pages[0], pages[2], pages[4]}},
+		}},
+	}
+
+	groups, err := pages.GroupByKeys("Date:2006", "Params.custom_date:January asc")
+	if err != nil {
+		t.Fatalf("Unable to make PagesGroup array: %s", err)
+	}
+	if !reflect.DeepEqual(groups, expect) {
+		t.Errorf("PagesGroup has unexpected groups. It should be %#v, got %#v", expect, groups)
+	}
+}
+
+func TestGroupByKeysWithSeveralValues(t *testing.T) {
+	t.Parallel()
+	pages := preparePageGroupTestPages(t)
+	pages[0].Params["guests"] = []string{"Bob", "Alice"}
+	pages[1].Params["guests"] = []string{"Bob"}
+	pages[3].Params["guests"] = "Carol"
+	pages[0].Params["tags"] = []string{"Go Lang", "Web"}
+	pages[4].Params["tags"] = []string{"web"}
+
+	expect := PagesGroup{
+		{Key: "section1", Pages: Pages{pages[0], pages[1]}, Groups: PagesGroup{
+			{Key: "Alice", Pages: Pages{pages[0]}},
+			{Key: "Bob", Pages: Pages{pages[0], pages[1]}},
+		}},
+		{Key: "section2", Pages: Pages{pages[3]}, Groups: PagesGroup{
+			{Key: "Carol", Pages: Pages{pages[3]}},
+		}},
+	}
+
+	groups, err := pages.GroupByKeys("Section", "Params.guests")
+	if err != nil {
+		t.Fatalf("Unable to make PagesGroup array: %s", err)
+	}
+	if !reflect.DeepEqual(groups, expect) {
+		t.Errorf("PagesGroup has unexpected groups. It should be %#v, got %#v", expect, groups)
+	}
+
+	expect = PagesGroup{
+		{Key: "go-lang", Pages: Pages{pages[0]}},
+		{Key: "web", Pages: Pages{pages[0], pages[4]}},
+	}
+
+	groups, err = pages.GroupByKeys("Taxonomies.tags")
+	if err != nil {
+		t.Fatalf("Unable to make PagesGroup array: %s", err)
+	}
+	if !reflect.DeepEqual(groups, expect) {
+		t.Errorf("PagesGroup has unexpected groups. It should be %#v, got %#v", expect, groups)
+	}
+}
+
+func TestGroupByKeysCalledWithInvalidKeys(t *testing.T) {
+	t.Parallel()
+	pages := preparePageGroupTestPages(t)
+
+	for _, keys := range [][]string{
+		{},
+		{"Section", "UnavailableKey"},
+		{":2006"},
+		{"DummyPageMethodWithArgForTest"},
+	} {
+		_, err := pages.GroupByKeys(keys...)
+		if err == nil {
+			t.Errorf("GroupByKeys should return an error for %v but it returned nil", keys)
+		}
+	}
+}
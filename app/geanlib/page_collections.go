@@ -3,6 +3,7 @@ package geanlib
 import (
 	"path"
 	"path/filepath"
+	"sync"
 
 	"github.com/geego/gean/app/cache"
 )
@@ -31,6 +32,10 @@ type PageCollections struct {
 	rawAllPages Pages
 
 	pageCache *cache.PartitionedLazyCache
+
+	// The pages by path, for GetPage. Built on first use.
+	pathIndex     *pagePathIndex
+	pathIndexInit sync.Once
 }
 
 func (c *PageCollections) refreshPageCaches() {
@@ -70,6 +75,9 @@ func (c *PageCollections) refreshPageCaches() {
 	}
 
 	c.pageCache = cache.NewPartitionedLazyCache(partitions...)
+
+	c.pathIndex = nil
+	c.pathIndexInit = sync.Once{}
 }
 
 func newPageCollections() *PageCollections {
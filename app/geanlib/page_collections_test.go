@@ -125,3 +125,85 @@ func TestGetPage(t *testing.T) {
 	}
 
 }
+
+func TestGetPageByPath(t *testing.T) {
+	t.Parallel()
+
+	var (
+		assert  = require.New(t)
+		cfg, fs = newTestCfg()
+	)
+
+	writeSource(t, fs, filepath.Join("content", "episodes", "_index.md"), fmt.Sprintf(pageCollectionsPageTemplate, "Episodes"))
+	writeSource(t, fs, filepath.Join("content", "episodes", "intro.md"), fmt.Sprintf(pageCollectionsPageTemplate, "Intro"))
+	writeSource(t, fs, filepath.Join("content", "episodes", "season-2", "ep-10.md"), fmt.Sprintf(pageCollectionsPageTemplate, "Ep10"))
+	writeSource(t, fs, filepath.Join("content", "episodes", "season-2", "Ep-11.md"), fmt.Sprintf(pageCollectionsPageTemplate, "Ep11"))
+	writeSource(t, fs, filepath.Join("content", "dups", "dup.md"), fmt.Sprintf(pageCollectionsPageTemplate, "Dup1"))
+	writeSource(t, fs, filepath.Join("content", "dups", "Dup.md"), fmt.Sprintf(pageCollectionsPageTemplate, "Dup2"))
+
+	s := buildSingleSite(t, deps.DepsCfg{Fs: fs, Cfg: cfg}, BuildCfg{SkipRender: true})
+
+	tests := []struct {
+		ref           string
+		expectedTitle string
+	}{
+		{"/", ""},
+		{"/episodes", "Episodes"},
+		{"/episodes/", "Episodes"},
+		{"/episodes/_index.md", "Episodes"},
+		{"/episodes/season-2/ep-10", "Ep10"},
+		{"episodes/season-2/ep-10.md", "Ep10"},
+		{"/Episodes/Season-2/EP-10", "Ep10"},
+		{"/episodes/season-2/ep-11", "Ep11"},
+	}
+
+	for i, test := range tests {
+		errorMsg := fmt.Sprintf("Test %d", i)
+		page, err := s.Info.GetPage(test.ref)
+		assert.NoError(err, errorMsg)
+		assert.NotNil(page, errorMsg)
+		assert.Equal(test.expectedTitle, page.Title, errorMsg)
+	}
+
+	page, err := s.Info.GetPage("/episodes/season-2")
+	assert.NoError(err)
+	assert.Equal(KindSection, page.Kind)
+	assert.Equal([]string{"episodes", "season-2"}, page.sections)
+
+	page, err = s.Info.GetPage("/episodes/missing")
+	assert.NoError(err)
+	assert.Nil(page)
+
+	_, err = s.Info.GetPage("/dups/dup")
+	assert.Error(err)
+	assert.Contains(err.Error(), "ambiguous")
+
+	page, err = s.Info.GetPage("/episodes", "intro")
+	assert.NoError(err)
+	assert.Nil(page)
+
+	page, err = s.Info.GetPage("nosuchkind", "episodes")
+	assert.NoError(err)
+	assert.Nil(page)
+
+	// Relative to the page.
+	ep10, _ := s.Info.GetPage("/episodes/season-2/ep-10")
+	page, err = ep10.GetPage("../intro")
+	assert.NoError(err)
+	assert.Equal("Intro", page.Title)
+	page, err = ep10.GetPage("ep-11")
+	assert.NoError(err)
+	assert.Equal("Ep11", page.Title)
+
+	// Relative to the section, else from the root.
+	episodes, _ := s.Info.GetPage("/episodes")
+	page, err = episodes.GetPage("season-2/ep-10")
+	assert.NoError(err)
+	assert.Equal("Ep10", page.Title)
+	page, err = episodes.GetPage("intro")
+	assert.NoError(err)
+	assert.Equal("Intro", page.Title)
+	page, err = ep10.GetPage("episodes/intro")
+	assert.NoError(err)
+	assert.Equal("Intro", page.Title)
+}
@@ -0,0 +1,127 @@
+package geanlib
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// pagePathIndex indexes the pages of a site by their path, to look them up
+// by reference in GetPage. The keys are lower case and rooted at the content
+// dir, e.g. "/episodes/season-2/ep-10" for both the content file
+// episodes/season-2/ep-10.md and its translation ep-10.fr.md in the French
+// site, and "/episodes" for the episodes section.
+type pagePathIndex struct {
+	pages map[string]Pages
+}
+
+// newPagePathIndex creates the index of the pages in the given language.
+// The pages in the other languages are only indexed by their file name, i.e.
+// with the language code, e.g. "/episodes/season-2/ep-10.fr".
+func newPagePathIndex(lang string, pages Pages) *pagePathIndex {
+	idx := &pagePathIndex{pages: make(map[string]Pages)}
+
+	for _, p := range pages {
+		if p.Lang() != lang {
+			if p.Source.Path() != "" && p.Source.BaseFileName() != p.Source.TranslationBaseName() {
+				dir := filepath.ToSlash(p.Source.Dir())
+				idx.add(path.Join(dir, p.Source.BaseFileName()), p)
+				idx.add(filepath.ToSlash(p.Source.Path()), p)
+			}
+			continue
+		}
+
+		if p.Kind != KindPage {
+			idx.add(path.Join(p.sections...), p)
+		}
+
+		if p.Source.Path() != "" {
+			dir := filepath.ToSlash(p.Source.Dir())
+			idx.add(path.Join(dir, p.Source.TranslationBaseName()), p)
+			idx.add(path.Join(dir, p.Source.BaseFileName()), p)
+			idx.add(filepath.ToSlash(p.Source.Path()), p)
+		}
+	}
+
+	return idx
+}
+
+func normalizePagePath(ref string) string {
+	return path.Clean("/" + strings.ToLower(ref))
+}
+
+func (idx *pagePathIndex) add(key string, p *Page) {
+	key = normalizePagePath(key)
+	for _, pp := range idx.pages[key] {
+		if pp == p {
+			return
+		}
+	}
+	idx.pages[key] = append(idx.pages[key], p)
+}
+
+// get returns the page with the given path, nil if not found. It fails if
+// the path matches more than one page.
+func (idx *pagePathIndex) get(ref string) (*Page, error) {
+	pages := idx.pages[normalizePagePath(ref)]
+
+	switch len(pages) {
+	case 0:
+		return nil, nil
+	case 1:
+		return pages[0], nil
+	}
+
+	var matches []string
+	for _, p := range pages {
+		matches = append(matches, p.pathDescription())
+	}
+
+	return nil, fmt.Errorf("page reference %q is ambiguous, it matches %s", ref, strings.Join(matches, ", "))
+}
+
+// pathDescription describes the page by its content file, or else by its
+// kind and path.
+func (p *Page) pathDescription() string {
+	if p.Source.Path() != "" {
+		return fmt.Sprintf("%q", filepath.ToSlash(p.Source.Path()))
+	}
+	return fmt.Sprintf("the %s %q", p.Kind, "/"+path.Join(p.sections...))
+}
+
+// getPageByPath looks up a page by its path from the content root, see
+// pagePathIndex. The index is built on first use.
+func (c *PageCollections) getPageByPath(lang, ref string) (*Page, error) {
+	c.pathIndexInit.Do(func() {
+		c.pathIndex = newPagePathIndex(lang, c.AllPages)
+	})
+	return c.pathIndex.get(ref)
+}
+
+// GetPage looks up a page by its path, relative to this page unless it
+// starts with a "/", e.g.:
+//
+//	{{ with .GetPage "../intro" }}{{ .Title }}{{ end }}
+//
+// A path not found relative to this page is looked up from the content
+// root. See SiteInfo's GetPage.
+func (p *Page) GetPage(ref string) (*Page, error) {
+	if !strings.HasPrefix(ref, "/") {
+		found, err := p.s.getPageByPath(p.s.Language.Lang, path.Join(p.pathDir(), ref))
+		if found != nil || err != nil {
+			return found, err
+		}
+	}
+
+	return p.s.getPageByPath(p.s.Language.Lang, ref)
+}
+
+// pathDir is the dir relative paths are resolved from, the page's content
+// dir, or the section itself for sections etc.
+func (p *Page) pathDir() string {
+	if p.Kind != KindPage {
+		return "/" + path.Join(p.sections...)
+	}
+	return "/" + filepath.ToSlash(p.Source.Dir())
+}
@@ -130,12 +130,28 @@ type relatedDocsHandler struct {
 	// This is configured in site or langugage config.
 	cfg related.Config
 
+	// The terms of the page content, kept between builds.
+	contentCache *related.ContentCache
+
 	postingLists []*cachedPostingList
 	mu           sync.RWMutex
 }
 
-func newSearchIndexHandler(cfg related.Config) *relatedDocsHandler {
-	return &relatedDocsHandler{cfg: cfg}
+func newSearchIndexHandler(cfg related.Config, contentCache *related.ContentCache) *relatedDocsHandler {
+	return &relatedDocsHandler{cfg: cfg, contentCache: contentCache}
+}
+
+// reset returns a new handler for a rebuild, keeping the terms of the
+// content not changed since the previous build.
+func (s *relatedDocsHandler) reset() *relatedDocsHandler {
+	s.contentCache.Prune()
+	return &relatedDocsHandler{cfg: s.cfg, contentCache: s.contentCache}
+}
+
+// saveContentCache stores the terms of the content indexed in this build,
+// if the cache is kept in a file.
+func (s *relatedDocsHandler) saveContentCache() error {
+	return s.contentCache.Save()
 }
 
 // This assumes that a lock has been acquired.
@@ -165,6 +181,7 @@ func (s *relatedDocsHandler) getOrCreateIndex(p Pages) (*related.InvertedIndex,
 	}
 
 	searchIndex := related.NewInvertedIndex(s.cfg)
+	searchIndex.SetContentCache(s.contentCache)
 
 	for _, page := range p {
 		if err := searchIndex.Add(page); err != nil {
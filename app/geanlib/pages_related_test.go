@@ -59,3 +59,48 @@ Content
 	assert.Equal("Page 2", result[0].Title)
 	assert.Equal("Page 3", result[1].Title)
 }
+
+func TestRelatedContent(t *testing.T) {
+	assert := require.New(t)
+
+	t.Parallel()
+
+	var (
+		cfg, fs = newTestCfg()
+	)
+
+	cfg.Set("related", map[string]interface{}{
+		"threshold":    10,
+		"includeNewer": true,
+		"indices": []map[string]interface{}{
+			{"name": "content", "type": "content", "weight": 100},
+		},
+	})
+
+	pageTmpl := `---
+title: %s
+---
+
+%s
+`
+
+	writeSource(t, fs, filepath.Join("content", "page1.md"), fmt.Sprintf(pageTmpl, "Tomato Gardens", "Growing tomatoes in a small garden."))
+	writeSource(t, fs, filepath.Join("content", "page2.md"), fmt.Sprintf(pageTmpl, "Peppers", "Growing peppers and tomatoes in the greenhouse."))
+	writeSource(t, fs, filepath.Join("content", "page3.md"), fmt.Sprintf(pageTmpl, "Compilers", "Cross compiling with the Go compiler."))
+
+	s := buildSingleSite(t, deps.DepsCfg{Fs: fs, Cfg: cfg}, BuildCfg{SkipRender: true})
+	assert.Len(s.RegularPages, 3)
+
+	page1 := s.getPage(KindPage, "page1.md")
+	assert.NotNil(page1)
+
+	result, err := s.RegularPages.Related(page1)
+	assert.NoError(err)
+	assert.Len(result, 1)
+	assert.Equal("Peppers", result[0].Title)
+
+	result, err = s.RegularPages.RelatedTo(types.NewKeyValuesStrings("content", "go compiler"))
+	assert.NoError(err)
+	assert.Len(result, 1)
+	assert.Equal("Compilers", result[0].Title)
+}
@@ -33,10 +33,16 @@ func (p Pages) Len() int {
 }
 
 // Len returns the number of pages in the page group.
+// For nested groups, this is the number of pages in the innermost groups,
+// where a page may be in more than one group.
 func (psg PagesGroup) Len() int {
 	l := 0
 	for _, pg := range psg {
-		l += len(pg.Pages)
+		if len(pg.Groups) > 0 {
+			l += pg.Groups.Len()
+		} else {
+			l += len(pg.Pages)
+		}
 	}
 	return l
 }
@@ -120,14 +126,9 @@ func (p *Pager) page(index int) (*Page, error) {
 	// ... it is the difference between 99.5% and 100% test coverage :-)
 	groups := p.element().(PagesGroup)
 
-	i := 0
-	for _, v := range groups {
-		for _, page := range v.Pages {
-			if i == index {
-				return page, nil
-			}
-			i++
-		}
+	flattened := flattenPageGroups(groups, nil, nil)
+	if index < len(flattened) {
+		return flattened[index].page, nil
 	}
 	return nil, nil
 }
@@ -203,43 +204,68 @@ func splitPages(pages Pages, size int) []paginatedElement {
 	return split
 }
 
-func splitPageGroups(pageGroups PagesGroup, size int) []paginatedElement {
+// groupedPage is a page in the innermost group with the given keys, from the
+// outermost group to the innermost.
+type groupedPage struct {
+	keys []interface{}
+	page *Page
+}
 
-	type keyPage struct {
-		key  interface{}
-		page *Page
+func flattenPageGroups(pageGroups PagesGroup, keys []interface{}, flattened []groupedPage) []groupedPage {
+	for _, g := range pageGroups {
+		gkeys := append(keys[:len(keys):len(keys)], g.Key)
+		if len(g.Groups) > 0 {
+			flattened = flattenPageGroups(g.Groups, gkeys, flattened)
+			continue
+		}
+		for _, p := range g.Pages {
+			flattened = append(flattened, groupedPage{gkeys, p})
+		}
 	}
+	return flattened
+}
 
-	var (
-		split     []paginatedElement
-		flattened []keyPage
-	)
+// addGroupedPage adds the page to the last group in pg if it has the same key,
+// or to a new group, and so on down the nested groups.
+func addGroupedPage(pg PagesGroup, keys []interface{}, page *Page) PagesGroup {
+	key := keys[0]
+	last := len(pg) - 1
+	if last == -1 || key == nil || pg[last].Key != key {
+		pg = append(pg, PageGroup{Key: key})
+		last++
+	}
 
-	for _, g := range pageGroups {
+	g := &pg[last]
+	if len(keys) > 1 {
+		g.Groups = addGroupedPage(g.Groups, keys[1:], page)
 		for _, p := range g.Pages {
-			flattened = append(flattened, keyPage{g.Key, p})
+			if p == page {
+				// Already added to another group on the next level.
+				return pg
+			}
 		}
 	}
+	g.Pages = append(g.Pages, page)
+
+	return pg
+}
+
+func splitPageGroups(pageGroups PagesGroup, size int) []paginatedElement {
+	var (
+		split     []paginatedElement
+		flattened = flattenPageGroups(pageGroups, nil, nil)
+	)
 
 	numPages := len(flattened)
 
 	for low, j := 0, numPages; low < j; low += size {
 		high := int(math.Min(float64(low+size), float64(numPages)))
 
-		var (
-			pg         PagesGroup
-			key        interface{}
-			groupIndex = -1
-		)
+		var pg PagesGroup
 
 		for k := low; k < high; k++ {
 			kp := flattened[k]
-			if key == nil || key != kp.key {
-				key = kp.key
-				pg = append(pg, PageGroup{Key: key})
-				groupIndex++
-			}
-			pg[groupIndex].Pages = append(pg[groupIndex].Pages, kp.page)
+			pg = addGroupedPage(pg, kp.keys, kp.page)
 		}
 		split = append(split, pg)
 	}
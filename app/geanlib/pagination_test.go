@@ -70,6 +70,37 @@ func TestSplitPageGroups(t *testing.T) {
 
 }
 
+func TestSplitNestedPageGroups(t *testing.T) {
+	t.Parallel()
+	pages := preparePageGroupTestPages(t)
+	pages[0].Params["guests"] = []string{"Bob", "Alice"}
+	pages[1].Params["guests"] = []string{"Bob"}
+	pages[3].Params["guests"] = "Carol"
+
+	groups, err := pages.GroupByKeys("Section", "Params.guests")
+	require.NoError(t, err)
+	require.Equal(t, 4, groups.Len())
+
+	chunks := splitPageGroups(groups, 2)
+	require.Equal(t, 2, len(chunks))
+
+	require.Equal(t, PagesGroup{
+		{Key: "section1", Pages: Pages{pages[0]}, Groups: PagesGroup{
+			{Key: "Alice", Pages: Pages{pages[0]}},
+			{Key: "Bob", Pages: Pages{pages[0]}},
+		}},
+	}, chunks[0])
+
+	require.Equal(t, PagesGroup{
+		{Key: "section1", Pages: Pages{pages[1]}, Groups: PagesGroup{
+			{Key: "Bob", Pages: Pages{pages[1]}},
+		}},
+		{Key: "section2", Pages: Pages{pages[3]}, Groups: PagesGroup{
+			{Key: "Carol", Pages: Pages{pages[3]}},
+		}},
+	}, chunks[1])
+}
+
 func TestPager(t *testing.T) {
 	t.Parallel()
 	s := newTestSite(t)
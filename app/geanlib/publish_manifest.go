@@ -0,0 +1,159 @@
+package geanlib
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/geego/gean/app/helpers"
+	"github.com/govenue/fsintra"
+)
+
+// ManifestFilename is the name of the build manifest stored in publishDir.
+// It lists every file written by the last build, relative to publishDir,
+// and is used to remove stale output on the next build.
+const ManifestFilename = ".gean_manifest.json"
+
+// Manifest holds the files written by a build.
+type Manifest struct {
+	// Slash separated paths relative to publishDir, sorted.
+	Files []string `json:"files"`
+}
+
+// publishedFiles records the files written during a full build, with a
+// description of every distinct source that wrote them.
+type publishedFiles struct {
+	mu    sync.Mutex
+	files map[string][]string
+}
+
+func newPublishedFiles() *publishedFiles {
+	return &publishedFiles{files: make(map[string][]string)}
+}
+
+func (p *publishedFiles) add(filename, source string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, s := range p.files[filename] {
+		if s == source {
+			return
+		}
+	}
+	p.files[filename] = append(p.files[filename], source)
+}
+
+// PublishedFiles returns the absolute filenames written to the destination
+// filesystem by the last full build, sorted. This includes pages, aliases,
+// feeds, sitemaps and other files rendered by the sites, but not the files
+// copied from the static dirs.
+func (h *HugoSites) PublishedFiles() []string {
+	if h.published == nil {
+		return nil
+	}
+
+	h.published.mu.Lock()
+	defer h.published.mu.Unlock()
+
+	files := make([]string, 0, len(h.published.files))
+	for filename := range h.published.files {
+		files = append(files, filename)
+	}
+	sort.Strings(files)
+
+	return files
+}
+
+// ReadManifest reads the manifest in publishDir. It returns an empty
+// manifest if none is found.
+func ReadManifest(fs fsintra.Fs, publishDir string) (Manifest, error) {
+	var m Manifest
+
+	b, err := fsintra.ReadFile(fs, filepath.Join(publishDir, ManifestFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return m, err
+	}
+
+	err = json.Unmarshal(b, &m)
+
+	return m, err
+}
+
+// CleanStaleOutput removes the files listed in the manifest in publishDir
+// that are not in published, the absolute filenames written by the current
+// build, and writes a new manifest. Directories left empty are removed.
+// It returns the removed files as slash separated paths relative to
+// publishDir.
+func CleanStaleOutput(fs fsintra.Fs, publishDir string, published []string) ([]string, error) {
+	publishDir = filepath.Clean(publishDir)
+
+	old, err := ReadManifest(fs, publishDir)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]bool)
+	for _, filename := range published {
+		rel, err := filepath.Rel(publishDir, filename)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		current[filepath.ToSlash(rel)] = true
+	}
+
+	var removed []string
+
+	for _, rel := range old.Files {
+		if current[rel] || strings.HasPrefix(rel, "../") || rel == ManifestFilename {
+			continue
+		}
+
+		filename := filepath.Join(publishDir, filepath.FromSlash(rel))
+		if err := fs.Remove(filename); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return removed, err
+		}
+		removed = append(removed, rel)
+
+		if err := removeEmptyDirs(fs, publishDir, filepath.Dir(filename)); err != nil {
+			return removed, err
+		}
+	}
+
+	m := Manifest{Files: make([]string, 0, len(current))}
+	for rel := range current {
+		m.Files = append(m.Files, rel)
+	}
+	sort.Strings(m.Files)
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return removed, err
+	}
+
+	return removed, helpers.WriteToDisk(filepath.Join(publishDir, ManifestFilename), bytes.NewReader(b), fs)
+}
+
+// removeEmptyDirs removes dir and its parents below root as long as they
+// are empty.
+func removeEmptyDirs(fs fsintra.Fs, root, dir string) error {
+	for dir != root && strings.HasPrefix(dir, root) {
+		empty, err := fsintra.IsEmpty(fs, dir)
+		if err != nil || !empty {
+			return err
+		}
+		if err := fs.Remove(dir); err != nil {
+			return err
+		}
+		dir = filepath.Dir(dir)
+	}
+	return nil
+}
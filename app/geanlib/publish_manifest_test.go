@@ -0,0 +1,66 @@
+package geanlib
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/geego/gean/app/deps"
+	"github.com/govenue/fsintra"
+	"github.com/govenue/require"
+)
+
+func TestCleanStaleOutput(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	siteConfig := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "robotsTXT", "404"]
+`
+
+	mf := fsintra.NewMemMapFs()
+	writeToFs(t, mf, "content/sect/p1.md", "---\ntitle: P1\n---\nContent")
+	writeToFs(t, mf, "content/sect/p2.md", "---\ntitle: P2\naliases: [/old/p2/]\n---\nContent")
+
+	th, h := newTestSitesFromConfig(t, mf, siteConfig,
+		"layouts/_default/single.html", "Single|{{ .Title }}",
+		"layouts/_default/list.html", "List|{{ .Title }}",
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	published := h.PublishedFiles()
+	assert.Contains(published, filepath.FromSlash("public/sect/p2/index.html"))
+	assert.Contains(published, filepath.FromSlash("public/old/p2/index.html"))
+	assert.Contains(published, filepath.FromSlash("public/sitemap.xml"))
+
+	// A file not written by the build.
+	writeToFs(t, th.Fs.Destination, "public/custom.txt", "custom")
+
+	removed, err := CleanStaleOutput(th.Fs.Destination, "public", published)
+	assert.NoError(err)
+	assert.Len(removed, 0)
+
+	m, err := ReadManifest(th.Fs.Destination, "public")
+	assert.NoError(err)
+	assert.Contains(m.Files, "sect/p2/index.html")
+	assert.NotContains(m.Files, "custom.txt")
+
+	// Remove P2 and rebuild.
+	assert.NoError(mf.Remove(filepath.FromSlash("content/sect/p2.md")))
+
+	h, err = NewHugoSites(deps.DepsCfg{Fs: th.Fs, Cfg: th.Cfg})
+	assert.NoError(err)
+	assert.NoError(h.Build(BuildCfg{}))
+
+	removed, err = CleanStaleOutput(th.Fs.Destination, "public", h.PublishedFiles())
+	assert.NoError(err)
+	assert.Equal([]string{"old/p2/index.html", "sect/p2/index.html"}, removed)
+
+	th.assertFileNotExist("public/sect/p2/index.html")
+	th.assertFileNotExist("public/sect/p2")
+	th.assertFileNotExist("public/old")
+	th.assertFileContent("public/sect/p1/index.html", "Single|P1")
+	th.assertFileContent("public/custom.txt", "custom")
+}
@@ -0,0 +1,115 @@
+package geanlib
+
+import (
+	"bytes"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/govenue/fsintra"
+)
+
+// RefError is a failed ref or relref lookup.
+type RefError struct {
+	// The page doing the lookup.
+	Page *Page
+
+	// The ref looked up.
+	Ref string
+
+	// The content file of Page, relative to the content dir, and the
+	// 1-based line the ref is found on. Line is 0 if the ref isn't found
+	// in the file, e.g. when it's in a template.
+	Filename string
+	Line     int
+
+	Err error
+}
+
+type refErrors struct {
+	mu     sync.Mutex
+	errors []*RefError
+}
+
+func (p *Page) recordRefError(ref string, err error) {
+	if err == nil || p.s == nil || p.s.owner == nil || p.s.owner.refErrors == nil {
+		return
+	}
+
+	re := &RefError{Page: p, Ref: ref, Filename: p.File.Path(), Err: err}
+
+	// The same ref in the same page fails for every output format.
+	c := p.s.owner.refErrors
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, e := range c.errors {
+		if e.Page == p && e.Ref == ref {
+			return
+		}
+	}
+	c.errors = append(c.errors, re)
+}
+
+// RefErrors returns the ref and relref lookups that failed in the last
+// full build, ordered by filename and line.
+func (h *HugoSites) RefErrors() []*RefError {
+	if h.refErrors == nil {
+		return nil
+	}
+
+	h.refErrors.mu.Lock()
+	errors := make([]*RefError, len(h.refErrors.errors))
+	copy(errors, h.refErrors.errors)
+	h.refErrors.mu.Unlock()
+
+	for _, e := range errors {
+		if e.Line == 0 {
+			e.Line = e.Page.lineOf(e.Ref)
+		}
+	}
+
+	sort.Sort(refErrorsByPosition(errors))
+
+	return errors
+}
+
+// lineOf returns the 1-based line of the first occurrence of s in the
+// page's content file, 0 if not found.
+func (p *Page) lineOf(s string) int {
+	// Refs are written with forward slashes.
+	s = strings.TrimPrefix(filepath.ToSlash(s), "/")
+
+	b := p.sourceContent()
+	return lineAt(b, bytes.Index(b, []byte(s)))
+}
+
+// sourceContent reads the page's content file, front matter included.
+func (p *Page) sourceContent() []byte {
+	filename := filepath.Join(p.s.absContentDir(), p.File.Path())
+	b, err := fsintra.ReadFile(p.s.Fs.Source, filename)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// lineAt returns the 1-based line of the byte offset idx in b, 0 if idx
+// is negative.
+func lineAt(b []byte, idx int) int {
+	if idx < 0 {
+		return 0
+	}
+	return bytes.Count(b[:idx], []byte("\n")) + 1
+}
+
+type refErrorsByPosition []*RefError
+
+func (r refErrorsByPosition) Len() int      { return len(r) }
+func (r refErrorsByPosition) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r refErrorsByPosition) Less(i, j int) bool {
+	if r[i].Filename != r[j].Filename {
+		return r[i].Filename < r[j].Filename
+	}
+	return r[i].Line < r[j].Line
+}
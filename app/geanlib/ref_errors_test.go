@@ -0,0 +1,36 @@
+package geanlib
+
+import (
+	"testing"
+
+	"github.com/govenue/fsintra"
+	"github.com/govenue/require"
+)
+
+func TestRefErrors(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	siteConfig := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "sitemap", "robotsTXT", "404", "RSS"]
+`
+
+	mf := fsintra.NewMemMapFs()
+	writeToFs(t, mf, "content/sect/p1.md", "---\ntitle: P1\n---\n\nLink to [P2]({{< ref \"p2.md\" >}}).\n\nLink to [nothing]({{< relref \"nothing.md\" >}}).\n")
+	writeToFs(t, mf, "content/sect/p2.md", "---\ntitle: P2\n---\nContent")
+
+	_, h := newTestSitesFromConfig(t, mf, siteConfig,
+		"layouts/_default/single.html", "Single|{{ .Title }}|{{ .Content }}",
+		"layouts/_default/list.html", "List|{{ .Title }}",
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	errs := h.RefErrors()
+	assert.Len(errs, 1)
+	assert.Equal("nothing.md", errs[0].Ref)
+	assert.Equal("P1", errs[0].Page.Title)
+	assert.Equal(7, errs[0].Line)
+}
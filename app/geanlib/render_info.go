@@ -0,0 +1,181 @@
+package geanlib
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/geego/gean/app/output"
+	"github.com/geego/gean/app/tpl"
+)
+
+var _ tpl.PartialObserver = (*renderInfoCollector)(nil)
+
+// RenderInfo describes how a published file was rendered.
+// This is only collected when trackRenderInfo is set, as it is in the
+// server's inspector.
+type RenderInfo struct {
+	// The absolute filename this was written to in the destination filesystem.
+	Filename string
+
+	// The name of the template executed.
+	Template string
+
+	// The layouts looked up, in order, to find Template.
+	Layouts []string
+
+	// The page rendered, nil if this isn't a page, e.g. the sitemap index.
+	Page *PageOutput
+
+	// The output format rendered. Empty if not a page.
+	OutputFormat output.Format
+
+	// When this file was rendered and how long it took.
+	Time     time.Time
+	Duration time.Duration
+
+	// The partials executed with the page as context, in execution order.
+	Partials []PartialExecution
+}
+
+// PartialExecution represents a single partial execution.
+type PartialExecution struct {
+	Name     string
+	Duration time.Duration
+}
+
+// renderInfoCollector records RenderInfo for every file rendered.
+type renderInfoCollector struct {
+	mu       sync.RWMutex
+	infos    map[string]*RenderInfo
+	inflight map[*PageOutput]*RenderInfo
+}
+
+func newRenderInfoCollector() *renderInfoCollector {
+	return &renderInfoCollector{
+		infos:    make(map[string]*RenderInfo),
+		inflight: make(map[*PageOutput]*RenderInfo),
+	}
+}
+
+// start registers a new render. The returned func must be called when done.
+func (c *renderInfoCollector) start(info *RenderInfo) func() {
+	info.Time = time.Now()
+
+	c.mu.Lock()
+	if info.Page != nil {
+		c.inflight[info.Page] = info
+	}
+	c.mu.Unlock()
+
+	return func() {
+		c.mu.Lock()
+		info.Duration = time.Since(info.Time)
+		if info.Page != nil {
+			delete(c.inflight, info.Page)
+		}
+		c.infos[info.Filename] = info
+		c.mu.Unlock()
+	}
+}
+
+// ObservePartial implements tpl.PartialObserver.
+func (c *renderInfoCollector) ObservePartial(name string, context interface{}, start time.Time) {
+	p, ok := context.(*PageOutput)
+	if !ok {
+		return
+	}
+
+	d := time.Since(start)
+
+	c.mu.Lock()
+	if info, found := c.inflight[p]; found {
+		info.Partials = append(info.Partials, PartialExecution{Name: name, Duration: d})
+	}
+	c.mu.Unlock()
+}
+
+func (c *renderInfoCollector) get(filename string) *RenderInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.infos[filename]
+}
+
+func (c *renderInfoCollector) all() []*RenderInfo {
+	c.mu.RLock()
+	infos := make([]*RenderInfo, 0, len(c.infos))
+	for _, info := range c.infos {
+		infos = append(infos, info)
+	}
+	c.mu.RUnlock()
+
+	sort.Sort(renderInfosByFilename(infos))
+
+	return infos
+}
+
+type renderInfosByFilename []*RenderInfo
+
+func (r renderInfosByFilename) Len() int           { return len(r) }
+func (r renderInfosByFilename) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r renderInfosByFilename) Less(i, j int) bool { return r[i].Filename < r[j].Filename }
+
+// RenderInfo returns information about how the file with the given absolute
+// filename in the destination filesystem was rendered.
+// It returns nil if render info tracking is disabled or the file is unknown.
+func (h *HugoSites) RenderInfo(filename string) *RenderInfo {
+	if h.renderInfo == nil {
+		return nil
+	}
+	return h.renderInfo.get(filepath.Clean(filename))
+}
+
+// RenderInfos returns the render info for all files rendered, sorted by
+// filename.
+func (h *HugoSites) RenderInfos() []*RenderInfo {
+	if h.renderInfo == nil {
+		return nil
+	}
+	return h.renderInfo.all()
+}
+
+// initRenderInfo enables render info tracking if configured.
+// This must be called when the sites' deps are in place.
+func (h *HugoSites) initRenderInfo() {
+	if !h.Cfg.GetBool("trackRenderInfo") {
+		return
+	}
+
+	if h.renderInfo == nil {
+		h.renderInfo = newRenderInfoCollector()
+	}
+
+	for _, s := range h.Sites {
+		s.Deps.PartialObserver = h.renderInfo
+	}
+}
+
+// startRenderInfo starts recording how dest is rendered. It returns a func
+// that must be called when done.
+func (s *Site) startRenderInfo(dest string, p *PageOutput, layouts []string) func() {
+	if s.owner == nil || s.owner.renderInfo == nil {
+		return func() {}
+	}
+
+	info := &RenderInfo{
+		Filename: filepath.Join(s.absPublishDir(), dest),
+		Layouts:  layouts,
+		Page:     p,
+	}
+
+	if p != nil {
+		info.OutputFormat = p.outputFormat
+	}
+
+	if templ := s.findFirstTemplate(layouts...); templ != nil {
+		info.Template = templ.Name()
+	}
+
+	return s.owner.renderInfo.start(info)
+}
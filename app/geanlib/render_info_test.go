@@ -0,0 +1,56 @@
+package geanlib
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/govenue/fsintra"
+	"github.com/govenue/require"
+)
+
+func TestRenderInfo(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	siteConfig := `
+baseURL = "http://example.com/"
+trackRenderInfo = true
+disableKinds = ["taxonomy", "taxonomyTerm", "sitemap", "robotsTXT", "404", "RSS"]
+`
+
+	mf := fsintra.NewMemMapFs()
+	writeToFs(t, mf, "content/sect/p1.md", "---\ntitle: P1\nparams:\n  color: blue\n---\nContent")
+	writeToFs(t, mf, "layouts/_default/single.html", `{{ partial "head.html" . }}|{{ partial "head.html" "not a page" }}|{{ .Title }}`)
+	writeToFs(t, mf, "layouts/_default/list.html", `List|{{ .Title }}`)
+	writeToFs(t, mf, "layouts/partials/head.html", `head`)
+
+	_, h := newTestSitesFromConfig(t, mf, siteConfig)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	var info *RenderInfo
+	for _, ri := range h.RenderInfos() {
+		if strings.HasSuffix(ri.Filename, filepath.FromSlash("sect/p1/index.html")) {
+			info = ri
+		}
+	}
+
+	assert.NotNil(info)
+	assert.Equal(info, h.RenderInfo(info.Filename))
+	assert.Equal("_default/single.html", info.Template)
+	assert.Equal("P1", info.Page.Title)
+	assert.Equal("HTML", info.OutputFormat.Name)
+	assert.Len(info.Partials, 1)
+	assert.Equal("partials/head.html", info.Partials[0].Name)
+}
+
+func TestRenderInfoDisabled(t *testing.T) {
+	t.Parallel()
+
+	_, h := newTestSitesFromConfigWithDefaultTemplates(t, `baseURL = "http://example.com/"`)
+
+	require.NoError(t, h.Build(BuildCfg{}))
+	require.Nil(t, h.RenderInfos())
+}
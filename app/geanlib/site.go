@@ -8,6 +8,7 @@ import (
 	"mime"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -18,12 +19,15 @@ import (
 
 	"github.com/geego/gean/app/config"
 	"github.com/geego/gean/app/deps"
+	"github.com/geego/gean/app/geanfs"
 	"github.com/geego/gean/app/helpers"
 	"github.com/geego/gean/app/media"
 	"github.com/geego/gean/app/nitro"
 	"github.com/geego/gean/app/output"
 	"github.com/geego/gean/app/parser"
 	"github.com/geego/gean/app/related"
+	"github.com/geego/gean/app/schema"
+	"github.com/geego/gean/app/search"
 	"github.com/geego/gean/app/source"
 	"github.com/geego/gean/app/tpl"
 	"github.com/geego/gean/app/transform"
@@ -122,6 +126,23 @@ type Site struct {
 
 	relatedDocsHandler *relatedDocsHandler
 
+	// The front matter schemas per content type.
+	schemas *frontMatterSchemas
+
+	searchIndexConfig search.Config
+
+	// The plural names of the hierarchical taxonomies.
+	hierarchicalTaxonomies map[string]bool
+
+	// The archive periods per section.
+	archives map[string][]string
+
+	// The menus generated from the section tree, by menu name.
+	sectionMenus map[string]sectionMenuConfig
+
+	// The series of the regular pages, by key.
+	seriesByKey map[string]*seriesPages
+
 	siteStats *siteStats
 }
 
@@ -160,16 +181,21 @@ func (s *Site) isEnabled(kind string) bool {
 // reset returns a new Site prepared for rebuild.
 func (s *Site) reset() *Site {
 	return &Site{Deps: s.Deps,
-		layoutHandler:       output.NewLayoutHandler(s.PathSpec.ThemeSet()),
-		disabledKinds:       s.disabledKinds,
-		titleFunc:           s.titleFunc,
-		relatedDocsHandler:  newSearchIndexHandler(s.relatedDocsHandler.cfg),
-		outputFormats:       s.outputFormats,
-		outputFormatsConfig: s.outputFormatsConfig,
-		mediaTypesConfig:    s.mediaTypesConfig,
-		Language:            s.Language,
-		owner:               s.owner,
-		PageCollections:     newPageCollections()}
+		layoutHandler:          output.NewLayoutHandler(s.PathSpec.ThemeSet()),
+		disabledKinds:          s.disabledKinds,
+		titleFunc:              s.titleFunc,
+		relatedDocsHandler:     s.relatedDocsHandler.reset(),
+		schemas:                s.schemas,
+		searchIndexConfig:      s.searchIndexConfig,
+		hierarchicalTaxonomies: s.hierarchicalTaxonomies,
+		archives:               s.archives,
+		sectionMenus:           s.sectionMenus,
+		outputFormats:          s.outputFormats,
+		outputFormatsConfig:    s.outputFormatsConfig,
+		mediaTypesConfig:       s.mediaTypesConfig,
+		Language:               s.Language,
+		owner:                  s.owner,
+		PageCollections:        newPageCollections()}
 }
 
 // newSite creates a new site with the given configuration.
@@ -214,7 +240,15 @@ func newSite(cfg deps.DepsCfg) (*Site, error) {
 		return nil, err
 	}
 
-	outputFormats, err := createSiteOutputFormats(siteOutputFormatsConfig, cfg.Language)
+	// The SearchIndex format can only be set in the site outputs config. It is
+	// not added to the formats the templates are looked up by, see
+	// output.SearchIndexFormat. One adjusted in the outputFormats config wins.
+	allOutputFormatsConfig := siteOutputFormatsConfig
+	if _, found := siteOutputFormatsConfig.GetByName(output.SearchIndexFormat.Name); !found {
+		allOutputFormatsConfig = append(siteOutputFormatsConfig, output.SearchIndexFormat)
+	}
+
+	outputFormats, err := createSiteOutputFormats(allOutputFormatsConfig, cfg.Language)
 	if err != nil {
 		return nil, err
 	}
@@ -234,18 +268,57 @@ func newSite(cfg deps.DepsCfg) (*Site, error) {
 		}
 	}
 
+	// The terms of the content indexed for related content are kept in one
+	// file per project and language in the cacheDir, if set, so they are
+	// reused by the next build.
+	relatedContentCache := related.NewContentCache()
+	if cacheDir := cfg.Cfg.GetString("cacheDir"); cacheDir != "" && !cfg.Cfg.GetBool("ignoreCache") {
+		name := helpers.Md5String(cfg.Cfg.GetString("workingDir") + "/" + cfg.Language.Lang)
+		relatedContentCache = related.NewFileContentCache(geanfs.Os, filepath.Join(cacheDir, "related-"+name+".json"))
+	}
+
+	schemas, err := schema.DecodeConfig(cfg.Language.Get("schemas"))
+	if err != nil {
+		return nil, err
+	}
+
+	searchIndexConfig, err := search.DecodeConfig(cfg.Language.Get("searchIndex"))
+	if err != nil {
+		return nil, err
+	}
+
+	hierarchicalTaxonomies, err := decodeHierarchicalTaxonomies(cfg.Language.Get("hierarchicalTaxonomies"))
+	if err != nil {
+		return nil, err
+	}
+
+	archives, err := decodeArchives(cfg.Language.Get("archives"))
+	if err != nil {
+		return nil, err
+	}
+
+	sectionMenus, err := decodeSectionMenus(cfg.Language.Get("sectionMenus"))
+	if err != nil {
+		return nil, err
+	}
+
 	titleFunc := helpers.GetTitleFunc(cfg.Language.GetString("titleCaseStyle"))
 
 	s := &Site{
-		PageCollections:     c,
-		layoutHandler:       output.NewLayoutHandler(cfg.Cfg.GetString("themesDir") != ""),
-		Language:            cfg.Language,
-		disabledKinds:       disabledKinds,
-		titleFunc:           titleFunc,
-		relatedDocsHandler:  newSearchIndexHandler(relatedContentConfig),
-		outputFormats:       outputFormats,
-		outputFormatsConfig: siteOutputFormatsConfig,
-		mediaTypesConfig:    siteMediaTypesConfig,
+		PageCollections:        c,
+		layoutHandler:          output.NewLayoutHandler(cfg.Cfg.GetString("themesDir") != ""),
+		Language:               cfg.Language,
+		disabledKinds:          disabledKinds,
+		titleFunc:              titleFunc,
+		relatedDocsHandler:     newSearchIndexHandler(relatedContentConfig, relatedContentCache),
+		schemas:                newFrontMatterSchemas(schemas, cfg.Language.GetStringMapString("taxonomies")),
+		searchIndexConfig:      searchIndexConfig,
+		hierarchicalTaxonomies: hierarchicalTaxonomies,
+		archives:               archives,
+		sectionMenus:           sectionMenus,
+		outputFormats:          outputFormats,
+		outputFormatsConfig:    siteOutputFormatsConfig,
+		mediaTypesConfig:       siteMediaTypesConfig,
 	}
 
 	s.Info = newSiteInfo(siteBuilderCfg{s: s, pageCollections: c, language: s.Language})
@@ -566,6 +639,7 @@ func (s *Site) reProcess(events []fsnotify.Event) (whatChanged, error) {
 	dataChanged := []fsnotify.Event{}
 	i18nChanged := []fsnotify.Event{}
 	shortcodesChanged := make(map[string]bool)
+	schemaChanged := false
 	// prevent spamming the log on changes
 	logger := helpers.NewDistinctFeedbackLogger()
 	seen := make(map[fsnotify.Event]bool)
@@ -600,6 +674,25 @@ func (s *Site) reProcess(events []fsnotify.Event) (whatChanged, error) {
 			logger.Println("i18n changed", ev)
 			i18nChanged = append(dataChanged, ev)
 		}
+		if s.isSchemaFileEvent(ev) {
+			logger.Println("Schema changed", ev)
+			schemaChanged = true
+		}
+	}
+
+	if schemaChanged {
+		for _, site := range s.owner.Sites {
+			if site.schemas != nil {
+				site.schemas.reset()
+			}
+		}
+
+		// Read the pages again to validate them against the new schemas.
+		for _, p := range s.rawAllPages {
+			if p.Kind == KindPage {
+				sourceChanged = append(sourceChanged, fsnotify.Event{Name: filepath.Join(s.absContentDir(), p.File.Path()), Op: fsnotify.Write})
+			}
+		}
 	}
 
 	if len(tmplChanged) > 0 || len(i18nChanged) > 0 {
@@ -673,6 +766,7 @@ func (s *Site) reProcess(events []fsnotify.Event) (whatChanged, error) {
 			//remove the file & a create will follow
 			path, _ := helpers.GetRelativePath(ev.Name, s.getContentDir(ev.Name))
 			s.removePageByPathPrefix(path)
+			s.removeFrontMatterErrors(path)
 			continue
 		}
 
@@ -684,6 +778,7 @@ func (s *Site) reProcess(events []fsnotify.Event) (whatChanged, error) {
 			if ex, err := fsintra.Exists(s.Fs.Source, ev.Name); !ex || err != nil {
 				path, _ := helpers.GetRelativePath(ev.Name, s.getContentDir(ev.Name))
 				s.removePageByPath(path)
+				s.removeFrontMatterErrors(path)
 				continue
 			}
 		}
@@ -1442,20 +1537,18 @@ func (s *Site) getMenusFromConfig() Menus {
 				for _, entry := range m {
 					s.Log.DEBUG.Printf("found menu: %q, in site config\n", name)
 
-					menuEntry := MenuEntry{Menu: name}
 					ime, err := assist.ToStringMapE(entry)
 					if err != nil {
 						s.Log.ERROR.Printf("unable to process menus in site config\n")
 						s.Log.ERROR.Println(err)
 					}
 
-					menuEntry.marshallMap(ime)
-					menuEntry.URL = s.Info.createNodeMenuEntryURL(menuEntry.URL)
+					menuEntry := s.newMenuEntry(name, ime)
 
 					if ret[name] == nil {
 						ret[name] = &Menu{}
 					}
-					*ret[name] = ret[name].add(&menuEntry)
+					*ret[name] = ret[name].add(menuEntry)
 				}
 			}
 		}
@@ -1495,6 +1588,19 @@ func (s *Site) assembleMenus() {
 		}
 	}
 
+	// add menu entries from data files and the section tree, unless already
+	// in the config
+	for _, menus := range []Menus{s.getMenusFromData(), s.getMenusFromSections()} {
+		for name, menu := range menus {
+			for _, me := range *menu {
+				if _, ok := flat[twoD{name, me.KeyName()}]; ok {
+					continue
+				}
+				flat[twoD{name, me.KeyName()}] = me
+			}
+		}
+	}
+
 	sectionPagesMenu := s.Info.sectionPagesMenu
 	pages := s.Pages
 
@@ -1586,6 +1692,7 @@ func (s *Site) assembleTaxonomies() {
 
 	for singular, plural := range taxonomies {
 		s.taxonomiesPluralSingular[plural] = singular
+		hierarchical := s.isHierarchicalTaxonomy(plural)
 
 		for _, p := range s.Pages {
 			vals := p.getParam(plural, !s.Info.preserveTaxonomyNames)
@@ -1593,23 +1700,39 @@ func (s *Site) assembleTaxonomies() {
 			if weight == nil {
 				weight = 0
 			}
-			if vals != nil {
-				if v, ok := vals.([]string); ok {
-					for _, idx := range v {
-						x := WeightedPage{weight.(int), p}
-						s.Taxonomies[plural].add(s.getTaxonomyKey(idx), x)
-						if s.Info.preserveTaxonomyNames {
-							// Need to track the original
-							s.taxonomiesOrigKey[fmt.Sprintf("%s-%s", plural, s.PathSpec.MakePathSanitized(idx))] = idx
-						}
+
+			// Pages are rolled up to the parent terms in hierarchical
+			// taxonomies, but only once per term.
+			added := make(map[string]bool)
+
+			addTerm := func(term string) {
+				terms := []string{term}
+				if hierarchical {
+					terms = termPaths(term)
+				}
+				for _, term := range terms {
+					key := s.getTaxonomyKey(term)
+					if hierarchical && added[key] {
+						continue
 					}
-				} else if v, ok := vals.(string); ok {
+					added[key] = true
+
 					x := WeightedPage{weight.(int), p}
-					s.Taxonomies[plural].add(s.getTaxonomyKey(v), x)
+					s.Taxonomies[plural].add(key, x)
 					if s.Info.preserveTaxonomyNames {
 						// Need to track the original
-						s.taxonomiesOrigKey[fmt.Sprintf("%s-%s", plural, s.PathSpec.MakePathSanitized(v))] = v
+						s.taxonomiesOrigKey[fmt.Sprintf("%s-%s", plural, s.PathSpec.MakePathSanitized(term))] = term
 					}
+				}
+			}
+
+			if vals != nil {
+				if v, ok := vals.([]string); ok {
+					for _, idx := range v {
+						addTerm(idx)
+					}
+				} else if v, ok := vals.(string); ok {
+					addTerm(v)
 				} else {
 					s.Log.ERROR.Printf("Invalid %s in %s\n", plural, p.File.Path())
 				}
@@ -1626,7 +1749,7 @@ func (s *Site) assembleTaxonomies() {
 // Prepare site for a new full build.
 func (s *Site) resetBuildState() {
 
-	s.relatedDocsHandler = newSearchIndexHandler(s.relatedDocsHandler.cfg)
+	s.relatedDocsHandler = s.relatedDocsHandler.reset()
 	s.PageCollections = newPageCollectionsFromPages(s.rawAllPages)
 	// TODO(bep) get rid of this double
 	s.Info.PageCollections = s.PageCollections
@@ -1640,6 +1763,7 @@ func (s *Site) resetBuildState() {
 	for _, p := range s.rawAllPages {
 		p.scratch = newScratch()
 		p.subSections = Pages{}
+		p.childTerms = nil
 		p.parent = nil
 	}
 }
@@ -1747,10 +1871,26 @@ func (s *Site) Stats() {
 // GetPage looks up a page of a given type in the path given.
 //    {{ with .Site.GetPage "section" "blog" }}{{ .Title }}{{ end }}
 //
-// This will return nil when no page could be found, and will return the
-// first page found if the key is ambigous.
+// Or by its path from the content root, with or without the file extension,
+// and matched case-insensitively:
+//    {{ with .Site.GetPage "/episodes/season-2/ep-10" }}{{ .Title }}{{ end }}
+//
+// This will return nil when no page could be found, also for an unknown type
+// with a path. A page path matching more than one page is an error, the first
+// page found is returned if the key in the given type is ambigous.
 func (s *SiteInfo) GetPage(typ string, path ...string) (*Page, error) {
-	return s.getPage(typ, path...), nil
+	for _, kind := range allKindsInPages {
+		if typ == kind {
+			return s.getPage(typ, path...), nil
+		}
+	}
+
+	if len(path) > 0 {
+		// Not a page path, and not a kind either.
+		return nil, nil
+	}
+
+	return s.getPageByPath(s.Language.Lang, typ)
 }
 
 func (s *Site) permalinkForOutputFormat(link string, f output.Format) (string, error) {
@@ -1789,6 +1929,9 @@ func (s *Site) renderAndWriteXML(name string, dest string, d interface{}, layout
 	defer bp.PutBuffer(renderBuffer)
 	renderBuffer.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\" standalone=\"yes\" ?>\n")
 
+	p, _ := d.(*PageOutput)
+	defer s.startRenderInfo(dest, p, layouts)()
+
 	if err := s.renderForLayouts(name, d, renderBuffer, layouts...); err != nil {
 		helpers.DistinctWarnLog.Println(err)
 		return nil
@@ -1813,7 +1956,12 @@ func (s *Site) renderAndWriteXML(name string, dest string, d interface{}, layout
 		return nil
 	}
 
-	return s.publish(dest, outBuffer)
+	source := name
+	if p != nil {
+		source = p.sourceDescription()
+	}
+
+	return s.publish(source, dest, outBuffer)
 
 }
 
@@ -1821,6 +1969,8 @@ func (s *Site) renderAndWritePage(name string, dest string, p *PageOutput, layou
 	renderBuffer := bp.GetBuffer()
 	defer bp.PutBuffer(renderBuffer)
 
+	defer s.startRenderInfo(dest, p, layouts)()
+
 	if err := s.renderForLayouts(p.Kind, p, renderBuffer, layouts...); err != nil {
 		helpers.DistinctWarnLog.Println(err)
 		return nil
@@ -1872,7 +2022,7 @@ func (s *Site) renderAndWritePage(name string, dest string, p *PageOutput, layou
 		return nil
 	}
 
-	return s.publish(dest, outBuffer)
+	return s.publish(p.sourceDescription(), dest, outBuffer)
 }
 
 func (s *Site) renderForLayouts(name string, d interface{}, w io.Writer, layouts ...string) (err error) {
@@ -1920,8 +2070,13 @@ func (s *Site) findFirstTemplate(layouts ...string) tpl.Template {
 	return nil
 }
 
-func (s *Site) publish(path string, r io.Reader) (err error) {
+// publish writes r to path in the publish dir. The source describes what
+// is written, see OutputCollisions.
+func (s *Site) publish(source, path string, r io.Reader) (err error) {
 	path = filepath.Join(s.absPublishDir(), path)
+	if s.owner != nil && s.owner.published != nil {
+		s.owner.published.add(path, source)
+	}
 	return helpers.WriteToDisk(path, r, s.Fs.Destination)
 }
 
@@ -2020,14 +2175,19 @@ func (s *Site) newTaxonomyPage(plural, key string) *Page {
 
 	p := s.newNodePage(KindTaxonomy, plural, key)
 
+	name := key
+	if s.isHierarchicalTaxonomy(plural) {
+		// Title the term, not the path.
+		name = path.Base(key)
+	}
+
 	if s.Info.preserveTaxonomyNames {
 		// Keep (mostly) as is in the title
 		// We make the first character upper case, mostly because
 		// it is easier to reason about in the tests.
-		p.Title = helpers.FirstUpper(key)
-		key = s.PathSpec.MakePathSanitized(key)
+		p.Title = helpers.FirstUpper(name)
 	} else {
-		p.Title = strings.Replace(s.titleFunc(key), "-", " ", -1)
+		p.Title = strings.Replace(s.titleFunc(name), "-", " ", -1)
 	}
 
 	return p
@@ -0,0 +1,178 @@
+package geanlib
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/govenue/assist"
+)
+
+// The archive periods.
+const (
+	archiveYear  = "year"
+	archiveMonth = "month"
+)
+
+// decodeArchives decodes the archives config, the archive periods keyed by
+// section, e.g.:
+//
+//	[archives]
+//	episodes = ["year", "month"]
+//	blog = "year"
+func decodeArchives(in interface{}) (map[string][]string, error) {
+	archives := make(map[string][]string)
+
+	if in == nil {
+		return archives, nil
+	}
+
+	m, err := assist.ToStringMapE(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode archives config: %s", err)
+	}
+
+	for section, v := range m {
+		periods := assist.ToStringSlice(v)
+		for _, period := range periods {
+			if period != archiveYear && period != archiveMonth {
+				return nil, fmt.Errorf("invalid archive period %q for section %q, must be %q or %q", period, section, archiveYear, archiveMonth)
+			}
+		}
+		archives[section] = periods
+	}
+
+	return archives, nil
+}
+
+// archiveSections returns the sections of the archive page for the given
+// period containing the given date, e.g. ["episodes", "2017", "05"].
+func archiveSections(section, period string, date time.Time) []string {
+	if period == archiveMonth {
+		return []string{section, date.Format("2006"), date.Format("01")}
+	}
+	return []string{section, date.Format("2006")}
+}
+
+// archivePeriod returns the period and the first day of the period of the
+// archive page with the given sections.
+func archivePeriod(sections []string) (string, time.Time) {
+	if len(sections) == 3 {
+		start, _ := time.Parse("2006/01", sections[1]+"/"+sections[2])
+		return archiveMonth, start
+	}
+	start, _ := time.Parse("2006", sections[1])
+	return archiveYear, start
+}
+
+// inArchive returns whether the given page is listed on the archive page
+// with the given sections, by its publish date.
+func inArchive(p *Page, sections []string) bool {
+	if p.Kind != KindPage || p.Section() != sections[0] {
+		return false
+	}
+
+	date := p.PubDate()
+	if date.IsZero() {
+		return false
+	}
+
+	period, _ := archivePeriod(sections)
+
+	return path.Join(archiveSections(sections[0], period, date)...) == path.Join(sections...)
+}
+
+// assembleArchives creates the archive pages of the configured sections
+// not already created, one for every period with published pages.
+func (s *Site) assembleArchives() Pages {
+	var newPages Pages
+
+	if !s.isEnabled(KindArchive) || len(s.archives) == 0 {
+		return newPages
+	}
+
+	archivePages := make(map[string]*Page)
+	for _, p := range s.findPagesByKind(KindArchive) {
+		archivePages[path.Join(p.sections...)] = p
+	}
+
+	for _, p := range s.findPagesByKind(KindPage) {
+		periods, found := s.archives[p.Section()]
+		if !found {
+			continue
+		}
+
+		date := p.PubDate()
+		if date.IsZero() {
+			continue
+		}
+
+		for _, period := range periods {
+			sections := archiveSections(p.Section(), period, date)
+			key := path.Join(sections...)
+			if _, found := archivePages[key]; found {
+				continue
+			}
+			n := s.newArchivePage(sections...)
+			archivePages[key] = n
+			newPages = append(newPages, n)
+		}
+	}
+
+	s.linkArchivePeriods(archivePages)
+
+	return newPages
+}
+
+// linkArchivePeriods links every archive page to the archive pages of the
+// previous and next periods in the same section.
+func (s *Site) linkArchivePeriods(archivePages map[string]*Page) {
+	var keys []string
+	for key := range archivePages {
+		keys = append(keys, key)
+	}
+	// The periods are zero padded, so this sorts them by date.
+	sort.Strings(keys)
+
+	last := make(map[string]*Page)
+	for _, key := range keys {
+		p := archivePages[key]
+		period, _ := archivePeriod(p.sections)
+		group := p.sections[0] + "/" + period
+
+		p.prevPeriod, p.nextPeriod = nil, nil
+		if prev, found := last[group]; found {
+			p.prevPeriod = prev
+			prev.nextPeriod = p
+		}
+		last[group] = p
+	}
+}
+
+func (s *Site) newArchivePage(sections ...string) *Page {
+	p := s.newNodePage(KindArchive, sections...)
+
+	period, start := archivePeriod(sections)
+	if period == archiveMonth {
+		p.Title = start.Format("January 2006")
+	} else {
+		p.Title = start.Format("2006")
+	}
+
+	return p
+}
+
+// PrevPeriod returns the archive page of the previous period with pages in
+// the same section, e.g. 2016 for 2017. Note that for other pages than
+// archive pages, this method will always return nil.
+func (p *Page) PrevPeriod() *Page {
+	return p.prevPeriod
+}
+
+// NextPeriod returns the archive page of the next period with pages in the
+// same section, e.g. 2018 for 2017. Note that for other pages than archive
+// pages, this method will always return nil.
+func (p *Page) NextPeriod() *Page {
+	return p.nextPeriod
+}
@@ -0,0 +1,90 @@
+package geanlib
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/govenue/fsintra"
+	"github.com/govenue/require"
+)
+
+func TestArchives(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	siteConfig := `
+baseURL = "http://example.com/"
+paginate = 2
+
+[archives]
+episodes = ["year", "month"]
+`
+
+	pageTemplate := `---
+title: %s
+date: %s
+%s
+---
+Content
+`
+
+	mf := fsintra.NewMemMapFs()
+	writeToFs(t, mf, "content/episodes/e1.md", fmt.Sprintf(pageTemplate, "E1", "2016-12-03", ""))
+	// Archived by the publish date.
+	writeToFs(t, mf, "content/episodes/e2.md", fmt.Sprintf(pageTemplate, "E2", "2017-04-28", "publishDate: 2017-05-02"))
+	writeToFs(t, mf, "content/episodes/e3.md", fmt.Sprintf(pageTemplate, "E3", "2017-05-10", ""))
+	writeToFs(t, mf, "content/episodes/e4.md", fmt.Sprintf(pageTemplate, "E4", "2017-07-01", ""))
+	// Not published yet.
+	writeToFs(t, mf, "content/episodes/e5.md", fmt.Sprintf(pageTemplate, "E5", "2099-01-01", ""))
+	writeToFs(t, mf, "content/blog/b1.md", fmt.Sprintf(pageTemplate, "B1", "2017-05-01", ""))
+
+	th, h := newTestSitesFromConfig(t, mf, siteConfig,
+		"layouts/_default/single.html", "Single|{{ .Title }}",
+		"layouts/_default/list.html", "List|{{ .Title }}",
+		"layouts/_default/archive.html", `Archive|{{ .Title }}|{{ range .Pages }}{{ .Title }},{{ end }}|{{ range .Paginator.Pages }}{{ .Title }},{{ end }}|{{ with .PrevPeriod }}Prev:{{ .Title }}{{ end }}|{{ with .NextPeriod }}Next:{{ .Title }}{{ end }}`,
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/episodes/2016/index.html", "Archive|2016|E1,|E1,||Next:2017")
+	th.assertFileContent("public/episodes/2017/index.html", "Archive|2017|E4,E3,E2,|E4,E3,|Prev:2016|")
+	th.assertFileContent("public/episodes/2017/page/2/index.html", "Archive|2017|E4,E3,E2,|E2,|Prev:2016|")
+	th.assertFileContent("public/episodes/2016/12/index.html", "Archive|December 2016|E1,|E1,||Next:May 2017")
+	th.assertFileContent("public/episodes/2017/05/index.html", "Archive|May 2017|E3,E2,|E3,E2,|Prev:December 2016|Next:July 2017")
+	th.assertFileContent("public/episodes/2017/07/index.html", "Archive|July 2017|E4,|E4,|Prev:May 2017|")
+	th.assertFileContent("public/episodes/2017/index.xml", "<title>E4</title>")
+
+	th.assertFileNotExist("public/episodes/2017/04/index.html")
+	th.assertFileNotExist("public/episodes/2099/index.html")
+	th.assertFileNotExist("public/blog/2017/index.html")
+
+	s := h.Sites[0]
+
+	may := s.getPage(KindArchive, "episodes", "2017", "05")
+	assert.NotNil(may)
+	assert.Equal("episodes", may.Section())
+	assert.Equal("month", may.Data["Period"])
+	assert.Equal(2017, may.Data["Year"])
+	assert.Equal(time.May, may.Data["Month"])
+	assert.Equal("/episodes/2017/05/", may.RelPermalink())
+
+	assert.Len(s.findPagesByKind(KindArchive), 5)
+}
+
+func TestDecodeArchives(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	archives, err := decodeArchives(map[string]interface{}{
+		"episodes": []interface{}{"year", "month"},
+		"blog":     "year",
+	})
+	assert.NoError(err)
+	assert.Equal(map[string][]string{"episodes": {"year", "month"}, "blog": {"year"}}, archives)
+
+	_, err = decodeArchives(map[string]interface{}{"blog": "week"})
+	assert.Error(err)
+}
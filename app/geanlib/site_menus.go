@@ -0,0 +1,303 @@
+package geanlib
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/geego/gean/app/helpers"
+	"github.com/govenue/assist"
+)
+
+// newMenuEntry creates a menu entry in the given menu from a menu entry map in
+// the site config or a data file. A page set with the page key, e.g.
+// page = "/blog", is looked up by path, see SiteInfo's GetPage, and provides
+// the entry's defaults, like in front matter menus.
+func (s *Site) newMenuEntry(menu string, ime map[string]interface{}) *MenuEntry {
+	me := &MenuEntry{Menu: menu}
+
+	for k, v := range ime {
+		if strings.ToLower(k) != "page" {
+			continue
+		}
+		ref := assist.ToString(v)
+		p, err := s.getPageByPath(s.Language.Lang, ref)
+		if err != nil {
+			s.Log.ERROR.Printf("Failed to resolve page in menu %q: %s", menu, err)
+		} else if p == nil {
+			s.Log.WARN.Printf("Page %q in menu %q not found", ref, menu)
+		} else {
+			me.Name = p.LinkTitle()
+			me.Weight = p.Weight
+			me.URL = p.RelPermalink()
+			me.Page = p
+		}
+	}
+
+	pageURL := me.URL
+	me.marshallMap(ime)
+	if me.URL != pageURL {
+		me.URL = s.Info.createNodeMenuEntryURL(me.URL)
+	}
+
+	return me
+}
+
+// getMenusFromData returns the menus defined in data/menus, one file per
+// menu, e.g. data/menus/main.yaml, with the menu entries keyed by
+// identifier:
+//
+//	blog:
+//	  page: /blog
+//	  weight: 10
+//	github:
+//	  name: GitHub
+//	  url: https://github.com/geego/gean
+//
+// The entries in the menu file for the current language, e.g.
+// data/menus/main.fr.yaml, are merged into these, key by key.
+func (s *Site) getMenusFromData() Menus {
+	ret := Menus{}
+
+	files, ok := s.Data["menus"].(map[string]interface{})
+	if !ok {
+		return ret
+	}
+
+	entries := make(map[string]map[string]map[string]interface{})
+
+	addEntries := func(name string, file interface{}) {
+		m, err := assist.ToStringMapE(file)
+		if err != nil {
+			s.Log.ERROR.Printf("Unable to process menu %q in data: %s", name, err)
+			return
+		}
+		if entries[name] == nil {
+			entries[name] = make(map[string]map[string]interface{})
+		}
+		for id, entry := range m {
+			ime, err := assist.ToStringMapE(entry)
+			if err != nil {
+				s.Log.ERROR.Printf("Unable to process menu entry %q in menu %q in data: %s", id, name, err)
+				continue
+			}
+			if entries[name][id] == nil {
+				entries[name][id] = make(map[string]interface{})
+			}
+			for k, v := range ime {
+				entries[name][id][strings.ToLower(k)] = v
+			}
+		}
+	}
+
+	var names []string
+	for key := range files {
+		if !strings.Contains(key, ".") {
+			names = append(names, key)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		addEntries(name, files[name])
+	}
+
+	// Merge in the language files last.
+	for key, file := range files {
+		i := strings.LastIndex(key, ".")
+		if i == -1 || key[i+1:] != s.Language.Lang {
+			continue
+		}
+		addEntries(key[:i], file)
+	}
+
+	for name, ids := range entries {
+		for id, ime := range ids {
+			me := s.newMenuEntry(name, ime)
+			if me.Identifier == "" {
+				me.Identifier = id
+			}
+			if me.Name == "" {
+				me.Name = id
+			}
+			if ret[name] == nil {
+				ret[name] = &Menu{}
+			}
+			*ret[name] = ret[name].add(me)
+		}
+	}
+
+	return ret
+}
+
+// sectionMenuConfig configures a menu generated from the section tree.
+type sectionMenuConfig struct {
+	// The section to start from, e.g. "docs". Empty for the root sections.
+	section string
+
+	// The number of levels below the start section to include. 0 is all.
+	depth int
+
+	// The section paths to include and exclude, as path.Match patterns,
+	// e.g. "docs/*". An excluded section is excluded with its subsections.
+	include []string
+	exclude []string
+
+	// Whether to add the regular pages of the sections.
+	pages bool
+}
+
+// decodeSectionMenus decodes the sectionMenus config, the section tree menus
+// keyed by menu name, e.g.:
+//
+//	[sectionMenus.docs]
+//	section = "docs"
+//	depth = 2
+//	exclude = ["docs/internal"]
+//	pages = true
+func decodeSectionMenus(in interface{}) (map[string]sectionMenuConfig, error) {
+	menus := make(map[string]sectionMenuConfig)
+
+	if in == nil {
+		return menus, nil
+	}
+
+	m, err := assist.ToStringMapE(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode sectionMenus config: %s", err)
+	}
+
+	for name, v := range m {
+		mm, err := assist.ToStringMapE(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode sectionMenus config for menu %q: %s", name, err)
+		}
+
+		var conf sectionMenuConfig
+		for k, v := range mm {
+			switch strings.ToLower(k) {
+			case "section":
+				conf.section = strings.Trim(filepath.ToSlash(assist.ToString(v)), "/")
+			case "depth":
+				conf.depth = assist.ToInt(v)
+			case "include":
+				conf.include = assist.ToStringSlice(v)
+			case "exclude":
+				conf.exclude = assist.ToStringSlice(v)
+			case "pages":
+				conf.pages = assist.ToBool(v)
+			default:
+				return nil, fmt.Errorf("unknown sectionMenus setting %q for menu %q", k, name)
+			}
+		}
+
+		for _, pattern := range append(conf.include, conf.exclude...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid section pattern %q for menu %q: %s", pattern, name, err)
+			}
+		}
+
+		menus[name] = conf
+	}
+
+	return menus, nil
+}
+
+func matchesAnySection(patterns []string, sectionPath string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, sectionPath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// getMenusFromSections returns the menus generated from the section tree,
+// see decodeSectionMenus. The entries are weighted by the weight in the
+// _index content files, and identified by their section path, e.g.
+// "docs/guide", or content path for the regular pages. A page with a front
+// matter entry in the menu keeps that entry.
+func (s *Site) getMenusFromSections() Menus {
+	ret := Menus{}
+
+	sections := s.findPagesByKind(KindSection)
+	// Parents first.
+	pageBy(func(p1, p2 *Page) bool {
+		if len(p1.sections) == len(p2.sections) {
+			return path.Join(p1.sections...) < path.Join(p2.sections...)
+		}
+		return len(p1.sections) < len(p2.sections)
+	}).Sort(sections)
+
+	for name, conf := range s.sectionMenus {
+		var root []string
+		if conf.section != "" {
+			root = strings.Split(conf.section, "/")
+		}
+
+		var (
+			menu     = Menu{}
+			added    = make(map[*Page]*MenuEntry)
+			levels   = make(map[*Page]int)
+			excluded = make(map[*Page]bool)
+		)
+
+		addEntry := func(p *Page, id string) *MenuEntry {
+			me, found := p.Menus()[name]
+			if !found {
+				me = &MenuEntry{Menu: name, Identifier: id, Name: p.LinkTitle(), Weight: p.Weight, URL: p.RelPermalink(), Page: p}
+				for parent := p.parent; parent != nil; parent = parent.parent {
+					if pe, found := added[parent]; found {
+						me.Parent = pe.KeyName()
+						break
+					}
+				}
+				menu = menu.add(me)
+			}
+			added[p] = me
+			return me
+		}
+
+		for _, sect := range sections {
+			if len(sect.sections) <= len(root) || !helpers.HasStringsPrefix(sect.sections, root) {
+				continue
+			}
+
+			level := len(sect.sections) - len(root)
+			if conf.depth > 0 && level > conf.depth {
+				continue
+			}
+
+			sectionPath := path.Join(sect.sections...)
+			if excluded[sect.parent] || matchesAnySection(conf.exclude, sectionPath) {
+				excluded[sect] = true
+				continue
+			}
+
+			if len(conf.include) > 0 && !matchesAnySection(conf.include, sectionPath) {
+				continue
+			}
+
+			addEntry(sect, sectionPath)
+			levels[sect] = level
+		}
+
+		if conf.pages {
+			for _, p := range s.RegularPages {
+				level, found := levels[p.parent]
+				if !found || (conf.depth > 0 && level >= conf.depth) {
+					continue
+				}
+				addEntry(p, filepath.ToSlash(p.Source.Path()))
+			}
+		}
+
+		if len(menu) > 0 {
+			ret[name] = &menu
+		}
+	}
+
+	return ret
+}
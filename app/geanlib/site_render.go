@@ -84,6 +84,15 @@ func pageRenderer(s *Site, pages <-chan *Page, results chan<- error, wg *sync.Wa
 				continue
 			}
 
+			if strings.EqualFold(outFormat.Name, output.SearchIndexFormat.Name) {
+				// The search index is rendered without a template, also when
+				// its format is adjusted in the site config.
+				if err := s.renderSearchIndex(pageOutput); err != nil {
+					results <- err
+				}
+				continue
+			}
+
 			var layouts []string
 
 			if page.selfLayout != "" {
@@ -331,10 +340,12 @@ func (s *Site) renderRobotsTXT() error {
 	n.Data["Pages"] = s.Pages
 	n.Pages = s.Pages
 
-	rLayouts := []string{"robots.txt", "_default/robots.txt", "_internal/_default/robots.txt"}
+	rLayouts := s.appendThemeTemplates([]string{"robots.txt", "_default/robots.txt", "_internal/_default/robots.txt"})
+	defer s.startRenderInfo("robots.txt", nil, rLayouts)()
+
 	outBuffer := bp.GetBuffer()
 	defer bp.PutBuffer(outBuffer)
-	if err := s.renderForLayouts("robots", n, outBuffer, s.appendThemeTemplates(rLayouts)...); err != nil {
+	if err := s.renderForLayouts("robots", n, outBuffer, rLayouts...); err != nil {
 		helpers.DistinctWarnLog.Println(err)
 		return nil
 	}
@@ -343,7 +354,7 @@ func (s *Site) renderRobotsTXT() error {
 		return nil
 	}
 
-	return s.publish("robots.txt", outBuffer)
+	return s.publish("robots.txt", "robots.txt", outBuffer)
 }
 
 // renderAliases renders shell pages that simply have a redirect in the header.
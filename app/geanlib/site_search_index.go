@@ -0,0 +1,78 @@
+package geanlib
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/geego/gean/app/helpers"
+	"github.com/geego/gean/app/search"
+	"github.com/govenue/assist"
+)
+
+// renderSearchIndex renders the search index of the regular pages listed
+// by p, all of them for the home page, see search.Index.
+func (s *Site) renderSearchIndex(p *PageOutput) error {
+	pages := p.Pages
+	if p.Kind == KindHome {
+		pages = s.RegularPages
+	}
+
+	var taxonomies []string
+	for _, plural := range s.Language.GetStringMapString("taxonomies") {
+		taxonomies = append(taxonomies, plural)
+	}
+
+	b := search.NewBuilder(s.searchIndexConfig, s.Language.Lang, taxonomies)
+
+	for _, page := range pages {
+		if page.Kind != KindPage {
+			continue
+		}
+
+		terms := make(map[string][]string)
+		for _, plural := range taxonomies {
+			if v := page.getParam(plural, false); v != nil {
+				terms[plural] = assist.ToStringSlice(v)
+			}
+		}
+
+		b.Add(search.Source{
+			ID:         page.RelPermalink(),
+			Title:      page.Title,
+			Summary:    helpers.StripHTML(string(page.Summary)),
+			Sections:   page.sections,
+			Taxonomies: terms,
+			Words:      page.PlainWords(),
+			Params:     page.Params,
+		})
+	}
+
+	targetPath, err := p.targetPath()
+	if err != nil {
+		return err
+	}
+
+	idx, shards := b.Build(filepath.Base(targetPath))
+
+	if err := s.publishJSON(p.sourceDescription(), targetPath, idx); err != nil {
+		return err
+	}
+
+	for _, shard := range shards {
+		shardPath := filepath.Join(filepath.Dir(targetPath), shard.Name)
+		if err := s.publishJSON(p.sourceDescription(), shardPath, shard); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Site) publishJSON(source, path string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.publish(source, path, bytes.NewReader(b))
+}
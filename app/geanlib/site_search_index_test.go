@@ -0,0 +1,146 @@
+package geanlib
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/geego/gean/app/search"
+	"github.com/govenue/fsintra"
+	"github.com/govenue/require"
+)
+
+func TestSearchIndex(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	siteConfig := `
+baseURL = "http://example.com/"
+disableKinds = ["sitemap", "robotsTXT", "404", "RSS", "taxonomy", "taxonomyTerm"]
+
+[outputs]
+home = ["HTML", "SearchIndex"]
+section = ["HTML", "SearchIndex"]
+
+[searchIndex]
+facets = ["author"]
+[searchIndex.weights]
+summary = 0
+`
+
+	mf := fsintra.NewMemMapFs()
+	writeToFs(t, mf, "content/post/a.md", "---\ntitle: Cats\ntags: [\"pets\"]\nauthor: Ann\n---\nThe cats were running.")
+	writeToFs(t, mf, "content/docs/b.md", "---\ntitle: Dogs\n---\nDogs jumped.")
+
+	th, h := newTestSitesFromConfig(t, mf, siteConfig,
+		"layouts/_default/single.html", "Single|{{ .Title }}",
+		"layouts/_default/list.html", "List|{{ .Title }}",
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	read := func(filename string) *search.Index {
+		var idx search.Index
+		assert.NoError(json.Unmarshal([]byte(readDestination(t, th.Fs, filepath.FromSlash(filename))), &idx))
+		return &idx
+	}
+
+	idx := read("public/searchindex.json")
+
+	assert.Equal("en", idx.Lang)
+	assert.Equal("id", idx.Ref)
+	assert.Equal([]string{"author"}, idx.Facets)
+	assert.Equal([]search.Field{
+		{Name: "title", Weight: 10},
+		{Name: "sections", Weight: 2},
+		{Name: "categories", Weight: 3},
+		{Name: "tags", Weight: 3},
+		{Name: "content", Weight: 1},
+	}, idx.Fields)
+
+	assert.Len(idx.Docs, 2)
+	assert.Equal("/docs/b/", idx.Docs[0]["id"])
+	assert.Equal("dog jump", idx.Docs[0]["content"])
+
+	cats := idx.Docs[1]
+	assert.Equal("/post/a/", cats["id"])
+	assert.Equal("Cats", cats["title"])
+	assert.Equal([]interface{}{"post"}, cats["sections"])
+	assert.Equal([]interface{}{"pets"}, cats["tags"])
+	assert.Equal("cat run", cats["content"])
+	assert.Equal(map[string]interface{}{"author": "Ann"}, cats["facets"])
+	assert.Nil(cats["summary"])
+
+	// Only the pages in the section.
+	idx = read("public/post/searchindex.json")
+	assert.Len(idx.Docs, 1)
+	assert.Equal("/post/a/", idx.Docs[0]["id"])
+}
+
+func TestSearchIndexShards(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	siteConfig := `
+baseURL = "http://example.com/"
+disableKinds = ["sitemap", "robotsTXT", "404", "RSS", "taxonomy", "taxonomyTerm"]
+
+[outputs]
+home = ["HTML", "SearchIndex"]
+
+[searchIndex]
+shardSize = 2
+`
+
+	mf := fsintra.NewMemMapFs()
+	for _, name := range []string{"a", "b", "c"} {
+		writeToFs(t, mf, "content/"+name+".md", "---\ntitle: "+name+"\n---\nContent")
+	}
+
+	th, h := newTestSitesFromConfig(t, mf, siteConfig,
+		"layouts/_default/single.html", "Single|{{ .Title }}",
+		"layouts/_default/list.html", "List|{{ .Title }}",
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/searchindex.json", `"shards":["searchindex-1.json","searchindex-2.json"]`)
+	th.assertFileContent("public/searchindex-1.json", `"id":"/a/"`, `"id":"/b/"`)
+	th.assertFileContent("public/searchindex-2.json", `"id":"/c/"`)
+}
+
+func TestSearchIndexFormatConfig(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	siteConfig := `
+baseURL = "http://example.com/"
+disableKinds = ["sitemap", "robotsTXT", "404", "RSS", "taxonomy", "taxonomyTerm"]
+
+[outputs]
+home = ["HTML", "SearchIndex"]
+
+[outputFormats.SearchIndex]
+mediaType = "application/json"
+baseName = "search"
+isPlainText = true
+noUgly = true
+`
+
+	mf := fsintra.NewMemMapFs()
+	writeToFs(t, mf, "content/a.md", "---\ntitle: a\n---\nContent")
+
+	th, h := newTestSitesFromConfig(t, mf, siteConfig,
+		"layouts/_default/single.html", "Single|{{ .Title }}",
+		"layouts/_default/list.html", "List|{{ .Title }}",
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	// The adjusted format is still rendered without a template.
+	th.assertFileContent("public/search.json", `"id":"/a/"`)
+	th.assertFileNotExist("public/searchindex.json")
+}
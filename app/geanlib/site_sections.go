@@ -27,10 +27,83 @@ func (s *SiteInfo) Home() (*Page, error) {
 
 // Parent returns a section's parent section or a page's section.
 // To get a section's subsections, see Page's Sections method.
+// In hierarchical taxonomies, this is a term's parent term, see Page's
+// Children method.
 func (p *Page) Parent() *Page {
 	return p.parent
 }
 
+// Ancestors returns the parents of this page, from the root, e.g. the home
+// page, down to the page's parent.
+func (p *Page) Ancestors() Pages {
+	var ancestors Pages
+	for parent := p.parent; parent != nil; parent = parent.parent {
+		ancestors = append(Pages{parent}, ancestors...)
+	}
+	return ancestors
+}
+
+// FirstSection returns the page's root section, e.g. the blog section for a
+// page in blog/2017, or the home page for the home page and the root level
+// pages. For archive pages, this is the archived section.
+// Note that this will return nil for taxonomy and series pages.
+func (p *Page) FirstSection() *Page {
+	v := p
+	if v.origOnCopy != nil {
+		v = v.origOnCopy
+	}
+
+	if v.Kind == KindArchive {
+		return v.s.getPage(KindSection, v.sections[0])
+	}
+
+	var first *Page
+	for sect := v.CurrentSection(); sect != nil && sect.IsSection(); sect = sect.parent {
+		first = sect
+	}
+
+	if first == nil {
+		return v.CurrentSection()
+	}
+
+	return first
+}
+
+// Breadcrumbs returns the pages from the home page down to and including
+// this page, e.g. for a term page in a taxonomy: the home page, the taxonomy
+// terms page, the parent terms if hierarchical and the term page.
+// See the breadcrumbs.html internal template.
+func (p *Page) Breadcrumbs() Pages {
+	v := p
+	if v.origOnCopy != nil {
+		v = v.origOnCopy
+	}
+
+	var crumbs Pages
+
+	if v.Kind == KindArchive {
+		if sect := v.s.getPage(KindSection, v.sections[0]); sect != nil {
+			crumbs = append(sect.Ancestors(), sect)
+		}
+		if len(v.sections) == 3 {
+			// The year of the month.
+			if year := v.s.getPage(KindArchive, v.sections[:2]...); year != nil {
+				crumbs = append(crumbs, year)
+			}
+		}
+	} else {
+		crumbs = v.Ancestors()
+	}
+
+	if !v.IsHome() && (len(crumbs) == 0 || !crumbs[0].IsHome()) {
+		if home := v.s.getPage(KindHome); home != nil {
+			crumbs = append(Pages{home}, crumbs...)
+		}
+	}
+
+	return append(crumbs, v)
+}
+
 // CurrentSection returns the page's current section or the page itself if home or a section.
 // Note that this will return nil for pages that is not regular, home or section pages.
 func (p *Page) CurrentSection() *Page {
@@ -42,6 +115,11 @@ func (p *Page) CurrentSection() *Page {
 		return v
 	}
 
+	if v.isTaxonomyKind() {
+		// The parent is a taxonomy term, if any.
+		return nil
+	}
+
 	return v.parent
 }
 
@@ -66,13 +144,17 @@ func (p *Page) InSection(other interface{}) (bool, error) {
 }
 
 // IsDescendant returns whether the current page is a descendant of the given page.
-// Note that this method is not relevant for taxonomy lists and taxonomy terms pages.
+// For taxonomy pages, this is by the parent terms, see Page's Ancestors method.
 func (p *Page) IsDescendant(other interface{}) (bool, error) {
 	pp, err := unwrapPage(other)
 	if err != nil {
 		return false, err
 	}
 
+	if p.isTaxonomyKind() || pp.isTaxonomyKind() {
+		return p.hasAncestor(pp), nil
+	}
+
 	if pp.Kind == KindPage && len(p.sections) == len(pp.sections) {
 		// A regular page is never its section's descendant.
 		return false, nil
@@ -81,13 +163,17 @@ func (p *Page) IsDescendant(other interface{}) (bool, error) {
 }
 
 // IsAncestor returns whether the current page is an ancestor of the given page.
-// Note that this method is not relevant for taxonomy lists and taxonomy terms pages.
+// For taxonomy pages, this is by the parent terms, see Page's Ancestors method.
 func (p *Page) IsAncestor(other interface{}) (bool, error) {
 	pp, err := unwrapPage(other)
 	if err != nil {
 		return false, err
 	}
 
+	if p.isTaxonomyKind() || pp.isTaxonomyKind() {
+		return pp.hasAncestor(p), nil
+	}
+
 	if p.Kind == KindPage && len(p.sections) == len(pp.sections) {
 		// A regular page is never its section's ancestor.
 		return false, nil
@@ -96,6 +182,20 @@ func (p *Page) IsAncestor(other interface{}) (bool, error) {
 	return helpers.HasStringsPrefix(pp.sections, p.sections), nil
 }
 
+func (p *Page) isTaxonomyKind() bool {
+	return p.Kind == KindTaxonomy || p.Kind == KindTaxonomyTerm
+}
+
+// hasAncestor returns whether the given page is one of this page's parents.
+func (p *Page) hasAncestor(other *Page) bool {
+	for parent := p.parent; parent != nil; parent = parent.parent {
+		if parent == other {
+			return true
+		}
+	}
+	return false
+}
+
 // Eq returns whether the current page equals the given page.
 // Note that this is more accurate than doing `{{ if eq $page $otherPage }}`
 // since a Page can be embedded in another type.
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/geego/gean/app/deps"
+	"github.com/govenue/fsintra"
 	"github.com/govenue/require"
 )
 
@@ -287,3 +288,78 @@ PAG|{{ .Title }}|{{ $sect.InSection . }}
 	th.assertFileContent("public/l1/l2/page/2/index.html", "L1/l2-IsActive: true", "PAG|T2_3|true")
 
 }
+
+func TestBreadcrumbs(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	siteConfig := `
+baseURL = "http://example.com/"
+title = "My Site"
+hierarchicalTaxonomies = ["categories"]
+
+[taxonomies]
+category = "categories"
+
+[archives]
+episodes = ["year", "month"]
+`
+
+	pageTemplate := `---
+title: "%s"
+date: 2017-05-10
+categories: ["tech/go"]
+---
+Content
+`
+
+	mf := fsintra.NewMemMapFs()
+	writeToFs(t, mf, "content/about.md", fmt.Sprintf(pageTemplate, "About"))
+	writeToFs(t, mf, "content/blog/2017/_index.md", fmt.Sprintf(pageTemplate, "Blog 2017"))
+	writeToFs(t, mf, "content/blog/2017/post.md", fmt.Sprintf(pageTemplate, "Post"))
+	writeToFs(t, mf, "content/episodes/_index.md", fmt.Sprintf(pageTemplate, "Episodes"))
+	writeToFs(t, mf, "content/episodes/e1.md", fmt.Sprintf(pageTemplate, "E1"))
+
+	th, h := newTestSitesFromConfig(t, mf, siteConfig,
+		"layouts/_default/single.html", `Single|{{ range .Breadcrumbs }}{{ .Title }}/{{ end }}|{{ with .FirstSection }}{{ .Title }}{{ end }}|{{ template "_internal/breadcrumbs.html" . }}`,
+		"layouts/_default/list.html", `List|{{ range .Breadcrumbs }}{{ .Title }}/{{ end }}`,
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/blog/2017/post/index.html",
+		"Single|My Site/Blogs/Blog 2017/Post/|Blogs|",
+		`<li class="breadcrumb-item active" aria-current="page"><a href="/blog/2017/post/">Post</a></li>`,
+		`"@type": "BreadcrumbList"`,
+		`"position":  3 ,`,
+		`"name": "Blog 2017",`,
+		`"item": "http://example.com/blog/2017/"`,
+	)
+	th.assertFileContent("public/about/index.html", "Single|My Site/About/|My Site|")
+	th.assertFileContent("public/index.html", "List|My Site/")
+	th.assertFileContent("public/categories/tech/go/index.html", "List|My Site/Categories/Tech/Go/")
+	th.assertFileContent("public/categories/index.html", "List|My Site/Categories/")
+	th.assertFileContent("public/episodes/2017/05/index.html", "List|My Site/Episodes/2017/May 2017/")
+
+	s := h.Sites[0]
+
+	blog := s.getPage(KindSection, "blog")
+	post := s.getPage(KindPage, "blog/2017/post.md")
+	tech := s.getPage(KindTaxonomy, "categories", "tech")
+	golang := s.getPage(KindTaxonomy, "categories", "tech/go")
+
+	assert.Equal(blog, post.FirstSection())
+	assert.Equal(blog, blog.FirstSection())
+	assert.Nil(golang.FirstSection())
+
+	isAncestor, err := tech.IsAncestor(golang)
+	assert.NoError(err)
+	assert.True(isAncestor)
+	isDescendant, err := golang.IsDescendant(tech)
+	assert.NoError(err)
+	assert.True(isDescendant)
+	isAncestor, err = golang.IsAncestor(tech)
+	assert.NoError(err)
+	assert.False(isAncestor)
+}
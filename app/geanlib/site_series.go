@@ -0,0 +1,242 @@
+package geanlib
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/govenue/assist"
+)
+
+// seriesTaxonomy is the name of the taxonomy whose term pages are used as the
+// series landing pages if configured.
+const seriesTaxonomy = "series"
+
+// Series is a page's place in a series of pages meant to be read in order,
+// e.g. a multi-part tutorial.
+//
+// A page is added to a series with the series front matter param, ordered by
+// seriesWeight, or by listing it in the order file of the series, e.g.
+// data/series/my-series.yaml:
+//
+//	title: My Series
+//	pages:
+//	- tutorials/part-1.md
+//	- tutorials/part-2.md
+//
+// Pages listed in the order file come first, in that order.
+type Series struct {
+	// The series name, from the order file or the front matter.
+	Name string
+
+	// The position of the page in the series, starting at 1.
+	Index int
+
+	// The number of pages in the series.
+	Total int
+
+	// The previous and next pages in the series, if any.
+	Prev *Page
+	Next *Page
+
+	// All the pages in the series, in order.
+	Pages Pages
+
+	// The landing page of the series, nil if disabled.
+	Page *Page
+}
+
+// Series returns the page's place in its series, nil if not in a series.
+func (p *Page) Series() *Series {
+	return p.series
+}
+
+type seriesPages struct {
+	key   string
+	name  string
+	pages Pages
+	page  *Page
+}
+
+// seriesOrderFile is an order file in data/series.
+type seriesOrderFile struct {
+	title string
+	pages []string
+}
+
+func (s *Site) seriesOrderFiles() map[string]seriesOrderFile {
+	files := make(map[string]seriesOrderFile)
+
+	data, ok := s.Data["series"].(map[string]interface{})
+	if !ok {
+		return files
+	}
+
+	for key, v := range data {
+		m, err := assist.ToStringMapE(v)
+		if err != nil {
+			s.Log.ERROR.Printf("Invalid series order file %q: %s", key, err)
+			continue
+		}
+		files[key] = seriesOrderFile{
+			title: assist.ToString(m["title"]),
+			pages: assist.ToStringSlice(m["pages"]),
+		}
+	}
+
+	return files
+}
+
+// seriesName returns the series name in the front matter, if any.
+func (p *Page) seriesName() string {
+	switch v := p.getParam("series", false).(type) {
+	case string:
+		return v
+	case []string:
+		if len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+// assembleSeries assigns the regular pages to their series and creates the
+// series landing pages not already created.
+func (s *Site) assembleSeries() Pages {
+	var newPages Pages
+
+	s.seriesByKey = make(map[string]*seriesPages)
+
+	regularPages := s.findPagesByKind(KindPage)
+	byPath := make(map[string]*Page)
+	for _, p := range regularPages {
+		p.series = nil
+		byPath[filepath.ToSlash(p.Source.Path())] = p
+	}
+
+	seriesFor := func(key, name string) *seriesPages {
+		sp, found := s.seriesByKey[key]
+		if !found {
+			sp = &seriesPages{key: key, name: name}
+			s.seriesByKey[key] = sp
+		}
+		if sp.name == "" {
+			sp.name = name
+		}
+		return sp
+	}
+
+	added := make(map[*Page]string)
+
+	orderFiles := s.seriesOrderFiles()
+	var keys []string
+	for key := range orderFiles {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		of := orderFiles[key]
+		sp := seriesFor(key, of.title)
+		for _, pth := range of.pages {
+			p, found := byPath[filepath.ToSlash(pth)]
+			if !found {
+				s.Log.WARN.Printf("Page %q in series %q not found", pth, key)
+				continue
+			}
+			if other, found := added[p]; found {
+				s.Log.WARN.Printf("Page %q in series %q is already in series %q", pth, key, other)
+				continue
+			}
+			added[p] = key
+			sp.pages = append(sp.pages, p)
+		}
+	}
+
+	unlisted := make(map[string]Pages)
+
+	for _, p := range regularPages {
+		name := p.seriesName()
+		if name == "" {
+			continue
+		}
+		if _, found := added[p]; found {
+			continue
+		}
+		key := s.PathSpec.MakePathSanitized(name)
+		seriesFor(key, name)
+		added[p] = key
+		unlisted[key] = append(unlisted[key], p)
+	}
+
+	for key, pages := range unlisted {
+		pageBy(seriesWeight).Sort(pages)
+		s.seriesByKey[key].pages = append(s.seriesByKey[key].pages, pages...)
+	}
+
+	_, useTaxonomy := s.Taxonomies[seriesTaxonomy]
+
+	seriesPagesByKey := make(map[string]*Page)
+	for _, p := range s.findPagesByKind(KindSeries) {
+		seriesPagesByKey[p.sections[1]] = p
+	}
+	if useTaxonomy {
+		for _, p := range s.findPagesByKind(KindTaxonomy) {
+			if p.sections[0] == seriesTaxonomy {
+				seriesPagesByKey[s.PathSpec.MakePathSanitized(p.termKey())] = p
+			}
+		}
+	}
+
+	for key, sp := range s.seriesByKey {
+		if sp.name == "" {
+			sp.name = key
+		}
+
+		sp.page = seriesPagesByKey[key]
+		if sp.page == nil && !useTaxonomy && s.isEnabled(KindSeries) {
+			sp.page = s.newSeriesPage(key, sp.name)
+			newPages = append(newPages, sp.page)
+		}
+
+		for i, p := range sp.pages {
+			series := &Series{
+				Name:  sp.name,
+				Index: i + 1,
+				Total: len(sp.pages),
+				Pages: sp.pages,
+				Page:  sp.page,
+			}
+			if i > 0 {
+				series.Prev = sp.pages[i-1]
+			}
+			if i < len(sp.pages)-1 {
+				series.Next = sp.pages[i+1]
+			}
+			p.series = series
+		}
+	}
+
+	return newPages
+}
+
+// seriesWeight orders the pages in a series not listed in an order file by
+// seriesWeight, date and title. Pages without a seriesWeight come last.
+func seriesWeight(p1, p2 *Page) bool {
+	w1, w2 := assist.ToInt(p1.getParam("seriesWeight", false)), assist.ToInt(p2.getParam("seriesWeight", false))
+	if w1 != w2 {
+		if w1 == 0 || w2 == 0 {
+			return w2 == 0
+		}
+		return w1 < w2
+	}
+	if !p1.Date.Equal(p2.Date) {
+		return p1.Date.Before(p2.Date)
+	}
+	return p1.Title < p2.Title
+}
+
+func (s *Site) newSeriesPage(key, name string) *Page {
+	p := s.newNodePage(KindSeries, seriesTaxonomy, key)
+	p.Title = name
+	return p
+}
@@ -0,0 +1,95 @@
+package geanlib
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/govenue/fsintra"
+	"github.com/govenue/require"
+)
+
+const seriesPageTemplate = `---
+title: %s
+date: %s
+%s
+---
+Content
+`
+
+var seriesLayouts = []string{
+	"layouts/_default/single.html", `Single|{{ .Title }}|{{ with .Series }}{{ .Name }} {{ .Index }}/{{ .Total }}|{{ with .Prev }}Prev:{{ .Title }}{{ end }}|{{ with .Next }}Next:{{ .Title }}{{ end }}|{{ .Page.RelPermalink }}{{ end }}`,
+	"layouts/_default/list.html", "List|{{ .Title }}|{{ range .Pages }}{{ .Title }},{{ end }}",
+	"layouts/_default/series.html", "Series|{{ .Title }}|{{ .Data.Series }}|{{ range .Pages }}{{ .Title }},{{ end }}",
+}
+
+func writeSeriesContent(t *testing.T, fs fsintra.Fs, seriesParam string) {
+	writeToFs(t, fs, "content/tutorials/a1.md", fmt.Sprintf(seriesPageTemplate, "Variables", "2017-01-03", seriesParam+"\nseriesWeight: 2"))
+	writeToFs(t, fs, "content/tutorials/a2.md", fmt.Sprintf(seriesPageTemplate, "Install", "2017-01-02", seriesParam+"\nseriesWeight: 1"))
+	writeToFs(t, fs, "content/tutorials/a3.md", fmt.Sprintf(seriesPageTemplate, "Extras", "2017-01-01", seriesParam))
+	writeToFs(t, fs, "content/other.md", fmt.Sprintf(seriesPageTemplate, "Other", "2017-01-01", ""))
+}
+
+func TestSeries(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	siteConfig := `
+baseURL = "http://example.com/"
+`
+
+	mf := fsintra.NewMemMapFs()
+	writeSeriesContent(t, mf, "series: Go Basics")
+
+	// Listed in the order file, and one more by front matter.
+	writeToFs(t, mf, "data/series/podcast-tips.yaml", "title: Podcast Tips\npages:\n- tips/mics.md\n- tips/intro.md\n- tips/missing.md\n")
+	writeToFs(t, mf, "content/tips/intro.md", fmt.Sprintf(seriesPageTemplate, "Intro", "2017-02-01", ""))
+	writeToFs(t, mf, "content/tips/mics.md", fmt.Sprintf(seriesPageTemplate, "Mics", "2017-02-02", ""))
+	writeToFs(t, mf, "content/tips/editing.md", fmt.Sprintf(seriesPageTemplate, "Editing", "2017-01-01", "series: Podcast Tips"))
+
+	th, h := newTestSitesFromConfig(t, mf, siteConfig, seriesLayouts...)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	th.assertFileContent("public/tutorials/a2/index.html", "Single|Install|Go Basics 1/3||Next:Variables|/series/go-basics/")
+	th.assertFileContent("public/tutorials/a1/index.html", "Single|Variables|Go Basics 2/3|Prev:Install|Next:Extras|/series/go-basics/")
+	th.assertFileContent("public/tutorials/a3/index.html", "Single|Extras|Go Basics 3/3|Prev:Variables||/series/go-basics/")
+	th.assertFileContent("public/other/index.html", "Single|Other|")
+
+	th.assertFileContent("public/tips/mics/index.html", "Single|Mics|Podcast Tips 1/3||Next:Intro|/series/podcast-tips/")
+	th.assertFileContent("public/tips/editing/index.html", "Single|Editing|Podcast Tips 3/3|Prev:Intro||/series/podcast-tips/")
+
+	th.assertFileContent("public/series/go-basics/index.html", "Series|Go Basics|Go Basics|Install,Variables,Extras,")
+	th.assertFileContent("public/series/podcast-tips/index.html", "Series|Podcast Tips|Podcast Tips|Mics,Intro,Editing,")
+	th.assertFileContent("public/series/podcast-tips/index.xml", "<title>Mics</title>")
+
+	s := h.Sites[0]
+	assert.Len(s.findPagesByKind(KindSeries), 2)
+	assert.Nil(s.getPage(KindPage, "other.md").Series())
+}
+
+func TestSeriesTaxonomy(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	siteConfig := `
+baseURL = "http://example.com/"
+
+[taxonomies]
+series = "series"
+`
+
+	mf := fsintra.NewMemMapFs()
+	writeSeriesContent(t, mf, "series: [\"Go Basics\"]")
+
+	th, h := newTestSitesFromConfig(t, mf, siteConfig, seriesLayouts...)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	// The taxonomy term page is the landing page.
+	th.assertFileContent("public/tutorials/a2/index.html", "Single|Install|Go Basics 1/3||Next:Variables|/series/go-basics/")
+	th.assertFileContent("public/series/go-basics/index.html", "List|Go Basics|")
+
+	assert.Len(h.Sites[0].findPagesByKind(KindSeries), 0)
+}
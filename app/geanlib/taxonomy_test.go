@@ -197,3 +197,105 @@ permalinkeds:
 	th.assertFileContent(pathFunc("public/empties/index.html"), "Terms List", "Empties")
 
 }
+
+func TestHierarchicalTaxonomies(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	siteConfig := `
+baseURL = "http://example.com/blog"
+hierarchicalTaxonomies = ["categories"]
+
+[Taxonomies]
+tag = "tags"
+category = "categories"
+`
+
+	pageTemplate := `---
+title: "%s"
+tags: ["a/b"]
+categories: [%s]
+---
+# Doc
+`
+
+	th, h := newTestSitesFromConfigWithDefaultTemplates(t, siteConfig)
+	fs := th.Fs
+
+	writeSource(t, fs, "content/p1.md", fmt.Sprintf(pageTemplate, "P1", `"tech/programming/go"`))
+	writeSource(t, fs, "content/p2.md", fmt.Sprintf(pageTemplate, "P2", `"Tech/Programming/Go", "tech/hardware/"`))
+	writeSource(t, fs, "content/p3.md", fmt.Sprintf(pageTemplate, "P3", `"life"`))
+	writeNewContentFile(t, fs, "Programming Languages", "2017-01-01", "content/categories/tech/programming/_index.md", 10)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	s := h.Sites[0]
+
+	// Pages are rolled up to the parent terms, once.
+	categories := s.Taxonomies["categories"]
+	assert.Len(categories, 5)
+	assert.Equal(2, categories.Count("tech"))
+	assert.Equal(2, categories.Count("tech/programming"))
+	assert.Equal(2, categories.Count("tech/programming/go"))
+	assert.Equal(1, categories.Count("tech/hardware"))
+	assert.Equal(1, categories.Count("life"))
+
+	// Other taxonomies are flat.
+	assert.Len(s.Taxonomies["tags"], 1)
+	assert.Equal(3, s.Taxonomies["tags"].Count("a/b"))
+
+	titles := func(pages Pages) []string {
+		var t []string
+		for _, p := range pages {
+			t = append(t, p.Title)
+		}
+		return t
+	}
+
+	terms := s.getPage(KindTaxonomyTerm, "categories")
+	tech := s.getPage(KindTaxonomy, "categories", "tech")
+	programming := s.getPage(KindTaxonomy, "categories", "tech", "programming")
+	golang := s.getPage(KindTaxonomy, "categories", "tech/programming/go")
+	assert.NotNil(terms)
+	assert.NotNil(tech)
+	assert.NotNil(programming)
+	assert.NotNil(golang)
+
+	assert.Equal("Go", golang.Title)
+	assert.Equal("/blog/categories/tech/programming/go/", golang.RelPermalink())
+	assert.Len(golang.Pages, 2)
+	assert.Equal("tech/programming/go", golang.Data["Term"])
+
+	assert.Equal(programming, golang.Parent())
+	assert.Equal(tech, programming.Parent())
+	assert.Equal(terms, tech.Parent())
+	assert.Nil(terms.Parent())
+	assert.Nil(golang.CurrentSection())
+
+	assert.Equal([]string{"Categories", "Tech", "Programming Languages"}, titles(golang.Ancestors()))
+	assert.Equal([]string{"Life", "Tech"}, titles(terms.Children()))
+	assert.Equal([]string{"Hardware", "Programming Languages"}, titles(tech.Children()))
+	assert.Len(golang.Children(), 0)
+
+	th.assertFileContent("public/categories/tech/programming/go/index.html", "List", "Go")
+	th.assertFileContent("public/categories/tech/index.html", "List", "Tech")
+	th.assertFileContent("public/tags/a/b/index.html", "List")
+}
+
+func TestDecodeHierarchicalTaxonomies(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	hierarchical, err := decodeHierarchicalTaxonomies([]interface{}{"categories"})
+	assert.NoError(err)
+	assert.Equal(map[string]bool{"categories": true}, hierarchical)
+
+	hierarchical, err = decodeHierarchicalTaxonomies(nil)
+	assert.NoError(err)
+	assert.Empty(hierarchical)
+
+	_, err = decodeHierarchicalTaxonomies(map[string]interface{}{"categories": true})
+	assert.Error(err)
+}
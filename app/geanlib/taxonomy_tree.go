@@ -0,0 +1,124 @@
+package geanlib
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/govenue/assist"
+)
+
+// decodeHierarchicalTaxonomies decodes the hierarchicalTaxonomies config, the
+// plural names of the taxonomies whose terms are paths, e.g.:
+//
+//	hierarchicalTaxonomies = ["categories"]
+func decodeHierarchicalTaxonomies(in interface{}) (map[string]bool, error) {
+	hierarchical := make(map[string]bool)
+
+	if in == nil {
+		return hierarchical, nil
+	}
+
+	plurals, err := assist.ToStringSliceE(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode hierarchicalTaxonomies config: %s", err)
+	}
+
+	for _, plural := range plurals {
+		hierarchical[plural] = true
+	}
+
+	return hierarchical, nil
+}
+
+// isHierarchicalTaxonomy returns whether the terms of the given taxonomy are
+// paths, e.g. "tech/programming/go", with a term page for every level.
+func (s *Site) isHierarchicalTaxonomy(plural string) bool {
+	return s.hierarchicalTaxonomies[plural]
+}
+
+// termPaths returns the term and its ancestors, from the root, for the
+// given term in a hierarchical taxonomy, i.e. "tech", "tech/programming" and
+// "tech/programming/go" for "tech/programming/go".
+func termPaths(term string) []string {
+	var (
+		paths   []string
+		current string
+	)
+
+	for _, part := range strings.Split(term, "/") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		current = path.Join(current, part)
+		paths = append(paths, current)
+	}
+
+	return paths
+}
+
+// termKey returns the key of the given taxonomy page, e.g.
+// "tech/programming/go".
+func (p *Page) termKey() string {
+	return path.Join(p.sections[1:]...)
+}
+
+// assembleTaxonomyTrees links the term pages of the hierarchical
+// taxonomies to their parent and child terms. The top level terms have
+// the taxonomy terms page as their parent.
+func (s *Site) assembleTaxonomyTrees() {
+	byTitle := func(p1, p2 *Page) bool {
+		return p1.Title < p2.Title
+	}
+
+	taxonomies := s.Language.GetStringMapString("taxonomies")
+
+	for _, plural := range taxonomies {
+		if !s.isHierarchicalTaxonomy(plural) {
+			continue
+		}
+
+		var root *Page
+		for _, p := range s.findPagesByKind(KindTaxonomyTerm) {
+			if p.sections[0] == plural {
+				root = p
+				p.childTerms = nil
+			}
+		}
+
+		terms := make(map[string]*Page)
+		for _, p := range s.findPagesByKind(KindTaxonomy) {
+			if p.sections[0] == plural {
+				terms[s.PathSpec.MakePathSanitized(p.termKey())] = p
+				p.childTerms = nil
+			}
+		}
+
+		for key, p := range terms {
+			p.parent = root
+			if i := strings.LastIndex(key, "/"); i != -1 {
+				p.parent = terms[key[:i]]
+			}
+
+			if p.parent != nil {
+				p.parent.childTerms = append(p.parent.childTerms, p)
+			}
+		}
+
+		if root != nil {
+			pageBy(byTitle).Sort(root.childTerms)
+		}
+		for _, p := range terms {
+			pageBy(byTitle).Sort(p.childTerms)
+		}
+	}
+}
+
+// Children returns the child terms of a term in a hierarchical taxonomy,
+// sorted by title. For the taxonomy terms page these are the top level
+// terms.
+// Note that for other pages, this method will always return an empty list.
+func (p *Page) Children() Pages {
+	return p.childTerms
+}
@@ -0,0 +1,234 @@
+package geanlib
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/geego/gean/app/tpl"
+)
+
+// TemplateProblem is a problem found by CheckTemplates.
+type TemplateProblem struct {
+	// The template or content file and the 1-based line the problem is on.
+	// Line is 0 if not known.
+	Filename string
+	Line     int
+
+	Message string
+}
+
+func (p *TemplateProblem) String() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", p.Filename, p.Line, p.Message)
+	}
+	return fmt.Sprintf("%s: %s", p.Filename, p.Message)
+}
+
+// CheckTemplates does a static analysis of the templates and checks them
+// against the content. It reports templates that failed to parse, e.g.
+// because of calls to unknown functions, partials that don't exist,
+// shortcodes used in content that aren't defined, layouts that no page uses
+// and page params never set in any front matter.
+//
+// Layouts are only checked if the sites were built with trackRenderInfo set.
+func (h *HugoSites) CheckTemplates() []*TemplateProblem {
+	analyzer, ok := h.Tmpl.(tpl.TemplateAnalyzer)
+	if !ok {
+		return nil
+	}
+
+	a := analyzer.AnalyzeTemplates()
+
+	var problems []*TemplateProblem
+
+	for _, e := range a.Errors {
+		problems = append(problems, &TemplateProblem{Filename: e.Name, Message: e.Err.Error()})
+	}
+
+	for _, ref := range a.Partials {
+		if isInternalTemplate(ref.Template) {
+			continue
+		}
+		if h.lookupPartial(ref.Name) == nil {
+			problems = append(problems, &TemplateProblem{Filename: ref.Template, Line: ref.Line, Message: fmt.Sprintf("partial %q not found", ref.Name)})
+		}
+	}
+
+	problems = append(problems, h.checkParams(a)...)
+	problems = append(problems, h.checkShortcodes()...)
+	problems = append(problems, h.checkLayouts(a)...)
+
+	sort.Sort(templateProblemsByPosition(problems))
+
+	return problems
+}
+
+func (h *HugoSites) lookupPartial(name string) *tpl.TemplateAdapter {
+	for _, n := range []string{"partials/" + name, "theme/partials/" + name} {
+		if templ := h.Tmpl.Lookup(n); templ != nil {
+			return templ
+		}
+		if templ := h.Tmpl.Lookup(n + ".html"); templ != nil {
+			return templ
+		}
+	}
+	return nil
+}
+
+// checkParams reports the page params read in the templates that aren't set
+// in the front matter of any page. The keys read with .Param may also be set
+// in the site params.
+func (h *HugoSites) checkParams(a *tpl.TemplateAnalysis) []*TemplateProblem {
+	keys := make(map[string]bool)
+	siteKeys := make(map[string]bool)
+	for _, s := range h.Sites {
+		for _, p := range s.rawAllPages {
+			for k := range p.Params {
+				keys[strings.ToLower(k)] = true
+			}
+		}
+		for k := range s.Info.Params {
+			siteKeys[strings.ToLower(k)] = true
+		}
+	}
+
+	var problems []*TemplateProblem
+	for _, ref := range a.Params {
+		if keys[ref.Name] || isInternalTemplate(ref.Template) {
+			continue
+		}
+		problems = append(problems, &TemplateProblem{Filename: ref.Template, Line: ref.Line, Message: fmt.Sprintf("param %q is not set in any front matter", ref.Name)})
+	}
+	for _, ref := range a.ParamLookups {
+		if keys[ref.Name] || siteKeys[ref.Name] || isInternalTemplate(ref.Template) {
+			continue
+		}
+		problems = append(problems, &TemplateProblem{Filename: ref.Template, Line: ref.Line, Message: fmt.Sprintf("param %q is not set in any front matter or in the site params", ref.Name)})
+	}
+
+	return problems
+}
+
+// checkShortcodes reports shortcodes used in content without a template.
+func (h *HugoSites) checkShortcodes() []*TemplateProblem {
+	var problems []*TemplateProblem
+
+	for _, s := range h.Sites {
+		for _, p := range s.rawAllPages {
+			if p.shortcodeState == nil {
+				continue
+			}
+
+			var names []string
+			for name := range p.shortcodeState.nameSet {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				if getShortcodeTemplateForTemplateKey(scKey{}, name, s.Tmpl) != nil {
+					continue
+				}
+				b := p.sourceContent()
+				line := 0
+				if i := shortcodeIndex(b, name); i >= 0 {
+					line = lineAt(b, i)
+				}
+				problems = append(problems, &TemplateProblem{
+					Filename: path.Join(s.Cfg.GetString("contentDir"), p.File.Path()),
+					Line:     line,
+					Message:  fmt.Sprintf("shortcode %q not found", name)})
+			}
+		}
+	}
+
+	return problems
+}
+
+// shortcodeIndex returns the index of the first use of the named shortcode in
+// b, e.g. {{< name >}} or {{% name %}}, or -1 if not found.
+func shortcodeIndex(b []byte, name string) int {
+	offset := 0
+	for {
+		i := bytes.Index(b[offset:], []byte("{{"))
+		if i < 0 || offset+i+2 >= len(b) {
+			return -1
+		}
+		start := offset + i
+		offset = start + 2
+
+		if b[offset] != '<' && b[offset] != '%' {
+			continue
+		}
+
+		rest := bytes.TrimLeft(b[offset+1:], " \t\r\n")
+		if !bytes.HasPrefix(rest, []byte(name)) || len(rest) == len(name) {
+			continue
+		}
+		if c := rest[len(name)]; c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '>' || c == '%' {
+			return start
+		}
+	}
+}
+
+// checkLayouts reports layouts not used by any page in the last build.
+func (h *HugoSites) checkLayouts(a *tpl.TemplateAnalysis) []*TemplateProblem {
+	if h.renderInfo == nil {
+		return nil
+	}
+
+	used := make(map[string]bool)
+	for _, info := range h.RenderInfos() {
+		used[info.Template] = true
+	}
+
+	// Templates included with the template keyword.
+	for _, ref := range a.TemplateCalls {
+		used[ref.Name] = true
+	}
+
+	var problems []*TemplateProblem
+	for _, name := range a.Templates {
+		if used[name] || !isLayoutTemplate(name) {
+			continue
+		}
+		problems = append(problems, &TemplateProblem{Filename: name, Message: "layout is not used by any page"})
+	}
+
+	return problems
+}
+
+// isLayoutTemplate returns whether the named template is a layout, as
+// opposed to a partial, shortcode, base template or internal template.
+func isLayoutTemplate(name string) bool {
+	name = strings.TrimPrefix(name, "theme/")
+	if isInternalTemplate(name) ||
+		strings.HasPrefix(name, "partials/") ||
+		strings.HasPrefix(name, "shortcodes/") ||
+		strings.HasPrefix(name, "alias") {
+		return false
+	}
+	base := path.Base(name)
+	return !strings.HasPrefix(base, "baseof.") && !strings.HasPrefix(base, "_")
+}
+
+func isInternalTemplate(name string) bool {
+	return strings.HasPrefix(name, "_internal/") || strings.HasPrefix(name, "_text/_internal/")
+}
+
+type templateProblemsByPosition []*TemplateProblem
+
+func (t templateProblemsByPosition) Len() int      { return len(t) }
+func (t templateProblemsByPosition) Swap(i, j int) { t[i], t[j] = t[j], t[i] }
+func (t templateProblemsByPosition) Less(i, j int) bool {
+	if t[i].Filename != t[j].Filename {
+		return t[i].Filename < t[j].Filename
+	}
+	if t[i].Line != t[j].Line {
+		return t[i].Line < t[j].Line
+	}
+	return t[i].Message < t[j].Message
+}
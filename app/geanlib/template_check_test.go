@@ -0,0 +1,68 @@
+package geanlib
+
+import (
+	"testing"
+
+	"github.com/govenue/fsintra"
+	"github.com/govenue/require"
+)
+
+func TestCheckTemplates(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	siteConfig := `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "sitemap", "robotsTXT", "404", "RSS"]
+trackRenderInfo = true
+
+[params]
+description = "Site description"
+`
+
+	mf := fsintra.NewMemMapFs()
+	writeToFs(t, mf, "content/sect/p1.md", "---\ntitle: P1\ncolor: blue\n---\n\nSome content.\n\n{{< missing >}}\n")
+
+	_, h := newTestSitesFromConfig(t, mf, siteConfig,
+		"layouts/_default/single.html", "Single|{{ .Title }}|{{ .Params.color }}|{{ .Params.size }}|{{ .Param \"description\" }}|{{ .Param \"nosuch\" }}|{{ if false }}{{ partial \"nope.html\" . }}{{ end }}",
+		"layouts/_default/list.html", "List|{{ .Title }}",
+		"layouts/_default/unused.html", "Unused",
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	var problems []string
+	for _, p := range h.CheckTemplates() {
+		problems = append(problems, p.String())
+	}
+
+	assert.Equal([]string{
+		`_default/single.html:1: param "nosuch" is not set in any front matter or in the site params`,
+		`_default/single.html:1: param "size" is not set in any front matter`,
+		`_default/single.html:1: partial "nope.html" not found`,
+		`_default/unused.html: layout is not used by any page`,
+		`content/sect/p1.md:8: shortcode "missing" not found`,
+	}, problems)
+}
+
+func TestShortcodeIndex(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range []struct {
+		content string
+		name    string
+		expect  int
+	}{
+		{"{{< sc >}}", "sc", 0},
+		{"a {{% sc %}}", "sc", 2},
+		{"{{<sc>}}", "sc", 0},
+		{"{{< scx >}} {{<  sc\n>}}", "sc", 12},
+		{"{{ sc }} {{< /sc >}}", "sc", -1},
+		{"{{< sc", "sc", -1},
+		{"{{<", "sc", -1},
+		{"", "sc", -1},
+	} {
+		require.Equal(t, test.expect, shortcodeIndex([]byte(test.content), test.name), "[%d] %s", i, test.content)
+	}
+}
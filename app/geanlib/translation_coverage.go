@@ -0,0 +1,73 @@
+package geanlib
+
+import "sort"
+
+// ContentCoverage is the coverage of the content translated to a language.
+type ContentCoverage struct {
+	Lang string `json:"lang"`
+
+	// The number of distinct content pages, in any language, see
+	// Page.TranslationKey.
+	Total int `json:"total"`
+
+	// The content files of the pages not translated to this language, in
+	// the default language if available, sorted.
+	Missing []string `json:"missing"`
+}
+
+// Translated returns the number of content pages translated to the
+// language.
+func (c *ContentCoverage) Translated() int {
+	return c.Total - len(c.Missing)
+}
+
+// ContentCoverage returns the content coverage of every language, in the
+// order of the sites.
+func (h *HugoSites) ContentCoverage() []*ContentCoverage {
+	var pages Pages
+	for _, p := range h.Pages() {
+		if p.File.Path() != "" {
+			pages = append(pages, p)
+		}
+	}
+
+	translations := pagesToTranslationsMap(pages)
+	defaultLang := h.multilingual.DefaultLang.Lang
+
+	var coverage []*ContentCoverage
+
+	for _, s := range h.Sites {
+		lang := s.Language.Lang
+		c := &ContentCoverage{Lang: lang, Total: len(translations)}
+
+		for _, t := range translations {
+			if _, found := t[lang]; found {
+				continue
+			}
+			c.Missing = append(c.Missing, translationSource(t, defaultLang).sourceDescription())
+		}
+
+		sort.Strings(c.Missing)
+
+		coverage = append(coverage, c)
+	}
+
+	return coverage
+}
+
+// translationSource returns the page in t to refer to when a translation
+// is missing: the one in the default language if any, else the first one
+// by language weight.
+func translationSource(t Translations, defaultLang string) *Page {
+	if p, found := t[defaultLang]; found {
+		return p
+	}
+
+	pages := make(Pages, 0, len(t))
+	for _, p := range t {
+		pages = append(pages, p)
+	}
+	pageBy(languagePageSort).Sort(pages)
+
+	return pages[0]
+}
@@ -0,0 +1,59 @@
+package geanlib
+
+import (
+	"testing"
+
+	"github.com/govenue/fsintra"
+	"github.com/govenue/require"
+)
+
+func TestContentCoverage(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	siteConfig := `
+baseURL = "http://example.com/"
+defaultContentLanguage = "en"
+disableKinds = ["sitemap", "robotsTXT", "404", "RSS", "taxonomy", "taxonomyTerm"]
+
+[Languages]
+[Languages.en]
+weight = 10
+[Languages.nn]
+weight = 20
+[Languages.de]
+weight = 30
+`
+
+	mf := fsintra.NewMemMapFs()
+	writeToFs(t, mf, "content/a.md", "---\ntitle: A\n---\nA")
+	writeToFs(t, mf, "content/a.nn.md", "---\ntitle: A\n---\nA")
+	writeToFs(t, mf, "content/a.de.md", "---\ntitle: A\n---\nA")
+	writeToFs(t, mf, "content/b.md", "---\ntitle: B\n---\nB")
+	writeToFs(t, mf, "content/c.nn.md", "---\ntitle: C\n---\nC")
+	writeToFs(t, mf, "content/c.de.md", "---\ntitle: C\n---\nC")
+
+	_, h := newTestSitesFromConfig(t, mf, siteConfig,
+		"layouts/_default/single.html", "Single|{{ .Title }}",
+		"layouts/_default/list.html", "List|{{ .Title }}",
+	)
+
+	assert.NoError(h.Build(BuildCfg{}))
+
+	coverage := h.ContentCoverage()
+	assert.Len(coverage, 3)
+
+	en, nn, de := coverage[0], coverage[1], coverage[2]
+
+	assert.Equal("en", en.Lang)
+	assert.Equal(3, en.Total)
+	assert.Equal([]string{"content/c.nn.md"}, en.Missing)
+
+	assert.Equal("nn", nn.Lang)
+	assert.Equal(2, nn.Translated())
+	assert.Equal([]string{"content/b.md"}, nn.Missing)
+
+	assert.Equal("de", de.Lang)
+	assert.Equal([]string{"content/b.md"}, de.Missing)
+}
@@ -10,6 +10,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/geego/gean/app/config"
+	"github.com/geego/gean/app/security"
 	"github.com/govenue/encoding/markdown"
 	"github.com/govenue/goorgeous"
 	"github.com/govenue/mapstructure"
@@ -33,17 +34,32 @@ type ContentSpec struct {
 	Highlight            func(code, lang, optsStr string) (string, error)
 	defatultPygmentsOpts map[string]string
 
+	// The security policy restricting the external helpers.
+	security *security.Policy
+
 	cfg config.Provider
 }
 
 // NewContentSpec returns a ContentSpec initialized
 // with the appropriate fields from the given config.Provider.
 func NewContentSpec(cfg config.Provider) (*ContentSpec, error) {
+	policy, err := security.DecodeConfig(cfg.Get("security"))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewContentSpecWithSecurity(cfg, policy)
+}
+
+// NewContentSpecWithSecurity returns a ContentSpec like NewContentSpec, but
+// with the given, already decoded, security policy.
+func NewContentSpecWithSecurity(cfg config.Provider, policy *security.Policy) (*ContentSpec, error) {
 	spec := &ContentSpec{
 		markdown:                   cfg.GetStringMap("markdown"),
 		footnoteAnchorPrefix:       cfg.GetString("footnoteAnchorPrefix"),
 		footnoteReturnLinkContents: cfg.GetString("footnoteReturnLinkContents"),
 		summaryLength:              cfg.GetInt("summaryLength"),
+		security:                   policy,
 
 		cfg: cfg,
 	}
@@ -430,15 +446,15 @@ func (c ContentSpec) RenderBytes(ctx *RenderingContext) []byte {
 	case "markdown":
 		return c.markdownRender(ctx)
 	case "asciidoc":
-		return getAsciidocContent(ctx)
+		return c.getAsciidocContent(ctx)
 	case "mmark":
 		return c.mmarkRender(ctx)
 	case "rst":
-		return getRstContent(ctx)
+		return c.getRstContent(ctx)
 	case "org":
 		return orgRender(ctx, c)
 	case "pandoc":
-		return getPandocContent(ctx)
+		return c.getPandocContent(ctx)
 	}
 }
 
@@ -579,10 +595,12 @@ func HasAsciidoc() bool {
 
 // getAsciidocContent calls asciidoctor or asciidoc as an external helper
 // to convert AsciiDoc content to HTML.
-func getAsciidocContent(ctx *RenderingContext) []byte {
+func (c ContentSpec) getAsciidocContent(ctx *RenderingContext) []byte {
 	var isAsciidoctor bool
+	name := "asciidoctor"
 	path := getAsciidoctorExecPath()
 	if path == "" {
+		name = "asciidoc"
 		path = getAsciidocExecPath()
 		if path == "" {
 			notepad.ERROR.Println("asciidoctor / asciidoc not found in $PATH: Please install.\n",
@@ -600,7 +618,7 @@ func getAsciidocContent(ctx *RenderingContext) []byte {
 		args = append(args, "--trace")
 	}
 	args = append(args, "-")
-	return externallyRenderContent(ctx, path, args)
+	return c.externallyRenderContent(ctx, name, path, args)
 }
 
 // HasRst returns whether rst2html is installed on this computer.
@@ -632,7 +650,7 @@ func getPythonExecPath() string {
 
 // getRstContent calls the Python script rst2html as an external helper
 // to convert reStructuredText content to HTML.
-func getRstContent(ctx *RenderingContext) []byte {
+func (c ContentSpec) getRstContent(ctx *RenderingContext) []byte {
 	python := getPythonExecPath()
 	path := getRstExecPath()
 
@@ -644,7 +662,7 @@ func getRstContent(ctx *RenderingContext) []byte {
 	}
 	notepad.INFO.Println("Rendering", ctx.DocumentName, "with", path, "...")
 	args := []string{path, "--leave-comments", "--initial-header-level=2"}
-	result := externallyRenderContent(ctx, python, args)
+	result := c.externallyRenderContent(ctx, "rst2html", python, args)
 	// TODO(bep) check if rst2html has a body only option.
 	bodyStart := bytes.Index(result, []byte("<body>\n"))
 	if bodyStart < 0 {
@@ -663,7 +681,7 @@ func getRstContent(ctx *RenderingContext) []byte {
 }
 
 // getPandocContent calls pandoc as an external helper to convert pandoc markdown to HTML.
-func getPandocContent(ctx *RenderingContext) []byte {
+func (c ContentSpec) getPandocContent(ctx *RenderingContext) []byte {
 	path, err := exec.LookPath("pandoc")
 	if err != nil {
 		notepad.ERROR.Println("pandoc not found in $PATH: Please install.\n",
@@ -671,7 +689,7 @@ func getPandocContent(ctx *RenderingContext) []byte {
 		return ctx.Content
 	}
 	args := []string{"--mathjax"}
-	return externallyRenderContent(ctx, path, args)
+	return c.externallyRenderContent(ctx, "pandoc", path, args)
 }
 
 func orgRender(ctx *RenderingContext, c ContentSpec) []byte {
@@ -681,7 +699,16 @@ func orgRender(ctx *RenderingContext, c ContentSpec) []byte {
 		c.getHTMLRenderer(markdown.HTML_TOC, ctx))
 }
 
-func externallyRenderContent(ctx *RenderingContext, path string, args []string) []byte {
+// externallyRenderContent renders the content with the named external helper,
+// running the executable at path with the given args, if the security policy
+// allows it. Otherwise the content is left unrendered.
+func (c ContentSpec) externallyRenderContent(ctx *RenderingContext, name, path string, args []string) []byte {
+	if err := c.security.CheckExec(name); err != nil {
+		notepad.ERROR.Printf("%s rendering %s: %s\n"+
+			"                 Leaving the content unrendered.", name, ctx.DocumentName, err)
+		return ctx.Content
+	}
+
 	content := ctx.Content
 	cleanContent := bytes.Replace(content, SummaryDivider, []byte(""), 1)
 
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/govenue/assert"
+	"github.com/govenue/configurator"
 	"github.com/govenue/encoding/markdown"
 	"github.com/govenue/mmark"
 )
@@ -367,6 +368,26 @@ func TestMmarkRender(t *testing.T) {
 	}
 }
 
+func TestExternallyRenderContentSecurity(t *testing.T) {
+	v := configurator.New()
+	v.Set("security", map[string]interface{}{"exec": []interface{}{"asciidoctor"}})
+
+	c, err := NewContentSpec(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &RenderingContext{Cfg: c.cfg, Content: []byte("testContent"), DocumentName: "post/doc.pdc"}
+	if actual := c.externallyRenderContent(ctx, "pandoc", "/no/such/pandoc", nil); !bytes.Equal(actual, ctx.Content) {
+		t.Errorf("Expected unrendered content, got %q", actual)
+	}
+
+	v.Set("security", "all")
+	if _, err := NewContentSpec(v); err == nil {
+		t.Error("Expected error for invalid security config")
+	}
+}
+
 func TestExtractTOCNormalContent(t *testing.T) {
 	content := []byte("<nav>\n<ul>\nTOC<li><a href=\"#")
 
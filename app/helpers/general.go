@@ -138,6 +138,34 @@ func ToLowerMap(m map[string]interface{}) {
 	}
 }
 
+// StringifyMapKeys returns the given value with any map[interface{}]interface{},
+// nested in maps and slices too, converted to map[string]interface{}, e.g. for
+// YAML decoded data to be encoded as JSON or TOML. The given value is left
+// unchanged.
+func StringifyMapKeys(in interface{}) interface{} {
+	switch v := in.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, vv := range v {
+			m[assist.ToString(k)] = StringifyMapKeys(vv)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, vv := range v {
+			m[k] = StringifyMapKeys(vv)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, vv := range v {
+			l[i] = StringifyMapKeys(vv)
+		}
+		return l
+	}
+	return in
+}
+
 // ReaderToString is the same as ReaderToBytes, but returns a string.
 func ReaderToString(lines io.Reader) string {
 	if lines == nil {
@@ -257,3 +257,38 @@ func TestToLowerMap(t *testing.T) {
 		}
 	}
 }
+
+func TestStringifyMapKeys(t *testing.T) {
+
+	tests := []struct {
+		input    interface{}
+		expected interface{}
+	}{
+		{"a", "a"},
+		{
+			map[interface{}]interface{}{
+				1:   "A value",
+				"b": []interface{}{map[interface{}]interface{}{"c": true}, 2},
+			},
+			map[string]interface{}{
+				"1": "A value",
+				"b": []interface{}{map[string]interface{}{"c": true}, 2},
+			},
+		},
+		{
+			map[string]interface{}{
+				"a": map[interface{}]interface{}{"b": map[interface{}]interface{}{3: 4}},
+			},
+			map[string]interface{}{
+				"a": map[string]interface{}{"b": map[string]interface{}{"3": 4}},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		result := StringifyMapKeys(test.input)
+		if !reflect.DeepEqual(test.expected, result) {
+			t.Errorf("[%d] Expected\n%#v, got\n%#v\n", i, test.expected, result)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2017 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Coverage is the coverage of the i18n bundle for a language compared to
+// the bundle for the default content language.
+type Coverage struct {
+	Lang string `json:"lang"`
+
+	// The number of translation IDs in the default language.
+	Total int `json:"total"`
+
+	// The IDs in the default language not translated to this language.
+	Missing []string `json:"missing"`
+
+	// The IDs with the same translation as in the default language, most
+	// likely copied but never translated.
+	Identical []string `json:"identical"`
+
+	// The IDs in this language never looked up while rendering.
+	Unused []string `json:"unused"`
+}
+
+// Translated returns the number of IDs in the default language translated
+// to this language.
+func (c *Coverage) Translated() int {
+	return c.Total - len(c.Missing)
+}
+
+// Coverage compares the i18n bundles for the given languages to the bundle
+// for defaultLang. The IDs are reported as unused if not looked up since the
+// bundles were loaded, so this is only meaningful after a full build.
+func (tp *TranslationProvider) Coverage(defaultLang string, langs []string) []*Coverage {
+	if tp.t.bundle == nil {
+		return nil
+	}
+
+	translations := tp.t.bundle.Translations()
+	defaults := translations[defaultLang]
+
+	var coverage []*Coverage
+
+	for _, lang := range langs {
+		c := &Coverage{Lang: lang, Total: len(defaults)}
+		current := translations[lang]
+
+		for id, d := range defaults {
+			t, found := current[id]
+			if !found {
+				c.Missing = append(c.Missing, id)
+			} else if lang != defaultLang && reflect.DeepEqual(t.MarshalInterface(), d.MarshalInterface()) {
+				c.Identical = append(c.Identical, id)
+			}
+		}
+
+		for id := range current {
+			if !tp.t.used.contains(id) {
+				c.Unused = append(c.Unused, id)
+			}
+		}
+
+		sort.Strings(c.Missing)
+		sort.Strings(c.Identical)
+		sort.Strings(c.Unused)
+
+		coverage = append(coverage, c)
+	}
+
+	return coverage
+}
@@ -0,0 +1,75 @@
+// Copyright 2017 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/geego/gean/app/deps"
+	"github.com/geego/gean/app/geanfs"
+	"github.com/govenue/configurator"
+	"github.com/govenue/fsintra"
+	"github.com/govenue/require"
+)
+
+func TestCoverage(t *testing.T) {
+	assert := require.New(t)
+
+	v := configurator.New()
+	v.SetDefault("defaultContentLanguage", "en")
+
+	fs := geanfs.NewMem(v)
+	tp := NewTranslationProvider()
+
+	assert.Nil(tp.Coverage("en", []string{"en"}))
+
+	d, err := deps.New(newDepsConfig(tp, v, fs))
+	assert.NoError(err)
+
+	for file, content := range map[string]string{
+		"en.toml": "[hello]\nother = \"Hello\"\n[goodbye]\nother = \"Goodbye\"\n[ok]\nother = \"OK\"\n",
+		"nn.toml": "[hello]\nother = \"Hei\"\n[ok]\nother = \"OK\"\n[old]\nother = \"Gamal\"\n",
+	} {
+		assert.NoError(fsintra.WriteFile(fs.Source, filepath.Join("i18n", file), []byte(content), 0755))
+	}
+
+	assert.NoError(d.LoadResources())
+
+	for _, lang := range []string{"en", "nn"} {
+		f := tp.t.Func(lang)
+		f("hello")
+		f("ok")
+	}
+
+	coverage := tp.Coverage("en", []string{"en", "nn", "de"})
+	assert.Len(coverage, 3)
+
+	en, nn, de := coverage[0], coverage[1], coverage[2]
+
+	assert.Equal("en", en.Lang)
+	assert.Equal(3, en.Total)
+	assert.Len(en.Missing, 0)
+	assert.Len(en.Identical, 0)
+	assert.Equal([]string{"goodbye"}, en.Unused)
+
+	assert.Equal(2, nn.Translated())
+	assert.Equal([]string{"goodbye"}, nn.Missing)
+	assert.Equal([]string{"ok"}, nn.Identical)
+	assert.Equal([]string{"old"}, nn.Unused)
+
+	assert.Equal(0, de.Translated())
+	assert.Equal([]string{"goodbye", "hello", "ok"}, de.Missing)
+	assert.Len(de.Unused, 0)
+}
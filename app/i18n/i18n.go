@@ -14,6 +14,8 @@
 package i18n
 
 import (
+	"sync"
+
 	"github.com/geego/gean/app/config"
 	"github.com/geego/gean/app/helpers"
 	"github.com/govenue/notepad"
@@ -29,11 +31,39 @@ type Translator struct {
 	translateFuncs map[string]bundle.TranslateFunc
 	cfg            config.Provider
 	logger         *notepad.Notepad
+
+	bundle *bundle.Bundle
+
+	// The translation IDs looked up, in any language.
+	used *usedIDs
+}
+
+type usedIDs struct {
+	mu  sync.Mutex
+	ids map[string]bool
+}
+
+func (u *usedIDs) add(id string) {
+	u.mu.Lock()
+	u.ids[id] = true
+	u.mu.Unlock()
+}
+
+func (u *usedIDs) contains(id string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.ids[id]
 }
 
 // NewTranslator creates a new Translator for the given language bundle and configuration.
 func NewTranslator(b *bundle.Bundle, cfg config.Provider, logger *notepad.Notepad) Translator {
-	t := Translator{cfg: cfg, logger: logger, translateFuncs: make(map[string]bundle.TranslateFunc)}
+	t := Translator{
+		cfg:            cfg,
+		logger:         logger,
+		translateFuncs: make(map[string]bundle.TranslateFunc),
+		bundle:         b,
+		used:           &usedIDs{ids: make(map[string]bool)},
+	}
 	t.initFuncs(b)
 	return t
 }
@@ -68,6 +98,8 @@ func (t Translator) initFuncs(bndl *bundle.Bundle) {
 		currentLang := lang
 
 		t.translateFuncs[currentLang] = func(translationID string, args ...interface{}) string {
+			t.used.add(translationID)
+
 			tFunc, err := bndl.Tfunc(currentLang)
 			if err != nil {
 				notepad.WARN.Printf("could not load translations for language %q (%s), will use default content language.\n", lang, err)
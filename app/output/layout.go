@@ -41,6 +41,8 @@ func NewLayoutHandler(hasTheme bool) *LayoutHandler {
 // Section: "section/" + section + ".rss.xml", "_default/rss.xml", "rss.xml", "_internal/_default/rss.xml"
 // Taxonomy "taxonomy/" + singular + ".rss.xml", "_default/rss.xml", "rss.xml", "_internal/_default/rss.xml"
 // Tax term: taxonomy/" + singular + ".terms.rss.xml", "_default/rss.xml", "rss.xml", "_internal/_default/rss.xml"
+// Archive: "archive/" + section + ".rss.xml", "_default/rss.xml", "rss.xml", "_internal/_default/rss.xml"
+// Series: "series/" + series + ".rss.xml", "_default/rss.xml", "rss.xml", "_internal/_default/rss.xml"
 
 const (
 
@@ -51,6 +53,8 @@ const (
 	layoutsRSSSection      = `section/SECTION.VARIATIONS _default/VARIATIONS VARIATIONS _internal/_default/rss.xml`
 	layoutsRSSTaxonomy     = `taxonomy/SECTION.VARIATIONS _default/VARIATIONS VARIATIONS _internal/_default/rss.xml`
 	layoutsRSSTaxonomyTerm = `taxonomy/SECTION.terms.VARIATIONS _default/VARIATIONS VARIATIONS _internal/_default/rss.xml`
+	layoutsRSSArchive      = `archive/SECTION.VARIATIONS _default/VARIATIONS VARIATIONS _internal/_default/rss.xml`
+	layoutsRSSSeries       = `series/SECTION.VARIATIONS _default/VARIATIONS VARIATIONS _internal/_default/rss.xml`
 
 	layoutsHome    = "index.VARIATIONS _default/list.VARIATIONS"
 	layoutsSection = `
@@ -71,6 +75,17 @@ _default/list.VARIATIONS
 taxonomy/SECTION.terms.VARIATIONS
 _default/terms.VARIATIONS
 indexes/indexes.VARIATIONS
+`
+	layoutsArchive = `
+archive/SECTION.VARIATIONS
+SECTION/archive.VARIATIONS
+_default/archive.VARIATIONS
+_default/list.VARIATIONS
+`
+	layoutsSeries = `
+series/SECTION.VARIATIONS
+_default/series.VARIATIONS
+_default/list.VARIATIONS
 `
 )
 
@@ -112,13 +127,17 @@ func (l *LayoutHandler) For(d LayoutDescriptor, layoutOverride string, f Format)
 				layoutsRSSHome,
 				layoutsRSSSection,
 				layoutsRSSTaxonomy,
-				layoutsRSSTaxonomyTerm)
+				layoutsRSSTaxonomyTerm,
+				layoutsRSSArchive,
+				layoutsRSSSeries)
 		} else {
 			layouts = resolveListTemplate(d, f,
 				layoutsHome,
 				layoutsSection,
 				layoutsTaxonomy,
-				layoutsTaxonomyTerm)
+				layoutsTaxonomyTerm,
+				layoutsArchive,
+				layoutsSeries)
 		}
 	}
 
@@ -161,7 +180,9 @@ func resolveListTemplate(d LayoutDescriptor, f Format,
 	homeLayouts,
 	sectionLayouts,
 	taxonomyLayouts,
-	taxonomyTermLayouts string) []string {
+	taxonomyTermLayouts,
+	archiveLayouts,
+	seriesLayouts string) []string {
 	var layouts []string
 
 	switch d.Kind {
@@ -173,6 +194,10 @@ func resolveListTemplate(d LayoutDescriptor, f Format,
 		layouts = resolveTemplate(taxonomyLayouts, d, f)
 	case "taxonomyTerm":
 		layouts = resolveTemplate(taxonomyTermLayouts, d, f)
+	case "archive":
+		layouts = resolveTemplate(archiveLayouts, d, f)
+	case "series":
+		layouts = resolveTemplate(seriesLayouts, d, f)
 	}
 	return layouts
 }
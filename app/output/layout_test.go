@@ -59,6 +59,10 @@ func TestLayout(t *testing.T) {
 			[]string{"taxonomy/tag.amp.html", "taxonomy/tag.html"}},
 		{"Taxonomy term", LayoutDescriptor{Kind: "taxonomyTerm", Section: "categories"}, false, "", ampType,
 			[]string{"taxonomy/categories.terms.amp.html", "taxonomy/categories.terms.html", "_default/terms.amp.html"}},
+		{"Archive", LayoutDescriptor{Kind: "archive", Section: "episodes"}, false, "", ampType,
+			[]string{"archive/episodes.amp.html", "archive/episodes.html", "episodes/archive.amp.html", "episodes/archive.html", "_default/archive.amp.html", "_default/archive.html", "_default/list.amp.html"}},
+		{"Series", LayoutDescriptor{Kind: "series", Section: "my-series"}, false, "", ampType,
+			[]string{"series/my-series.amp.html", "series/my-series.html", "_default/series.amp.html", "_default/series.html", "_default/list.amp.html"}},
 		{"Page", LayoutDescriptor{Kind: "page"}, true, "", ampType,
 			[]string{"_default/single.amp.html", "_default/single.html", "theme/_default/single.amp.html"}},
 		{"Page with layout", LayoutDescriptor{Kind: "page", Layout: "mylayout"}, false, "", ampType,
@@ -78,6 +82,10 @@ func TestLayout(t *testing.T) {
 			[]string{"taxonomy/tag.rss.xml", "_default/rss.xml", "rss.xml", "_internal/_default/rss.xml"}},
 		{"RSS Taxonomy term", LayoutDescriptor{Kind: "taxonomyTerm", Section: "tag"}, false, "", RSSFormat,
 			[]string{"taxonomy/tag.terms.rss.xml", "_default/rss.xml", "rss.xml", "_internal/_default/rss.xml"}},
+		{"RSS Archive", LayoutDescriptor{Kind: "archive", Section: "episodes"}, false, "", RSSFormat,
+			[]string{"archive/episodes.rss.xml", "_default/rss.xml", "rss.xml", "_internal/_default/rss.xml"}},
+		{"RSS Series", LayoutDescriptor{Kind: "series", Section: "my-series"}, false, "", RSSFormat,
+			[]string{"series/my-series.rss.xml", "_default/rss.xml", "rss.xml", "_internal/_default/rss.xml"}},
 		{"Home plain text", LayoutDescriptor{Kind: "home"}, true, "", JSONFormat,
 			[]string{"_text/index.json.json", "_text/index.json", "_text/_default/list.json.json", "_text/_default/list.json", "_text/theme/index.json.json", "_text/theme/index.json"}},
 		{"Page plain text", LayoutDescriptor{Kind: "page"}, true, "", JSONFormat,
@@ -117,6 +117,20 @@ var (
 		NoUgly:    true,
 		Rel:       "alternate",
 	}
+
+	// SearchIndexFormat is a JSON index of the pages listed for client side
+	// search. It is rendered without a template, and is not one of the
+	// DefaultFormats as it shares its suffix with JSONFormat. It is enabled
+	// in the site outputs config.
+	SearchIndexFormat = Format{
+		Name:           "SearchIndex",
+		MediaType:      media.JSONType,
+		BaseName:       "searchindex",
+		IsPlainText:    true,
+		NoUgly:         true,
+		Rel:            "search",
+		NotAlternative: true,
+	}
 )
 
 var DefaultFormats = Formats{
@@ -51,6 +51,12 @@ func TestDefaultTypes(t *testing.T) {
 	require.True(t, RSSFormat.NoUgly)
 	require.False(t, CalendarFormat.IsHTML)
 
+	require.Equal(t, "SearchIndex", SearchIndexFormat.Name)
+	require.Equal(t, media.JSONType, SearchIndexFormat.MediaType)
+	require.Equal(t, "searchindex", SearchIndexFormat.BaseName)
+	require.True(t, SearchIndexFormat.IsPlainText)
+	require.True(t, SearchIndexFormat.NotAlternative)
+
 }
 
 func TestGetFormatByName(t *testing.T) {
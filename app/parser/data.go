@@ -0,0 +1,187 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/geego/gean/app/helpers"
+	"github.com/govenue/encoding/yaml"
+)
+
+// UnmarshalData decodes the given data in the given format, one of json, yaml,
+// toml, xml, csv and tsv, or in the format detected by DetectDataFormat if
+// empty.
+//
+// Any map[interface{}]interface{} in YAML data is converted to
+// map[string]interface{}. CSV and TSV data is returned as a list of records,
+// each a list of fields. XML elements become maps keyed by the child element
+// names, with the attributes prefixed with a dash and any text next to child
+// elements in "#text".
+func UnmarshalData(b []byte, format string) (interface{}, error) {
+	if format == "" {
+		format = DetectDataFormat(b)
+	}
+
+	var (
+		v   interface{}
+		err error
+	)
+
+	switch strings.ToLower(format) {
+	case "json":
+		v, err = HandleJSONMetaData(b)
+	case "yaml", "yml":
+		v, err = HandleYAMLMetaData(b)
+		if err != nil {
+			// Not a map, try a list or a plain value.
+			var l interface{}
+			if yaml.Unmarshal(b, &l) == nil {
+				v, err = l, nil
+			}
+		}
+		v = helpers.StringifyMapKeys(v)
+	case "toml":
+		v, err = HandleTOMLMetaData(b)
+	case "xml":
+		v, err = unmarshalXML(b)
+	case "csv":
+		v, err = unmarshalCSV(b, ',')
+	case "tsv":
+		v, err = unmarshalCSV(b, '\t')
+	default:
+		return nil, fmt.Errorf("unsupported unmarshal format %q", format)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %s", format, err)
+	}
+
+	return v, nil
+}
+
+var (
+	tomlKeyRe = regexp.MustCompile(`^[\w."'-]+\s*=`)
+	yamlKeyRe = regexp.MustCompile(`^(-(\s|$)|[\w."' -]+:(\s|$))`)
+)
+
+// DetectDataFormat guesses the format of the given data from its start, see
+// UnmarshalData.
+func DetectDataFormat(b []byte) string {
+	s := strings.TrimSpace(string(b))
+
+	switch {
+	case strings.HasPrefix(s, "{"):
+		return "json"
+	case strings.HasPrefix(s, "["):
+		// A JSON list or a TOML table.
+		if json.Valid([]byte(s)) {
+			return "json"
+		}
+		return "toml"
+	case strings.HasPrefix(s, "<"):
+		return "xml"
+	case strings.HasPrefix(s, "+++"):
+		return "toml"
+	case strings.HasPrefix(s, "---"):
+		return "yaml"
+	}
+
+	// The first line that is not blank or a comment.
+	var line string
+	for _, l := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(l)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			break
+		}
+	}
+
+	switch {
+	case tomlKeyRe.MatchString(line):
+		return "toml"
+	case yamlKeyRe.MatchString(line):
+		return "yaml"
+	case strings.Contains(line, "\t"):
+		return "tsv"
+	case strings.Contains(line, ","):
+		return "csv"
+	}
+
+	return "yaml"
+}
+
+func unmarshalCSV(b []byte, comma rune) ([][]string, error) {
+	r := csv.NewReader(bytes.NewReader(b))
+	r.Comma = comma
+	r.FieldsPerRecord = 0
+	return r.ReadAll()
+}
+
+func unmarshalXML(b []byte) (interface{}, error) {
+	d := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			v, err := unmarshalXMLElement(d, start)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{start.Name.Local: v}, nil
+		}
+	}
+}
+
+// unmarshalXMLElement returns the element's text if it has neither attributes
+// nor child elements, else a map.
+func unmarshalXMLElement(d *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	var (
+		m    = make(map[string]interface{})
+		text bytes.Buffer
+	)
+
+	for _, attr := range start.Attr {
+		m["-"+attr.Name.Local] = attr.Value
+	}
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			v, err := unmarshalXMLElement(d, t)
+			if err != nil {
+				return nil, err
+			}
+			name := t.Name.Local
+			switch existing := m[name].(type) {
+			case nil:
+				m[name] = v
+			case []interface{}:
+				m[name] = append(existing, v)
+			default:
+				m[name] = []interface{}{existing, v}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			s := strings.TrimSpace(text.String())
+			if len(m) == 0 {
+				return s, nil
+			}
+			if s != "" {
+				m["#text"] = s
+			}
+			return m, nil
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectDataFormat(t *testing.T) {
+	cases := []struct {
+		data string
+		want string
+	}{
+		{`{"a": 1}`, "json"},
+		{`["a", "b"]`, "json"},
+		{"[params]\nweight = 2", "toml"},
+		{"# A comment\ntitle = \"Gean\"", "toml"},
+		{"+++\ntitle = \"Gean\"\n+++", "toml"},
+		{"title: Gean", "yaml"},
+		{"---\ntitle: Gean", "yaml"},
+		{"- a\n- b", "yaml"},
+		{"<?xml version=\"1.0\"?><a/>", "xml"},
+		{"a,b\n1,2", "csv"},
+		{"a\tb\n1\t2", "tsv"},
+		{"", "yaml"},
+	}
+
+	for i, c := range cases {
+		got := DetectDataFormat([]byte(c.data))
+		if got != c.want {
+			t.Errorf("[%d] got %q, want %q", i, got, c.want)
+		}
+	}
+}
+
+func TestUnmarshalData(t *testing.T) {
+	cases := []struct {
+		data   string
+		format string
+		want   interface{}
+		isErr  bool
+	}{
+		{"a,b\n1,2", "", [][]string{{"a", "b"}, {"1", "2"}}, false},
+		{"a;b", "csv", [][]string{{"a;b"}}, false},
+		{"a:\n  b: 1", "YAML", map[string]interface{}{"a": map[string]interface{}{"b": 1}}, false},
+		{"- a", "", []interface{}{"a"}, false},
+		{`<a x="1"><b>c</b><b>d</b>e</a>`, "", map[string]interface{}{"a": map[string]interface{}{
+			"-x": "1", "b": []interface{}{"c", "d"}, "#text": "e"}}, false},
+
+		// Errors
+		{"a = 1", "ini", nil, true},
+		{"<a><b></a>", "xml", nil, true},
+		{"", "xml", nil, true},
+		{"{", "json", nil, true},
+	}
+
+	for i, c := range cases {
+		got, err := UnmarshalData([]byte(c.data), c.format)
+		if err != nil {
+			if c.isErr {
+				continue
+			}
+			t.Fatalf("[%d] unexpected error value: %v", i, err)
+		}
+
+		if c.isErr {
+			t.Errorf("[%d] expected an error", i)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("[%d] not equal:\nwant %#v,\n got %#v", i, c.want, got)
+		}
+	}
+}
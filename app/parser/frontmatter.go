@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"regexp"
 	"strings"
 
 	"github.com/govenue/goorgeous"
@@ -224,3 +225,40 @@ func HandleJSONMetaData(datum []byte) (interface{}, error) {
 func HandleOrgMetaData(datum []byte) (interface{}, error) {
 	return goorgeous.OrgHeaders(datum)
 }
+
+// FrontMatterKeyLine returns the 1-based line of the top level key in the
+// given frontmatter, delimiters included, matched case insensitively. It
+// returns 0 if the key isn't found.
+func FrontMatterKeyLine(frontmatter []byte, key string) int {
+	if len(frontmatter) == 0 {
+		return 0
+	}
+
+	k := regexp.QuoteMeta(key)
+
+	var re *regexp.Regexp
+
+	switch frontmatter[0] {
+	case YAMLLead[0]:
+		re = regexp.MustCompile(`(?im)^["']?` + k + `["']?[ \t]*:`)
+	case TOMLLead[0]:
+		// Only keys before the first table are top level.
+		if loc := regexp.MustCompile(`(?m)^[ \t]*\[`).FindIndex(frontmatter); loc != nil {
+			frontmatter = frontmatter[:loc[0]]
+		}
+		re = regexp.MustCompile(`(?im)^[ \t]*["']?` + k + `["']?[ \t]*=`)
+	case JSONLead[0]:
+		re = regexp.MustCompile(`(?i)"` + k + `"\s*:`)
+	case '#':
+		re = regexp.MustCompile(`(?im)^#\+` + k + `:`)
+	default:
+		return 0
+	}
+
+	loc := re.FindIndex(frontmatter)
+	if loc == nil {
+		return 0
+	}
+
+	return bytes.Count(frontmatter[:loc[0]], []byte("\n")) + 1
+}
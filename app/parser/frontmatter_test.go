@@ -396,3 +396,27 @@ tags = %s
 		}
 	})
 }
+
+func TestFrontMatterKeyLine(t *testing.T) {
+	cases := []struct {
+		frontmatter string
+		key         string
+		want        int
+	}{
+		{"---\ntitle: a\nepisode: 3\n---\n", "episode", 3},
+		{"---\ntitle: a\nEpisode: 3\n---\n", "episode", 3},
+		{"---\ntitle: a\n---\n", "episode", 0},
+		{"---\ntitle: a\nparams:\n  episode: 3\n---\n", "episode", 0},
+		{"+++\ntitle = \"a\"\nepisode = 3\n+++\n", "episode", 3},
+		{"+++\ntitle = \"a\"\n[params]\nepisode = 3\n+++\n", "episode", 0},
+		{"{\n\"title\": \"a\",\n\"episode\": 3\n}\n", "episode", 3},
+		{"#+TITLE: a\n#+episode: 3\n", "episode", 2},
+	}
+
+	for i, c := range cases {
+		res := FrontMatterKeyLine([]byte(c.frontmatter), c.key)
+		if res != c.want {
+			t.Errorf("[%d] FrontMatterKeyLine(%q, %q): expected %d, got %d", i, c.frontmatter, c.key, c.want, res)
+		}
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"regexp"
 	"strings"
 	"unicode"
@@ -75,13 +76,18 @@ type Page interface {
 
 	// Metadata returns the unmarshalled frontmatter data.
 	Metadata() (interface{}, error)
+
+	// FrontMatterLine returns the 1-based line in the source the frontmatter
+	// starts on, 0 if there is no frontmatter.
+	FrontMatterLine() int
 }
 
 // page implements the Page interface.
 type page struct {
-	render      bool
-	frontmatter []byte
-	content     []byte
+	render          bool
+	frontmatter     []byte
+	frontmatterLine int
+	content         []byte
 }
 
 // Content returns the raw page content.
@@ -94,6 +100,12 @@ func (p *page) FrontMatter() []byte {
 	return p.frontmatter
 }
 
+// FrontMatterLine returns the 1-based line in the source the frontmatter
+// starts on, 0 if there is no frontmatter.
+func (p *page) FrontMatterLine() int {
+	return p.frontmatterLine
+}
+
 // IsRenderable denotes that the page should be rendered.
 func (p *page) IsRenderable() bool {
 	return p.render
@@ -117,7 +129,13 @@ func (p *page) Metadata() (meta interface{}, err error) {
 
 // ReadFrom reads the content from an io.Reader and constructs a page.
 func ReadFrom(r io.Reader) (p Page, err error) {
-	reader := bufio.NewReader(r)
+	// Read it all up front to know the line the frontmatter starts on.
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return
+	}
+	sr := bytes.NewReader(src)
+	reader := bufio.NewReader(sr)
 
 	// chomp BOM and assume UTF-8
 	if err = chompBOM(reader); err != nil && err != io.EOF {
@@ -138,6 +156,10 @@ func ReadFrom(r io.Reader) (p Page, err error) {
 	newp := new(page)
 	newp.render = shouldRender(firstLine)
 
+	// What has been read so far is the BOM, white space and comments.
+	read := len(src) - sr.Len() - reader.Buffered()
+	fmLine := bytes.Count(src[:read], []byte("\n")) + 1
+
 	if newp.render && isFrontMatterDelim(firstLine) {
 		left, right := determineDelims(firstLine)
 		fm, err := extractFrontMatterDelims(reader, left, right)
@@ -145,12 +167,14 @@ func ReadFrom(r io.Reader) (p Page, err error) {
 			return nil, err
 		}
 		newp.frontmatter = fm
+		newp.frontmatterLine = fmLine
 	} else if newp.render && goorgeous.IsKeyword(firstLine) {
 		fm, err := goorgeous.ExtractOrgHeaders(reader)
 		if err != nil {
 			return nil, err
 		}
 		newp.frontmatter = fm
+		newp.frontmatterLine = fmLine
 	}
 
 	content, err := extractContent(reader)
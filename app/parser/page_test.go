@@ -83,6 +83,24 @@ func TestPage(t *testing.T) {
 	}
 }
 
+func TestPageFrontMatterLine(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want int
+	}{
+		{yamlPageFrontMatter + yamlPageContent, 1},
+		{"\n\n" + tomlPageFrontMatter + tomlPageContent, 3},
+		{testPageLeader + yamlPageFrontMatter + testPageTrailer + yamlPageContent, 4},
+		{"\n" + orgPageFrontMatter + orgPageContent, 2},
+		{yamlPageContent, 0},
+	}
+
+	for i, c := range cases {
+		p := pageMust(ReadFrom(strings.NewReader(c.raw)))
+		assert.Equal(t, c.want, p.FrontMatterLine(), fmt.Sprintf("[%d]", i))
+	}
+}
+
 var (
 	testWhitespace  = "\t\t\n\n"
 	testPageLeader  = "\ufeff" + testWhitespace + "<!--[metadata]>\n"
@@ -0,0 +1,190 @@
+// Package publish implements atomic publishing of a built site.
+//
+// With atomic publishing the publish dir holds one directory per build,
+// named after the time the build started, and a "current" symlink pointing
+// to the last successful build:
+//
+//	public/
+//	  20171019-101500/
+//	  20171019-113012/
+//	  current -> 20171019-113012
+//
+// The web server serves public/current. A new build is rendered into a new
+// directory and the symlink is switched only when the build succeeds, so
+// visitors never see a half-written site.
+package publish
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// CurrentLink is the name of the symlink pointing to the active build.
+const CurrentLink = "current"
+
+const buildTimeFormat = "20060102-150405"
+
+var buildNameRe = regexp.MustCompile(`^\d{8}-\d{6}(-\d+)?$`)
+
+// Build is a build directory in the publish dir.
+type Build struct {
+	Name    string
+	Dir     string
+	Current bool
+}
+
+// NewBuild creates a new, empty build directory in root, named after the
+// given time.
+func NewBuild(root string, t time.Time) (string, error) {
+	if err := os.MkdirAll(root, 0777); err != nil {
+		return "", err
+	}
+
+	name := t.Format(buildTimeFormat)
+	for i := 1; ; i++ {
+		dir := filepath.Join(root, name)
+		err := os.Mkdir(dir, 0777)
+		if err == nil {
+			return dir, nil
+		}
+		if !os.IsExist(err) {
+			return "", err
+		}
+		name = fmt.Sprintf("%s-%d", t.Format(buildTimeFormat), i)
+	}
+}
+
+// Current returns the name of the build the current symlink points to, or an
+// empty string if there is none.
+func Current(root string) (string, error) {
+	target, err := os.Readlink(filepath.Join(root, CurrentLink))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return filepath.Base(target), nil
+}
+
+// Activate atomically points the current symlink to the named build.
+func Activate(root, name string) error {
+	if !buildNameRe.MatchString(name) {
+		return fmt.Errorf("%q is not a build", name)
+	}
+
+	if fi, err := os.Stat(filepath.Join(root, name)); err != nil || !fi.IsDir() {
+		return fmt.Errorf("build %q not found in %q", name, root)
+	}
+
+	// Create the new link next to the old one and rename it in place.
+	// The rename replaces the old link atomically.
+	tmp := filepath.Join(root, "."+CurrentLink+".tmp")
+	os.Remove(tmp)
+
+	// The link is relative, so the publish dir can be moved.
+	if err := os.Symlink(name, tmp); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, filepath.Join(root, CurrentLink)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return nil
+}
+
+// List returns the builds in root, oldest first.
+func List(root string) ([]Build, error) {
+	f, err := os.Open(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	fis, err := f.Readdir(-1)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := Current(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var builds []Build
+	for _, fi := range fis {
+		if !fi.IsDir() || !buildNameRe.MatchString(fi.Name()) {
+			continue
+		}
+		builds = append(builds, Build{
+			Name:    fi.Name(),
+			Dir:     filepath.Join(root, fi.Name()),
+			Current: fi.Name() == current,
+		})
+	}
+
+	sort.Sort(buildsByName(builds))
+
+	return builds, nil
+}
+
+// Prune removes all but the keep newest builds. The current build is never
+// removed, and at least one build is always kept.
+func Prune(root string, keep int) ([]string, error) {
+	if keep < 1 {
+		keep = 1
+	}
+
+	builds, err := List(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for i := 0; i < len(builds)-keep; i++ {
+		b := builds[i]
+		if b.Current {
+			continue
+		}
+		if err := os.RemoveAll(b.Dir); err != nil {
+			return removed, err
+		}
+		removed = append(removed, b.Name)
+	}
+
+	return removed, nil
+}
+
+// Previous returns the name of the newest build older than the current one.
+func Previous(root string) (string, error) {
+	builds, err := List(root)
+	if err != nil {
+		return "", err
+	}
+
+	for i, b := range builds {
+		if b.Current {
+			if i == 0 {
+				return "", errors.New("no build older than the current one")
+			}
+			return builds[i-1].Name, nil
+		}
+	}
+
+	return "", errors.New("no current build")
+}
+
+type buildsByName []Build
+
+func (b buildsByName) Len() int           { return len(b) }
+func (b buildsByName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b buildsByName) Less(i, j int) bool { return b[i].Name < b[j].Name }
@@ -0,0 +1,88 @@
+package publish
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/govenue/require"
+)
+
+func TestPublish(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skip TestPublish as os.Symlink needs administrator rights on Windows")
+	}
+
+	assert := require.New(t)
+
+	root, err := ioutil.TempDir("", "gean-publish")
+	assert.NoError(err)
+	defer os.RemoveAll(root)
+
+	current, err := Current(root)
+	assert.NoError(err)
+	assert.Equal("", current)
+
+	start := time.Date(2017, 10, 19, 10, 15, 0, 0, time.UTC)
+
+	var names []string
+	for i := 0; i < 4; i++ {
+		dir, err := NewBuild(root, start.Add(time.Duration(i)*time.Hour))
+		assert.NoError(err)
+		assert.NoError(ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte(filepath.Base(dir)), 0644))
+		assert.NoError(Activate(root, filepath.Base(dir)))
+		names = append(names, filepath.Base(dir))
+	}
+
+	assert.Equal("20171019-101500", names[0])
+
+	// Same second.
+	dir, err := NewBuild(root, start)
+	assert.NoError(err)
+	assert.Equal("20171019-101500-1", filepath.Base(dir))
+	assert.NoError(os.Remove(dir))
+
+	current, err = Current(root)
+	assert.NoError(err)
+	assert.Equal(names[3], current)
+
+	b, err := ioutil.ReadFile(filepath.Join(root, CurrentLink, "index.html"))
+	assert.NoError(err)
+	assert.Equal(names[3], string(b))
+
+	prev, err := Previous(root)
+	assert.NoError(err)
+	assert.Equal(names[2], prev)
+
+	// Roll back to the first build and prune, the current build is kept.
+	assert.NoError(Activate(root, names[0]))
+	removed, err := Prune(root, 2)
+	assert.NoError(err)
+	assert.Equal([]string{names[1]}, removed)
+
+	builds, err := List(root)
+	assert.NoError(err)
+	assert.Len(builds, 3)
+	assert.True(builds[0].Current)
+	assert.Equal(names[3], builds[2].Name)
+
+	_, err = Previous(root)
+	assert.Error(err)
+
+	// A keep below 1 keeps the newest build.
+	removed, err = Prune(root, -1)
+	assert.NoError(err)
+	assert.Equal([]string{names[2]}, removed)
+
+	builds, err = List(root)
+	assert.NoError(err)
+	assert.Len(builds, 2)
+	assert.Equal(names[0], builds[0].Name)
+	assert.Equal(names[3], builds[1].Name)
+
+	assert.Error(Activate(root, names[1]))
+	assert.Error(Activate(root, "../etc"))
+}
@@ -0,0 +1,384 @@
+// Copyright 2017-present The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package related
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/govenue/fsintra"
+)
+
+// The index types.
+const (
+	// IndexTypeKeywords matches documents sharing the exact keywords, e.g.
+	// tags. This is the default.
+	IndexTypeKeywords = "keywords"
+
+	// IndexTypeContent matches documents with similar text content, using
+	// TF-IDF vectors and cosine similarity.
+	IndexTypeContent = "content"
+)
+
+// ContentDocument is a Document with text content to index in the content
+// indices. Documents not implementing it are left out of those.
+type ContentDocument interface {
+	Document
+
+	// SearchContent returns the plain text content for the given index config.
+	SearchContent(cfg IndexConfig) (string, error)
+}
+
+// termCounts holds the number of occurrences of every term in a text.
+type termCounts map[string]int
+
+// tokenize splits the text into lower case terms. Words are split on
+// anything but letters and digits. Chinese, Japanese and Korean text is
+// rarely separated by spaces, so those runs of characters are split into
+// overlapping bigrams instead.
+func tokenize(text string) termCounts {
+	counts := make(termCounts)
+
+	var (
+		word []rune
+		cjk  []rune
+	)
+
+	flushWord := func() {
+		if len(word) > 0 {
+			counts[string(word)]++
+			word = word[:0]
+		}
+	}
+
+	flushCJK := func() {
+		switch {
+		case len(cjk) == 1:
+			counts[string(cjk)]++
+		case len(cjk) > 1:
+			for i := 0; i < len(cjk)-1; i++ {
+				counts[string(cjk[i:i+2])]++
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, unicode.ToLower(r))
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+
+	flushWord()
+	flushCJK()
+
+	return counts
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// ContentCache caches the terms of the indexed content between builds, so
+// only changed content needs to be tokenized again on rebuilds. A cache
+// created with NewFileContentCache also stores the terms of the content used
+// in a build in a file, so they are reused by later runs.
+type ContentCache struct {
+	mu      sync.Mutex
+	entries map[uint64]*contentCacheEntry
+
+	fs       fsintra.Fs
+	filename string
+}
+
+type contentCacheEntry struct {
+	counts termCounts
+	used   bool
+}
+
+// NewContentCache creates a new, empty ContentCache.
+func NewContentCache() *ContentCache {
+	return &ContentCache{entries: make(map[uint64]*contentCacheEntry)}
+}
+
+// NewFileContentCache creates a new ContentCache with the terms stored in
+// filename by Save, if any. Failing to read the file is not an error, the
+// content is then just tokenized.
+func NewFileContentCache(fs fsintra.Fs, filename string) *ContentCache {
+	c := NewContentCache()
+	c.fs = fs
+	c.filename = filename
+
+	if b, err := fsintra.ReadFile(fs, filename); err == nil {
+		var stored map[uint64]termCounts
+		if err := json.Unmarshal(b, &stored); err == nil {
+			for k, counts := range stored {
+				c.entries[k] = &contentCacheEntry{counts: counts}
+			}
+		}
+	}
+
+	return c
+}
+
+func contentCacheKey(text string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	return h.Sum64()
+}
+
+// get returns the terms of the indexed content text.
+func (c *ContentCache) get(text string) termCounts {
+	if c == nil {
+		return tokenize(text)
+	}
+
+	key := contentCacheKey(text)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, found := c.entries[key]
+	if !found {
+		entry = &contentCacheEntry{counts: tokenize(text)}
+		c.entries[key] = entry
+	}
+	entry.used = true
+
+	return entry.counts
+}
+
+// query returns the terms of the search text. These are not cached, but the
+// text is often that of an indexed page.
+func (c *ContentCache) query(text string) termCounts {
+	if c == nil {
+		return tokenize(text)
+	}
+
+	c.mu.Lock()
+	entry, found := c.entries[contentCacheKey(text)]
+	c.mu.Unlock()
+
+	if found {
+		return entry.counts
+	}
+
+	return tokenize(text)
+}
+
+// Save replaces the cache file, if any, with the terms of the content used
+// since the previous call to Prune, i.e. that of the current build.
+func (c *ContentCache) Save() error {
+	if c == nil || c.fs == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	stored := make(map[uint64]termCounts)
+	for k, entry := range c.entries {
+		if entry.used {
+			stored[k] = entry.counts
+		}
+	}
+	c.mu.Unlock()
+
+	b, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+
+	if err := c.fs.MkdirAll(filepath.Dir(c.filename), 0777); err != nil {
+		return err
+	}
+
+	return fsintra.WriteFile(c.fs, c.filename, b, 0666)
+}
+
+// Prune removes the content not used since the previous call to Prune, e.g.
+// that of deleted or edited pages.
+func (c *ContentCache) Prune() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, entry := range c.entries {
+		if !entry.used {
+			delete(c.entries, k)
+			continue
+		}
+		entry.used = false
+	}
+}
+
+// posting is a document containing a term, with the TF-IDF weight of the
+// term in that document.
+type posting struct {
+	doc    int
+	weight float64
+}
+
+// contentIndex is an index of the TF-IDF vectors of the documents' content.
+type contentIndex struct {
+	cfg   IndexConfig
+	cache *ContentCache
+
+	docs   []Document
+	counts []termCounts
+
+	// The number of documents containing every term.
+	df map[string]int
+
+	// The vectors are computed from all the documents, so they are
+	// prepared on the first search after any change.
+	mu       sync.Mutex
+	dirty    bool
+	postings map[string][]posting
+}
+
+func newContentIndex(cfg IndexConfig, cache *ContentCache) *contentIndex {
+	return &contentIndex{cfg: cfg, cache: cache, df: make(map[string]int)}
+}
+
+func (idx *contentIndex) add(doc Document) error {
+	cd, ok := doc.(ContentDocument)
+	if !ok {
+		return nil
+	}
+
+	text, err := cd.SearchContent(idx.cfg)
+	if err != nil {
+		return err
+	}
+
+	counts := idx.cache.get(text)
+	if len(counts) == 0 {
+		return nil
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.docs = append(idx.docs, doc)
+	idx.counts = append(idx.counts, counts)
+	for term := range counts {
+		idx.df[term]++
+	}
+	idx.dirty = true
+
+	return nil
+}
+
+func (idx *contentIndex) idf(term string) float64 {
+	return math.Log(1 + float64(len(idx.docs))/float64(idx.df[term]))
+}
+
+// vector returns the normalized TF-IDF vector of the given counts. Terms
+// not in the index are left out.
+func (idx *contentIndex) vector(counts termCounts) map[string]float64 {
+	v := make(map[string]float64, len(counts))
+
+	var norm float64
+	for term, n := range counts {
+		if idx.df[term] == 0 {
+			continue
+		}
+		w := (1 + math.Log(float64(n))) * idx.idf(term)
+		v[term] = w
+		norm += w * w
+	}
+
+	if norm == 0 {
+		return v
+	}
+
+	norm = math.Sqrt(norm)
+	for term := range v {
+		v[term] /= norm
+	}
+
+	return v
+}
+
+// This assumes that a lock has been acquired.
+func (idx *contentIndex) prepare() {
+	if !idx.dirty {
+		return
+	}
+
+	idx.postings = make(map[string][]posting)
+	for i, counts := range idx.counts {
+		for term, w := range idx.vector(counts) {
+			idx.postings[term] = append(idx.postings[term], posting{doc: i, weight: w})
+		}
+	}
+
+	idx.dirty = false
+}
+
+// search returns the cosine similarity, between 0 and 1, of the given text
+// and every document sharing at least one term with it.
+func (idx *contentIndex) search(text string) map[Document]float64 {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.prepare()
+
+	q := idx.vector(idx.cache.query(text))
+
+	scores := make(map[int]float64)
+	for term, w := range q {
+		for _, p := range idx.postings[term] {
+			scores[p.doc] += w * p.weight
+		}
+	}
+
+	similarities := make(map[Document]float64, len(scores))
+	for i, score := range scores {
+		// Guard against rounding errors.
+		similarities[idx.docs[i]] = math.Min(score, 1)
+	}
+
+	return similarities
+}
+
+// contentQuery returns the text to search the content index with from the
+// given values.
+func contentQuery(cfg IndexConfig, values ...interface{}) (string, error) {
+	var parts []string
+	for _, v := range values {
+		switch vv := v.(type) {
+		case string:
+			parts = append(parts, vv)
+		case []string:
+			parts = append(parts, vv...)
+		case nil:
+		default:
+			return "", fmt.Errorf("content index %q can only be searched with strings, got %T", cfg.Name, v)
+		}
+	}
+	return strings.Join(parts, " "), nil
+}
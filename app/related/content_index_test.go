@@ -0,0 +1,199 @@
+// Copyright 2017-present The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package related
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/geego/gean/app/common/types"
+	"github.com/govenue/fsintra"
+	"github.com/govenue/require"
+)
+
+type testContentDoc struct {
+	*testDoc
+	content string
+}
+
+func newTestContentDoc(content string, tags ...string) *testContentDoc {
+	return &testContentDoc{testDoc: newTestDoc("tags", tags...), content: content}
+}
+
+func (d *testContentDoc) SearchContent(cfg IndexConfig) (string, error) {
+	return d.content, nil
+}
+
+func TestTokenize(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	assert.Equal(termCounts{"the": 2, "go": 1, "compiler": 1, "v1": 1, "9": 1}, tokenize("The Go compiler, the v1.9!"))
+	assert.Equal(termCounts{"東京": 1, "京の": 1, "の天": 1, "天気": 1, "go": 1, "晴": 1}, tokenize("東京の天気 Go 晴"))
+	assert.Equal(termCounts{"한국": 1, "국어": 1}, tokenize("한국어"))
+	assert.Len(tokenize(" ... "), 0)
+}
+
+func TestContentIndexSearch(t *testing.T) {
+	t.Parallel()
+
+	config := Config{
+		Threshold:    20,
+		IncludeNewer: true,
+		Indices: IndexConfigs{
+			IndexConfig{Name: "tags", Weight: 100},
+			IndexConfig{Name: "content", Type: IndexTypeContent, Weight: 100},
+		},
+	}
+
+	docs := []Document{
+		newTestContentDoc("Growing tomatoes in a small garden", "garden"),
+		newTestContentDoc("Tomatoes and peppers for the greenhouse"),
+		newTestContentDoc("Configuring the Go compiler for cross compilation", "go"),
+		newTestContentDoc("東京の天気予報"),
+		newTestContentDoc("東京の天気は晴れ"),
+		// Not indexed in the content index.
+		newTestDoc("tags", "go"),
+	}
+
+	idx := NewInvertedIndex(config)
+	idx.SetContentCache(NewContentCache())
+	assert := require.New(t)
+	assert.NoError(idx.Add(docs...))
+
+	t.Run("count", func(t *testing.T) {
+		assert := require.New(t)
+		assert.Len(idx.index, 1)
+		assert.Len(idx.content, 1)
+		assert.Len(idx.content["content"].docs, 5)
+	})
+
+	t.Run("searchdoc-content", func(t *testing.T) {
+		assert := require.New(t)
+		m, err := idx.SearchDoc(newTestContentDoc("Growing tomatoes in the garden"), "content")
+		assert.NoError(err)
+		assert.Len(m, 2)
+		assert.Equal(docs[0], m[0])
+		assert.Equal(docs[1], m[1])
+	})
+
+	t.Run("searchdoc-cjk", func(t *testing.T) {
+		assert := require.New(t)
+		m, err := idx.SearchDoc(newTestContentDoc("東京の天気"), "content")
+		assert.NoError(err)
+		assert.Len(m, 2)
+		assert.Equal(docs[3], m[0])
+		assert.Equal(docs[4], m[1])
+	})
+
+	t.Run("searchdoc-mixed", func(t *testing.T) {
+		assert := require.New(t)
+		// The keyword match on the tag ranks first, the pages with similar
+		// content follow.
+		m, err := idx.SearchDoc(newTestContentDoc("The Go compiler", "go"))
+		assert.NoError(err)
+		assert.Len(m, 2)
+		assert.Equal(docs[2], m[0])
+		assert.Equal(docs[5], m[1])
+	})
+
+	t.Run("search-keyvalues", func(t *testing.T) {
+		assert := require.New(t)
+		m, err := idx.SearchKeyValues(types.NewKeyValuesStrings("content", "cross compilation"))
+		assert.NoError(err)
+		assert.Len(m, 1)
+		assert.Equal(docs[2], m[0])
+
+		_, err = idx.SearchKeyValues(types.KeyValues{Key: "content", Values: []interface{}{32}})
+		assert.Error(err)
+	})
+}
+
+func TestContentCache(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	cache := NewContentCache()
+
+	counts := cache.get("a b")
+	cache.get("c")
+	assert.Len(cache.entries, 2)
+
+	cache.Prune()
+	assert.Len(cache.entries, 2)
+
+	// Reused, not tokenized again.
+	counts["a"] = 42
+	assert.Equal(42, cache.get("a b")["a"])
+
+	cache.Prune()
+	assert.Len(cache.entries, 1)
+}
+
+func TestFileContentCache(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	fs := fsintra.NewMemMapFs()
+	filename := filepath.Join("/cache", "related.json")
+
+	cache := NewFileContentCache(fs, filename)
+	counts := cache.get("a b a")
+	assert.Equal(termCounts{"a": 2, "b": 1}, counts)
+	cache.get("c")
+
+	// Search queries are not cached.
+	assert.Equal(termCounts{"q": 1}, cache.query("q"))
+	assert.Len(cache.entries, 2)
+
+	assert.NoError(cache.Save())
+
+	// A new cache, e.g. in the next run, reads the terms from the file.
+	cache = NewFileContentCache(fs, filename)
+	assert.Len(cache.entries, 2)
+	cache.entries[contentCacheKey("a b a")].counts["a"] = 42
+	assert.Equal(42, cache.get("a b a")["a"])
+
+	// Only the content used in the build is saved.
+	assert.NoError(cache.Save())
+	assert.Len(NewFileContentCache(fs, filename).entries, 1)
+
+	files, err := fsintra.ReadDir(fs, "/cache")
+	assert.NoError(err)
+	assert.Len(files, 1)
+}
+
+func TestDecodeConfigIndexType(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	c, err := DecodeConfig(map[string]interface{}{
+		"indices": []map[string]interface{}{
+			{"name": "content", "type": "content", "weight": 50},
+		},
+	})
+	assert.NoError(err)
+	assert.Equal(IndexTypeContent, c.Indices[0].Type)
+
+	_, err = DecodeConfig(map[string]interface{}{
+		"indices": []map[string]interface{}{
+			{"name": "content", "type": "fulltext", "weight": 50},
+		},
+	})
+	assert.Error(err)
+}
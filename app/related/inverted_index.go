@@ -58,6 +58,10 @@ An example site config.toml:
 	name  = "date"
 	weight = 1
 	pattern = "2006"
+	[[related.indices]]
+	name = "content"
+	type = "content"
+	weight = 80
 */
 type Config struct {
 	// Only include matches >= threshold, a normalized rank between 0 and 100.
@@ -86,8 +90,15 @@ type IndexConfigs []IndexConfig
 // IndexConfig configures an index.
 type IndexConfig struct {
 	// The index name. This directly maps to a field or Param name.
+	// Content indices index the content of the document and are only named.
 	Name string
 
+	// The index type, keywords (default) or content.
+	// Keyword indices match documents sharing keywords, e.g. tags.
+	// Content indices match documents with similar text content, with their
+	// similarity scaling the weight of the match.
+	Type string
+
 	// Contextual pattern used to convert the Param value into a string.
 	// Currently only used for dates. Can be used to, say, bump posts in the same
 	// time frame when searching for related documents.
@@ -115,8 +126,9 @@ type Document interface {
 // InvertedIndex holds an inverted index, also sometimes named posting list, which
 // lists, for every possible search term, the documents that contain that term.
 type InvertedIndex struct {
-	cfg   Config
-	index map[string]map[Keyword][]Document
+	cfg     Config
+	index   map[string]map[Keyword][]Document
+	content map[string]*contentIndex
 
 	minWeight int
 	maxWeight int
@@ -135,9 +147,17 @@ func (idx *InvertedIndex) getIndexCfg(name string) (IndexConfig, bool) {
 // NewInvertedIndex creates a new InvertedIndex.
 // Documents to index must be added in Add.
 func NewInvertedIndex(cfg Config) *InvertedIndex {
-	idx := &InvertedIndex{index: make(map[string]map[Keyword][]Document), cfg: cfg}
+	idx := &InvertedIndex{
+		index:   make(map[string]map[Keyword][]Document),
+		content: make(map[string]*contentIndex),
+		cfg:     cfg,
+	}
 	for _, conf := range cfg.Indices {
-		idx.index[conf.Name] = make(map[Keyword][]Document)
+		if conf.isContent() {
+			idx.content[conf.Name] = newContentIndex(conf, nil)
+		} else {
+			idx.index[conf.Name] = make(map[Keyword][]Document)
+		}
 		if conf.Weight < idx.minWeight {
 			// By default, the weight scale starts at 0, but we allow
 			// negative weights.
@@ -150,6 +170,14 @@ func NewInvertedIndex(cfg Config) *InvertedIndex {
 	return idx
 }
 
+// SetContentCache sets the cache to use for the content indices, to reuse
+// between builds. This must be set before adding any documents.
+func (idx *InvertedIndex) SetContentCache(cache *ContentCache) {
+	for _, ci := range idx.content {
+		ci.cache = cache
+	}
+}
+
 // Add documents to the inverted index.
 // The value must support == and !=.
 func (idx *InvertedIndex) Add(docs ...Document) error {
@@ -159,6 +187,16 @@ func (idx *InvertedIndex) Add(docs ...Document) error {
 			// Disabled
 			continue
 		}
+
+		if ci, found := idx.content[config.Name]; found {
+			for _, doc := range docs {
+				if addErr := ci.add(doc); addErr != nil {
+					err = addErr
+				}
+			}
+			continue
+		}
+
 		setm := idx.index[config.Name]
 
 		for _, doc := range docs {
@@ -179,10 +217,11 @@ func (idx *InvertedIndex) Add(docs ...Document) error {
 }
 
 // queryElement holds the index name and keywords that can be used to compose a
-// search for related content.
+// search for related content. Content indices are searched with the text.
 type queryElement struct {
 	Index    string
 	Keywords []Keyword
+	Text     string
 }
 
 func newQueryElement(index string, keywords ...Keyword) queryElement {
@@ -240,6 +279,19 @@ func (idx *InvertedIndex) SearchDoc(doc Document, indices ...string) ([]Document
 	}
 
 	for _, cfg := range configs {
+		if cfg.isContent() {
+			var text string
+			if cd, ok := doc.(ContentDocument); ok {
+				var err error
+				text, err = cd.SearchContent(cfg)
+				if err != nil {
+					return nil, err
+				}
+			}
+			q = append(q, queryElement{Index: cfg.Name, Text: text})
+			continue
+		}
+
 		keywords, err := doc.SearchKeywords(cfg)
 		if err != nil {
 			return nil, err
@@ -252,6 +304,10 @@ func (idx *InvertedIndex) SearchDoc(doc Document, indices ...string) ([]Document
 	return idx.searchDate(doc.PubDate(), q...)
 }
 
+func (cfg IndexConfig) isContent() bool {
+	return cfg.Type == IndexTypeContent
+}
+
 func (cfg IndexConfig) ToKeywords(v interface{}) ([]Keyword, error) {
 	var (
 		keywords []Keyword
@@ -303,6 +359,15 @@ func (idx *InvertedIndex) SearchKeyValues(args ...types.KeyValues) ([]Document,
 			return nil, fmt.Errorf("index %q not found", key)
 		}
 
+		if conf.isContent() {
+			text, err := contentQuery(conf, arg.Values...)
+			if err != nil {
+				return nil, err
+			}
+			q[i] = queryElement{Index: conf.Name, Text: text}
+			continue
+		}
+
 		for _, val := range arg.Values {
 			k, err := conf.ToKeywords(val)
 			if err != nil {
@@ -326,17 +391,39 @@ func (idx *InvertedIndex) searchDate(upperDate time.Time, query ...queryElement)
 	matchm := make(map[Document]*rank, 200)
 	applyDateFilter := !idx.cfg.IncludeNewer && !upperDate.IsZero()
 
-	for _, el := range query {
-		setm, found := idx.index[el.Index]
+	addMatch := func(doc Document, weight int) {
+		r, found := matchm[doc]
 		if !found {
-			return []Document{}, fmt.Errorf("index for %q not found", el.Index)
+			matchm[doc] = newRank(doc, weight)
+		} else {
+			r.addWeight(weight)
 		}
+	}
 
+	for _, el := range query {
 		config, found := idx.getIndexCfg(el.Index)
 		if !found {
 			return []Document{}, fmt.Errorf("index config for %q not found", el.Index)
 		}
 
+		if ci, found := idx.content[el.Index]; found {
+			if el.Text == "" {
+				continue
+			}
+			for doc, similarity := range ci.search(el.Text) {
+				if applyDateFilter && doc.PubDate().After(upperDate) {
+					continue
+				}
+				addMatch(doc, int(math.Floor(float64(config.Weight)*similarity+0.5)))
+			}
+			continue
+		}
+
+		setm, found := idx.index[el.Index]
+		if !found {
+			return []Document{}, fmt.Errorf("index for %q not found", el.Index)
+		}
+
 		for _, kw := range el.Keywords {
 			if docs, found := setm[kw]; found {
 				for _, doc := range docs {
@@ -346,12 +433,7 @@ func (idx *InvertedIndex) searchDate(upperDate time.Time, query ...queryElement)
 							continue
 						}
 					}
-					r, found := matchm[doc]
-					if !found {
-						matchm[doc] = newRank(doc, config.Weight)
-					} else {
-						r.addWeight(config.Weight)
-					}
+					addMatch(doc, config.Weight)
 				}
 			}
 		}
@@ -417,6 +499,14 @@ func DecodeConfig(in interface{}) (Config, error) {
 		return Config{}, errors.New("related threshold must be between 0 and 100")
 	}
 
+	for _, index := range c.Indices {
+		switch index.Type {
+		case "", IndexTypeKeywords, IndexTypeContent:
+		default:
+			return Config{}, fmt.Errorf("related index %q has unknown type %q", index.Name, index.Type)
+		}
+	}
+
 	if c.ToLower {
 		for i := range c.Indices {
 			c.Indices[i].ToLower = true
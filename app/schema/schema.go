@@ -0,0 +1,405 @@
+// Package schema validates page front matter against the schema declared
+// for its content type.
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/govenue/assist"
+)
+
+// The field types.
+const (
+	TypeString = "string"
+	TypeInt    = "int"
+	TypeFloat  = "float"
+	TypeBool   = "bool"
+	TypeDate   = "date"
+	TypeList   = "list"
+	TypeMap    = "map"
+)
+
+var fieldTypes = map[string]bool{
+	TypeString: true,
+	TypeInt:    true,
+	TypeFloat:  true,
+	TypeBool:   true,
+	TypeDate:   true,
+	TypeList:   true,
+	TypeMap:    true,
+}
+
+// Schemas maps content types to their schema.
+type Schemas map[string]*Schema
+
+// Schema describes the front matter of a content type.
+//
+// An example site config.toml:
+//
+//	[schemas.episode]
+//	[schemas.episode.fields.episode]
+//	type = "int"
+//	required = true
+//	[schemas.episode.fields.status]
+//	enum = ["draft", "live", "archived"]
+//	default = "draft"
+//	[schemas.episode.fields.recorded]
+//	type = "date"
+//	format = "2006-01-02"
+//
+// The same schema can be put in archetypes/episode.schema.yaml:
+//
+//	fields:
+//	  episode: {type: int, required: true}
+//	  status: {enum: [draft, live, archived], default: draft}
+//	  recorded: {type: date, format: "2006-01-02"}
+type Schema struct {
+	// The declared keys, lower case.
+	Fields map[string]*Field
+
+	// Allow keys that are neither declared nor known. By default these are
+	// reported, as they are most likely typos.
+	Open bool
+}
+
+// Field describes a front matter key.
+type Field struct {
+	// One of the Type constants. Any type is allowed if empty.
+	Type string
+
+	// The key must be set, unless it has a default.
+	Required bool
+
+	// The allowed values, compared as strings. For lists this applies to
+	// every element.
+	Enum []string
+
+	// The Go time layout dates given as strings must match, e.g.
+	// "2006-01-02". Any format understood by Gean is allowed if empty.
+	Format string
+
+	// The value used if the key isn't set.
+	Default interface{}
+}
+
+// Error is a front matter value that doesn't match the schema.
+type Error struct {
+	// The lower case front matter key.
+	Key string
+
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// DecodeConfig decodes the schemas section of the site config.
+func DecodeConfig(in interface{}) (Schemas, error) {
+	schemas := make(Schemas)
+
+	if in == nil {
+		return schemas, nil
+	}
+
+	for typ, v := range assist.ToStringMap(in) {
+		s, err := Decode(v)
+		if err != nil {
+			return schemas, fmt.Errorf("schema %q: %s", typ, err)
+		}
+		schemas[strings.ToLower(typ)] = s
+	}
+
+	return schemas, nil
+}
+
+// Decode decodes a single schema, e.g. read from a file.
+func Decode(in interface{}) (*Schema, error) {
+	s := &Schema{Fields: make(map[string]*Field)}
+
+	m, err := assist.ToStringMapE(in)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range m {
+		switch strings.ToLower(k) {
+		case "open":
+			s.Open = assist.ToBool(v)
+		case "fields":
+			fields, err := assist.ToStringMapE(v)
+			if err != nil {
+				return nil, err
+			}
+			for name, fv := range fields {
+				f, err := decodeField(fv)
+				if err != nil {
+					return nil, fmt.Errorf("field %q: %s", name, err)
+				}
+				s.Fields[strings.ToLower(name)] = f
+			}
+		default:
+			return nil, fmt.Errorf("unknown setting %q", k)
+		}
+	}
+
+	return s, nil
+}
+
+func decodeField(in interface{}) (*Field, error) {
+	f := &Field{}
+
+	m, err := assist.ToStringMapE(in)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range m {
+		switch strings.ToLower(k) {
+		case "type":
+			f.Type = strings.ToLower(assist.ToString(v))
+			if !fieldTypes[f.Type] {
+				return nil, fmt.Errorf("unknown type %q", f.Type)
+			}
+		case "required":
+			f.Required = assist.ToBool(v)
+		case "enum":
+			f.Enum = assist.ToStringSlice(v)
+		case "format":
+			f.Format = assist.ToString(v)
+		case "default":
+			f.Default = v
+		default:
+			return nil, fmt.Errorf("unknown setting %q", k)
+		}
+	}
+
+	if f.Format != "" && f.Type != TypeDate {
+		return nil, errors.New("format is only supported for dates")
+	}
+
+	if f.Default != nil {
+		if msg := f.check(f.Default); msg != "" {
+			return nil, fmt.Errorf("invalid default: %s", msg)
+		}
+	}
+
+	return f, nil
+}
+
+// Validate checks the front matter in fm against the schema. The keys in
+// known, lower case, are accepted even if not declared, e.g. the keys used
+// by Gean itself. The errors are ordered by key.
+func (s *Schema) Validate(fm map[string]interface{}, known map[string]bool) []*Error {
+	var errs []*Error
+
+	values := make(map[string]interface{}, len(fm))
+	for k, v := range fm {
+		values[strings.ToLower(k)] = v
+	}
+
+	for key, f := range s.Fields {
+		if _, found := values[key]; !found && f.Required && f.Default == nil {
+			errs = append(errs, &Error{Key: key, Message: fmt.Sprintf("required key %q is missing", key)})
+		}
+	}
+
+	for key, v := range values {
+		f, found := s.Fields[key]
+		if !found {
+			if s.Open || known[key] {
+				continue
+			}
+			msg := fmt.Sprintf("unknown key %q", key)
+			if suggestion := s.suggest(key, known); suggestion != "" {
+				msg += fmt.Sprintf(", did you mean %q?", suggestion)
+			}
+			errs = append(errs, &Error{Key: key, Message: msg})
+			continue
+		}
+
+		if msg := f.check(v); msg != "" {
+			errs = append(errs, &Error{Key: key, Message: fmt.Sprintf("%q %s", key, msg)})
+		}
+	}
+
+	sort.Sort(errorsByKey(errs))
+
+	return errs
+}
+
+// ApplyDefaults sets the keys with a default that are missing in fm.
+func (s *Schema) ApplyDefaults(fm map[string]interface{}) {
+	set := make(map[string]bool, len(fm))
+	for k := range fm {
+		set[strings.ToLower(k)] = true
+	}
+
+	for key, f := range s.Fields {
+		if f.Default != nil && !set[key] {
+			fm[key] = f.Default
+		}
+	}
+}
+
+// suggest returns the declared or known key closest to key, if close enough
+// to be a likely typo.
+func (s *Schema) suggest(key string, known map[string]bool) string {
+	var (
+		best     string
+		bestDist = 3
+	)
+
+	try := func(candidate string) {
+		d := editDistance(key, candidate)
+		if d < bestDist || (d == bestDist && candidate < best) {
+			best, bestDist = candidate, d
+		}
+	}
+
+	for k := range s.Fields {
+		try(k)
+	}
+	for k := range known {
+		try(k)
+	}
+
+	if bestDist > 2 || bestDist >= len(key) {
+		return ""
+	}
+
+	return best
+}
+
+// check returns why v isn't valid for f, an empty string if it is.
+func (f *Field) check(v interface{}) string {
+	switch f.Type {
+	case TypeString:
+		if _, ok := v.(string); !ok {
+			return fmt.Sprintf("must be a string, got %T", v)
+		}
+	case TypeInt:
+		switch vv := v.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		case float64:
+			// JSON numbers.
+			if vv != float64(int64(vv)) {
+				return fmt.Sprintf("must be an integer, got %v", vv)
+			}
+		default:
+			return fmt.Sprintf("must be an integer, got %T", v)
+		}
+	case TypeFloat:
+		switch v.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		default:
+			return fmt.Sprintf("must be a number, got %T", v)
+		}
+	case TypeBool:
+		if _, ok := v.(bool); !ok {
+			return fmt.Sprintf("must be true or false, got %T", v)
+		}
+	case TypeDate:
+		switch vv := v.(type) {
+		case time.Time:
+		case string:
+			if f.Format != "" {
+				if _, err := time.Parse(f.Format, vv); err != nil {
+					return fmt.Sprintf("must be a date on the form %q, got %q", f.Format, vv)
+				}
+			} else if _, err := assist.ToTimeE(vv); err != nil {
+				return fmt.Sprintf("must be a date, got %q", vv)
+			}
+		default:
+			return fmt.Sprintf("must be a date, got %T", v)
+		}
+	case TypeList:
+		switch v.(type) {
+		case []interface{}, []string:
+		default:
+			return fmt.Sprintf("must be a list, got %T", v)
+		}
+	case TypeMap:
+		switch v.(type) {
+		case map[string]interface{}, map[interface{}]interface{}:
+		default:
+			return fmt.Sprintf("must be a map, got %T", v)
+		}
+	}
+
+	if len(f.Enum) == 0 {
+		return ""
+	}
+
+	values := []interface{}{v}
+	switch vv := v.(type) {
+	case []interface{}:
+		values = vv
+	case []string:
+		values = make([]interface{}, len(vv))
+		for i, s := range vv {
+			values[i] = s
+		}
+	}
+
+	for _, value := range values {
+		if !f.allowed(assist.ToString(value)) {
+			return fmt.Sprintf("must be one of %s, got %q", strings.Join(f.Enum, ", "), assist.ToString(value))
+		}
+	}
+
+	return ""
+}
+
+func (f *Field) allowed(s string) bool {
+	for _, e := range f.Enum {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+type errorsByKey []*Error
+
+func (e errorsByKey) Len() int           { return len(e) }
+func (e errorsByKey) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e errorsByKey) Less(i, j int) bool { return e[i].Key < e[j].Key }
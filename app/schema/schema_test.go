@@ -0,0 +1,180 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/govenue/require"
+)
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	s, err := Decode(map[string]interface{}{
+		"open": true,
+		"fields": map[string]interface{}{
+			"Episode":  map[string]interface{}{"type": "int", "required": true},
+			"status":   map[string]interface{}{"enum": []interface{}{"draft", "live"}, "default": "draft"},
+			"recorded": map[string]interface{}{"type": "date", "format": "2006-01-02"},
+		},
+	})
+
+	assert.NoError(err)
+	assert.True(s.Open)
+	assert.Len(s.Fields, 3)
+	assert.Equal(TypeInt, s.Fields["episode"].Type)
+	assert.True(s.Fields["episode"].Required)
+	assert.Equal([]string{"draft", "live"}, s.Fields["status"].Enum)
+	assert.Equal("draft", s.Fields["status"].Default)
+	assert.Equal("2006-01-02", s.Fields["recorded"].Format)
+
+	for i, in := range []map[string]interface{}{
+		{"closed": true},
+		{"fields": map[string]interface{}{"a": map[string]interface{}{"type": "number"}}},
+		{"fields": map[string]interface{}{"a": map[string]interface{}{"min": 3}}},
+		{"fields": map[string]interface{}{"a": map[string]interface{}{"type": "string", "format": "2006"}}},
+		{"fields": map[string]interface{}{"a": map[string]interface{}{"enum": []interface{}{"a"}, "default": "b"}}},
+	} {
+		_, err := Decode(in)
+		assert.Error(err, "[%d]", i)
+	}
+}
+
+func TestDecodeConfig(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	schemas, err := DecodeConfig(nil)
+	assert.NoError(err)
+	assert.Len(schemas, 0)
+
+	schemas, err = DecodeConfig(map[string]interface{}{
+		"Episode": map[string]interface{}{
+			"fields": map[string]interface{}{"episode": map[string]interface{}{"type": "int"}},
+		},
+	})
+	assert.NoError(err)
+	assert.Len(schemas, 1)
+	assert.NotNil(schemas["episode"])
+
+	_, err = DecodeConfig(map[string]interface{}{
+		"episode": map[string]interface{}{"fields": "episode"},
+	})
+	assert.Error(err)
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	s := &Schema{Fields: map[string]*Field{
+		"episode":  {Type: TypeInt, Required: true},
+		"status":   {Enum: []string{"draft", "live"}, Default: "draft"},
+		"guests":   {Type: TypeList, Enum: []string{"ann", "bob"}},
+		"recorded": {Type: TypeDate, Format: "2006-01-02"},
+		"rating":   {Type: TypeFloat},
+		"explicit": {Type: TypeBool},
+	}}
+
+	known := map[string]bool{"title": true, "publishdate": true}
+
+	messages := func(errs []*Error) []string {
+		var m []string
+		for _, e := range errs {
+			m = append(m, e.Key+": "+e.Message)
+		}
+		return m
+	}
+
+	assert.Empty(s.Validate(map[string]interface{}{
+		"Title":    "First",
+		"episode":  1,
+		"guests":   []interface{}{"ann"},
+		"recorded": "2017-03-21",
+		"rating":   4,
+		"explicit": false,
+	}, known))
+
+	// JSON numbers are float64.
+	assert.Empty(s.Validate(map[string]interface{}{"episode": float64(2)}, known))
+
+	assert.Equal([]string{
+		`episode: required key "episode" is missing`,
+	}, messages(s.Validate(map[string]interface{}{"title": "First"}, known)))
+
+	assert.Equal([]string{
+		`episode: required key "episode" is missing`,
+		`epsiode: unknown key "epsiode", did you mean "episode"?`,
+		`publishdat: unknown key "publishdat", did you mean "publishdate"?`,
+		`xyz: unknown key "xyz"`,
+	}, messages(s.Validate(map[string]interface{}{
+		"epsiode":    1,
+		"publishdat": "2017-03-21",
+		"xyz":        true,
+	}, known)))
+
+	assert.Equal([]string{
+		`episode: "episode" must be an integer, got 1.5`,
+		`explicit: "explicit" must be true or false, got string`,
+		`guests: "guests" must be one of ann, bob, got "eve"`,
+		`rating: "rating" must be a number, got string`,
+		`recorded: "recorded" must be a date on the form "2006-01-02", got "21/03/2017"`,
+		`status: "status" must be one of draft, live, got "gone"`,
+	}, messages(s.Validate(map[string]interface{}{
+		"episode":  1.5,
+		"status":   "gone",
+		"guests":   []interface{}{"ann", "eve"},
+		"recorded": "21/03/2017",
+		"rating":   "good",
+		"explicit": "no",
+	}, known)))
+
+	assert.Empty(s.Validate(map[string]interface{}{
+		"episode":  1,
+		"recorded": time.Now(),
+	}, known))
+
+	s.Open = true
+	assert.Empty(s.Validate(map[string]interface{}{"episode": 1, "xyz": true}, known))
+}
+
+func TestApplyDefaults(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	s := &Schema{Fields: map[string]*Field{
+		"status": {Default: "draft"},
+		"rating": {Default: 3},
+		"guests": {},
+	}}
+
+	fm := map[string]interface{}{"Rating": 5}
+	s.ApplyDefaults(fm)
+
+	assert.Equal(map[string]interface{}{"Rating": 5, "status": "draft"}, fm)
+}
+
+func TestEditDistance(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	for i, c := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"episode", "episode", 0},
+		{"epsiode", "episode", 2},
+		{"publishdat", "publishdate", 1},
+		{"kitten", "sitting", 3},
+	} {
+		assert.Equal(c.want, editDistance(c.a, c.b), "[%d]", i)
+	}
+}
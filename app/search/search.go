@@ -0,0 +1,287 @@
+// Package search builds indexes of the site content for client side search.
+package search
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/govenue/mapstructure"
+)
+
+// The fields of a document, in addition to one per taxonomy.
+const (
+	FieldTitle    = "title"
+	FieldSummary  = "summary"
+	FieldSections = "sections"
+	FieldContent  = "content"
+)
+
+// taxonomiesWeight is the key in Config.Weights with the weight of the
+// taxonomies not set by name.
+const taxonomiesWeight = "taxonomies"
+
+// Version is the version of the index schema.
+const Version = 1
+
+/*
+Config configures the search index.
+
+An example site config.toml:
+
+	[searchIndex]
+	shardSize = 500
+	facets = ["author", "series"]
+	stopWords = ["gean"]
+	[searchIndex.weights]
+	title = 10
+	tags = 5
+	summary = 0
+*/
+type Config struct {
+	// The weight of every field, keyed by field name or taxonomy plural.
+	// The key "taxonomies" sets the weight of the taxonomies not listed.
+	// Fields with a weight of 0 or less are left out of the index.
+	Weights map[string]float64
+
+	// The page params to include with every document, e.g. to filter the
+	// search results by.
+	Facets []string
+
+	// Split the documents into shards of this many documents, 0 to keep
+	// them all in the index file.
+	ShardSize int
+
+	// Disable stemming of the content.
+	NoStemming bool
+
+	// Words to leave out of the content in addition to the stop words of
+	// the language.
+	StopWords []string
+}
+
+// DefaultConfig returns the default search index config.
+func DefaultConfig() Config {
+	return Config{
+		Weights: map[string]float64{
+			FieldTitle:       10,
+			FieldSummary:     5,
+			FieldSections:    2,
+			taxonomiesWeight: 3,
+			FieldContent:     1,
+		},
+	}
+}
+
+// DecodeConfig creates a search index config from the given input, the
+// searchIndex section of the site config. Weights not set keep their
+// default.
+func DecodeConfig(in interface{}) (Config, error) {
+	if in == nil {
+		return DefaultConfig(), nil
+	}
+
+	m, ok := in.(map[string]interface{})
+	if !ok {
+		return DefaultConfig(), fmt.Errorf("expected map[string]interface {} got %T", in)
+	}
+
+	var c Config
+
+	if err := mapstructure.WeakDecode(m, &c); err != nil {
+		return c, err
+	}
+
+	if c.ShardSize < 0 {
+		return c, fmt.Errorf("searchIndex shardSize must be 0 or more, got %d", c.ShardSize)
+	}
+
+	// Keys are case insensitive in the site config.
+	weights := DefaultConfig().Weights
+	for k, v := range c.Weights {
+		weights[strings.ToLower(k)] = v
+	}
+	c.Weights = weights
+
+	return c, nil
+}
+
+func (c Config) weight(field string) float64 {
+	if w, found := c.Weights[field]; found {
+		return w
+	}
+	return c.Weights[taxonomiesWeight]
+}
+
+// Field is a field of the documents, with the weight to give matches in
+// it. This maps to e.g. a Lunr field boost or a Fuse.js key weight.
+type Field struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+}
+
+// Document is a page in the index. The keys are "id", the fields and
+// "facets".
+type Document map[string]interface{}
+
+// Index is the search index as written. If sharded, the documents are in
+// the shard files, named relative to the index file.
+type Index struct {
+	Version int    `json:"version"`
+	Lang    string `json:"lang"`
+
+	// The document key identifying a document, its relative permalink.
+	Ref string `json:"ref"`
+
+	Fields []Field    `json:"fields"`
+	Facets []string   `json:"facets,omitempty"`
+	Shards []string   `json:"shards,omitempty"`
+	Docs   []Document `json:"documents,omitempty"`
+}
+
+// Shard is a part of the documents in a sharded index.
+type Shard struct {
+	// The filename, relative to that of the index.
+	Name string `json:"-"`
+
+	Docs []Document `json:"documents"`
+}
+
+// Source is the content of a page to index.
+type Source struct {
+	// The relative permalink.
+	ID string
+
+	Title   string
+	Summary string
+
+	// The sections from the top, e.g. ["docs", "getting-started"].
+	Sections []string
+
+	// The taxonomy terms of the page, keyed by taxonomy plural.
+	Taxonomies map[string][]string
+
+	// The plain text words of the content.
+	Words []string
+
+	// The page params, with lower case keys.
+	Params map[string]interface{}
+}
+
+// Builder builds the search index of a language.
+type Builder struct {
+	cfg        Config
+	lang       string
+	taxonomies []string
+	tokenizer  *Tokenizer
+	docs       []Document
+}
+
+// NewBuilder creates a new Builder for the content in the given language,
+// with the given taxonomies, by plural name.
+func NewBuilder(cfg Config, lang string, taxonomies []string) *Builder {
+	taxonomies = append([]string(nil), taxonomies...)
+	sort.Strings(taxonomies)
+
+	return &Builder{
+		cfg:        cfg,
+		lang:       lang,
+		taxonomies: taxonomies,
+		tokenizer:  NewTokenizer(lang, cfg),
+	}
+}
+
+// Add adds a page to the index.
+func (b *Builder) Add(src Source) {
+	doc := Document{"id": src.ID}
+
+	set := func(field string, v interface{}) {
+		if b.cfg.weight(field) > 0 {
+			doc[field] = v
+		}
+	}
+
+	set(FieldTitle, src.Title)
+	set(FieldSummary, src.Summary)
+	set(FieldSections, nonNil(src.Sections))
+	for _, plural := range b.taxonomies {
+		set(plural, nonNil(src.Taxonomies[plural]))
+	}
+	set(FieldContent, strings.Join(b.tokenizer.Tokens(src.Words), " "))
+
+	if len(b.cfg.Facets) > 0 {
+		facets := make(map[string]interface{})
+		for _, name := range b.cfg.Facets {
+			if v, found := src.Params[strings.ToLower(name)]; found {
+				facets[name] = v
+			}
+		}
+		doc["facets"] = facets
+	}
+
+	b.docs = append(b.docs, doc)
+}
+
+// Build returns the index and, if sharded, its shards. The documents are
+// ordered by ID. The shards are named from the filename of the index, e.g.
+// search.json gives search-1.json, search-2.json etc.
+func (b *Builder) Build(filename string) (*Index, []*Shard) {
+	sort.Sort(documentsByID(b.docs))
+
+	idx := &Index{
+		Version: Version,
+		Lang:    b.lang,
+		Ref:     "id",
+		Facets:  b.cfg.Facets,
+	}
+
+	for _, name := range b.fieldNames() {
+		if w := b.cfg.weight(name); w > 0 {
+			idx.Fields = append(idx.Fields, Field{Name: name, Weight: w})
+		}
+	}
+
+	if b.cfg.ShardSize == 0 || len(b.docs) <= b.cfg.ShardSize {
+		idx.Docs = b.docs
+		return idx, nil
+	}
+
+	ext := path.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+
+	var shards []*Shard
+	for i := 0; i < len(b.docs); i += b.cfg.ShardSize {
+		end := i + b.cfg.ShardSize
+		if end > len(b.docs) {
+			end = len(b.docs)
+		}
+		shard := &Shard{Name: fmt.Sprintf("%s-%d%s", base, len(shards)+1, ext), Docs: b.docs[i:end]}
+		shards = append(shards, shard)
+		idx.Shards = append(idx.Shards, shard.Name)
+	}
+
+	return idx, shards
+}
+
+func (b *Builder) fieldNames() []string {
+	names := []string{FieldTitle, FieldSummary, FieldSections}
+	names = append(names, b.taxonomies...)
+	return append(names, FieldContent)
+}
+
+// nonNil makes empty lists show as [] and not null in JSON.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+type documentsByID []Document
+
+func (d documentsByID) Len() int      { return len(d) }
+func (d documentsByID) Swap(i, j int) { d[i], d[j] = d[j], d[i] }
+func (d documentsByID) Less(i, j int) bool {
+	return d[i]["id"].(string) < d[j]["id"].(string)
+}
@@ -0,0 +1,144 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/govenue/require"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	c, err := DecodeConfig(nil)
+	assert.NoError(err)
+	assert.Equal(DefaultConfig(), c)
+
+	c, err = DecodeConfig(map[string]interface{}{
+		"shardSize": 100,
+		"facets":    []interface{}{"author"},
+		"weights":   map[string]interface{}{"Title": 20, "tags": 4, "summary": 0},
+	})
+	assert.NoError(err)
+	assert.Equal(100, c.ShardSize)
+	assert.Equal([]string{"author"}, c.Facets)
+	assert.Equal(20.0, c.weight("title"))
+	assert.Equal(4.0, c.weight("tags"))
+	assert.Equal(0.0, c.weight("summary"))
+	assert.Equal(3.0, c.weight("categories"))
+	assert.Equal(1.0, c.weight("content"))
+
+	// The defaults are not shared.
+	assert.Equal(10.0, DefaultConfig().weight("title"))
+
+	_, err = DecodeConfig(map[string]interface{}{"shardSize": -1})
+	assert.Error(err)
+}
+
+func TestTokenizer(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	words := []string{"The", "cats", "jumped,", "running", "gean-rocks!"}
+
+	en := NewTokenizer("en-US", DefaultConfig())
+	assert.Equal([]string{"cat", "jump", "run", "gean", "rock"}, en.Tokens(words))
+
+	cfg := DefaultConfig()
+	cfg.NoStemming = true
+	cfg.StopWords = []string{"Gean"}
+	assert.Equal([]string{"cats", "jumped", "running", "rocks"}, NewTokenizer("en", cfg).Tokens(words))
+
+	// No stemmer or stop words for Klingon.
+	assert.Equal([]string{"the", "cats"}, NewTokenizer("tlh", DefaultConfig()).Tokens([]string{"The", "cats"}))
+}
+
+func TestBuilder(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	cfg := DefaultConfig()
+	cfg.NoStemming = true
+	cfg.Facets = []string{"author"}
+	cfg.Weights["summary"] = 0
+
+	b := NewBuilder(cfg, "en", []string{"tags", "categories"})
+	b.Add(Source{
+		ID:         "/b/",
+		Title:      "B",
+		Summary:    "About B",
+		Sections:   []string{"post"},
+		Taxonomies: map[string][]string{"tags": {"go"}},
+		Words:      []string{"The", "words"},
+		Params:     map[string]interface{}{"author": "Ann"},
+	})
+	b.Add(Source{ID: "/a/", Title: "A"})
+
+	idx, shards := b.Build("searchindex.json")
+	assert.Len(shards, 0)
+
+	assert.Equal(Version, idx.Version)
+	assert.Equal("en", idx.Lang)
+	assert.Equal("id", idx.Ref)
+	assert.Equal([]string{"author"}, idx.Facets)
+	assert.Equal([]Field{
+		{Name: "title", Weight: 10},
+		{Name: "sections", Weight: 2},
+		{Name: "categories", Weight: 3},
+		{Name: "tags", Weight: 3},
+		{Name: "content", Weight: 1},
+	}, idx.Fields)
+
+	assert.Equal([]Document{
+		{
+			"id":         "/a/",
+			"title":      "A",
+			"sections":   []string{},
+			"categories": []string{},
+			"tags":       []string{},
+			"content":    "",
+			"facets":     map[string]interface{}{},
+		},
+		{
+			"id":         "/b/",
+			"title":      "B",
+			"sections":   []string{"post"},
+			"categories": []string{},
+			"tags":       []string{"go"},
+			"content":    "words",
+			"facets":     map[string]interface{}{"author": "Ann"},
+		},
+	}, idx.Docs)
+}
+
+func TestBuilderShards(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	cfg := DefaultConfig()
+	cfg.ShardSize = 2
+
+	b := NewBuilder(cfg, "en", nil)
+	for _, id := range []string{"/c/", "/a/", "/e/", "/b/", "/d/"} {
+		b.Add(Source{ID: id, Title: id})
+	}
+
+	idx, shards := b.Build("searchindex.json")
+
+	assert.Len(idx.Docs, 0)
+	assert.Equal([]string{"searchindex-1.json", "searchindex-2.json", "searchindex-3.json"}, idx.Shards)
+	assert.Len(shards, 3)
+
+	var ids []string
+	for i, shard := range shards {
+		assert.Equal(idx.Shards[i], shard.Name)
+		for _, doc := range shard.Docs {
+			ids = append(ids, doc["id"].(string))
+		}
+	}
+	assert.Equal([]string{"/a/", "/b/", "/c/", "/d/", "/e/"}, ids)
+}
@@ -0,0 +1,45 @@
+package search
+
+// stopWords are the words too common to be worth indexing, per language.
+var stopWords = map[string][]string{
+	"en": {
+		"a", "about", "an", "and", "are", "as", "at", "be", "but", "by",
+		"for", "from", "has", "have", "he", "her", "his", "i", "if", "in",
+		"into", "is", "it", "its", "of", "on", "or", "our", "she", "so",
+		"that", "the", "their", "them", "then", "there", "these", "they",
+		"this", "to", "was", "we", "were", "what", "when", "which", "who",
+		"will", "with", "you", "your",
+	},
+	"es": {
+		"a", "al", "como", "con", "de", "del", "el", "en", "es", "esta",
+		"este", "la", "las", "le", "lo", "los", "más", "no", "o", "para",
+		"pero", "por", "que", "se", "si", "su", "sus", "un", "una", "y",
+	},
+	"fr": {
+		"au", "aux", "avec", "ce", "ces", "dans", "de", "des", "du", "elle",
+		"en", "est", "et", "il", "ils", "je", "la", "le", "les", "leur",
+		"mais", "ne", "nous", "on", "ou", "par", "pas", "pour", "qui",
+		"que", "sa", "se", "son", "sur", "un", "une", "vous",
+	},
+	"hu": {
+		"a", "az", "de", "egy", "és", "hogy", "is", "meg", "mint", "nem",
+		"van", "vagy",
+	},
+	"nb": norwegianStopWords,
+	"nn": norwegianStopWords,
+	"no": norwegianStopWords,
+	"ru": {
+		"а", "в", "во", "да", "для", "до", "же", "за", "и", "из", "к", "как",
+		"на", "не", "но", "о", "от", "по", "с", "что", "это", "я",
+	},
+	"sv": {
+		"att", "av", "de", "den", "det", "en", "ett", "för", "har", "i",
+		"inte", "jag", "med", "men", "och", "om", "på", "som", "till", "är",
+	},
+}
+
+var norwegianStopWords = []string{
+	"av", "de", "den", "det", "eg", "ei", "ein", "eit", "en", "er", "et",
+	"for", "han", "ho", "i", "ikkje", "ikke", "jeg", "med", "men", "og",
+	"om", "på", "som", "til", "var",
+}
@@ -0,0 +1,88 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/kljensen/snowball"
+)
+
+// stemmerLanguages maps language codes to the Snowball stemmers. These are
+// the same stemmers as in the lunr-languages plugins, so queries stemmed in
+// the browser match.
+var stemmerLanguages = map[string]string{
+	"en": "english",
+	"es": "spanish",
+	"fr": "french",
+	"hu": "hungarian",
+	"nb": "norwegian",
+	"nn": "norwegian",
+	"no": "norwegian",
+	"ru": "russian",
+	"sv": "swedish",
+}
+
+// Tokenizer splits plain text into the lower case, stemmed words to index,
+// leaving out the stop words.
+type Tokenizer struct {
+	stemmer   string
+	stopWords map[string]bool
+}
+
+// NewTokenizer creates a new Tokenizer for the given language code, e.g.
+// "en" or "en-us". Stemming and stop words are only supported for some
+// languages, see stemmerLanguages and stopWords.
+func NewTokenizer(lang string, cfg Config) *Tokenizer {
+	lang = baseLanguage(lang)
+
+	t := &Tokenizer{stopWords: make(map[string]bool)}
+
+	if !cfg.NoStemming {
+		t.stemmer = stemmerLanguages[lang]
+	}
+
+	for _, w := range stopWords[lang] {
+		t.stopWords[w] = true
+	}
+	for _, w := range cfg.StopWords {
+		t.stopWords[strings.ToLower(w)] = true
+	}
+
+	return t
+}
+
+// Tokens returns the tokens to index for the given words, in order. Words
+// are split further on anything but letters and digits.
+func (t *Tokenizer) Tokens(words []string) []string {
+	var tokens []string
+
+	for _, word := range words {
+		for _, w := range strings.FieldsFunc(strings.ToLower(word), isSeparator) {
+			if t.stopWords[w] {
+				continue
+			}
+			if t.stemmer != "" {
+				if stemmed, err := snowball.Stem(w, t.stemmer, false); err == nil && stemmed != "" {
+					w = stemmed
+				}
+			}
+			tokens = append(tokens, w)
+		}
+	}
+
+	return tokens
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// baseLanguage returns the language part of a language code, e.g. "en" for
+// "en-US".
+func baseLanguage(lang string) string {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "-_"); i != -1 {
+		lang = lang[:i]
+	}
+	return lang
+}
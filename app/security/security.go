@@ -0,0 +1,325 @@
+package security
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/govenue/assist"
+	"github.com/govenue/mapstructure"
+)
+
+// Policy is the security policy for the template functions that read the
+// environment, local files and remote data, and for the external markup
+// helpers. It is configured in the security section of the site config.
+//
+// An example site config.toml:
+//
+//	[security]
+//	exec = ["asciidoctor", "pandoc"]
+//	getenv = ["GEAN_*", "CI"]
+//	readPaths = ["data", "static/files"]
+//
+//	[security.http]
+//	hosts = ["api.example.com", "*.githubusercontent.com"]
+//	methods = ["GET"]
+//
+// Without a security section in the site config nothing is restricted, as
+// before the policy was added. With one, a configured list replaces the
+// default list, and an empty list denies everything, see DefaultPolicy.
+// A nil *Policy restricts nothing.
+type Policy struct {
+	// The external markup helpers allowed to run: asciidoctor, asciidoc,
+	// rst2html and pandoc.
+	Exec []string
+
+	// Glob patterns of the environment variables getenv may read, e.g. "GEAN_*".
+	Getenv []string
+
+	// The directories, relative to the working dir, that readFile, readDir,
+	// fileExists and the data functions may read. "." is the working dir.
+	ReadPaths []string
+
+	HTTP HTTPPolicy
+
+	exec   []*regexp.Regexp
+	getenv []*regexp.Regexp
+
+	unrestricted bool
+}
+
+// HTTPPolicy restricts the remote data functions, e.g. getJSON.
+type HTTPPolicy struct {
+	// Glob patterns of the hosts that may be requested, e.g. "*.example.com".
+	Hosts []string
+
+	// The HTTP methods that may be used.
+	Methods []string
+
+	hosts   []*regexp.Regexp
+	methods []*regexp.Regexp
+}
+
+// DefaultPolicy returns the policy used for the settings not in the security
+// section of the site config.
+// It allows the external markup helpers, reading anything in the working dir
+// and GET, HEAD and POST requests to any host, but only the GEAN_ prefixed and
+// CI environment variables.
+func DefaultPolicy() *Policy {
+	p := &Policy{
+		Exec:      []string{"asciidoctor", "asciidoc", "rst2html", "pandoc"},
+		Getenv:    []string{"GEAN_*", "CI"},
+		ReadPaths: []string{"."},
+		HTTP: HTTPPolicy{
+			Hosts:   []string{"*"},
+			Methods: []string{"GET", "HEAD", "POST"},
+		},
+	}
+
+	if err := p.compile(); err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+var unrestrictedPolicy = &Policy{unrestricted: true}
+
+// DecodeConfig decodes the security section of the site config. A nil section
+// gives a policy restricting nothing.
+func DecodeConfig(in interface{}) (*Policy, error) {
+	if in == nil {
+		return unrestrictedPolicy, nil
+	}
+
+	m, ok := in.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected map[string]interface {} got %T", in)
+	}
+
+	p := &Policy{}
+	if err := mapstructure.WeakDecode(m, p); err != nil {
+		return nil, fmt.Errorf("failed to decode security config: %s", err)
+	}
+
+	// Decoding into the default lists would merge them with the configured
+	// lists, so the lists not configured are set afterwards.
+	d := DefaultPolicy()
+	httpConfig := assist.ToStringMap(lookup(m, "http"))
+
+	if lookup(m, "exec") == nil {
+		p.Exec = d.Exec
+	}
+	if lookup(m, "getenv") == nil {
+		p.Getenv = d.Getenv
+	}
+	if lookup(m, "readPaths") == nil {
+		p.ReadPaths = d.ReadPaths
+	}
+	if lookup(httpConfig, "hosts") == nil {
+		p.HTTP.Hosts = d.HTTP.Hosts
+	}
+	if lookup(httpConfig, "methods") == nil {
+		p.HTTP.Methods = d.HTTP.Methods
+	}
+
+	for i, root := range p.ReadPaths {
+		clean, err := relPath(root)
+		if err != nil {
+			return nil, fmt.Errorf("invalid security.readPaths entry %q: %s", root, err)
+		}
+		p.ReadPaths[i] = clean
+	}
+
+	if err := p.compile(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// lookup returns the value of the given key in the config map, ignoring case.
+func lookup(m map[string]interface{}, key string) interface{} {
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return nil
+}
+
+func (p *Policy) compile() error {
+	var err error
+
+	if p.exec, err = compilePatterns("security.exec", p.Exec, false); err != nil {
+		return err
+	}
+	if p.getenv, err = compilePatterns("security.getenv", p.Getenv, false); err != nil {
+		return err
+	}
+	if p.HTTP.hosts, err = compilePatterns("security.http.hosts", p.HTTP.Hosts, true); err != nil {
+		return err
+	}
+	if p.HTTP.methods, err = compilePatterns("security.http.methods", p.HTTP.Methods, true); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AccessDeniedError is returned when the policy denies an access.
+type AccessDeniedError struct {
+	// What is denied, e.g. "environment variable".
+	What string
+
+	// The name of what is denied, e.g. "HOME".
+	Name string
+
+	// The config setting that would allow it, e.g. "security.getenv".
+	Setting string
+}
+
+func (e *AccessDeniedError) Error() string {
+	return fmt.Sprintf("access denied: %s %q is not allowed by the %s setting", e.What, e.Name, e.Setting)
+}
+
+// IsAccessDenied returns whether the given error is an AccessDeniedError.
+func IsAccessDenied(err error) bool {
+	_, ok := err.(*AccessDeniedError)
+	return ok
+}
+
+// CheckExec checks whether the named external helper, e.g. "pandoc", may run.
+func (p *Policy) CheckExec(name string) error {
+	if p.get().unrestricted {
+		return nil
+	}
+	if !matchAny(p.exec, name) {
+		return &AccessDeniedError{What: "executable", Name: name, Setting: "security.exec"}
+	}
+	return nil
+}
+
+// CheckGetenv checks whether the named environment variable may be read.
+func (p *Policy) CheckGetenv(name string) error {
+	if p.get().unrestricted {
+		return nil
+	}
+	if !matchAny(p.getenv, name) {
+		return &AccessDeniedError{What: "environment variable", Name: name, Setting: "security.getenv"}
+	}
+	return nil
+}
+
+// CheckReadPath checks whether the given path, relative to the working dir,
+// may be read. Paths outside the working dir are only allowed, and left to
+// the file system to resolve, without a security section.
+func (p *Policy) CheckReadPath(name string) error {
+	if p.get().unrestricted {
+		return nil
+	}
+
+	rel, err := relPath(name)
+	if err != nil {
+		return &AccessDeniedError{What: "path", Name: name, Setting: "security.readPaths"}
+	}
+
+	for _, root := range p.ReadPaths {
+		if root == "." || rel == root || strings.HasPrefix(rel, root+"/") {
+			return nil
+		}
+	}
+
+	return &AccessDeniedError{What: "path", Name: name, Setting: "security.readPaths"}
+}
+
+// CheckHTTP checks whether the given URL may be requested with the given method.
+func (p *Policy) CheckHTTP(method string, u *url.URL) error {
+	if p.get().unrestricted {
+		return nil
+	}
+
+	h := p.HTTP
+
+	if !matchAny(h.methods, method) {
+		return &AccessDeniedError{What: "HTTP method", Name: method, Setting: "security.http.methods"}
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return &AccessDeniedError{What: "URL scheme", Name: u.Scheme, Setting: "security.http.hosts"}
+	}
+
+	if !matchAny(h.hosts, u.Hostname()) {
+		return &AccessDeniedError{What: "host", Name: u.Hostname(), Setting: "security.http.hosts"}
+	}
+
+	return nil
+}
+
+// get returns the policy, or the unrestricted policy for a nil policy.
+func (p *Policy) get() *Policy {
+	if p == nil {
+		return unrestrictedPolicy
+	}
+	return p
+}
+
+// relPath returns the given path, which is relative to the working dir even if
+// it starts with a slash, cleaned and slash separated. It is an error if the
+// path is outside the working dir.
+func relPath(name string) (string, error) {
+	rel := filepath.ToSlash(filepath.Clean(strings.TrimLeft(filepath.ToSlash(name), "/")))
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", errors.New("path is outside the working dir")
+	}
+	return rel, nil
+}
+
+func matchAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// compilePatterns compiles the given glob patterns, where "*" matches any run
+// of characters and "?" any single character, to anchored regular expressions.
+func compilePatterns(setting string, patterns []string, ignoreCase bool) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, len(patterns))
+
+	for i, pattern := range patterns {
+		var b bytes.Buffer
+
+		if ignoreCase {
+			b.WriteString("(?i)")
+		}
+		b.WriteString("^")
+
+		for _, r := range pattern {
+			switch r {
+			case '*':
+				b.WriteString(".*")
+			case '?':
+				b.WriteString(".")
+			default:
+				b.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+
+		b.WriteString("$")
+
+		re, err := regexp.Compile(b.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s pattern %q: %s", setting, pattern, err)
+		}
+		res[i] = re
+	}
+
+	return res, nil
+}
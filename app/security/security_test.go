@@ -0,0 +1,161 @@
+package security
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/govenue/require"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	p, err := DecodeConfig(nil)
+	assert.NoError(err)
+	assert.True(p.unrestricted)
+
+	p, err = DecodeConfig(map[string]interface{}{})
+	assert.NoError(err)
+	assert.False(p.unrestricted)
+	assert.Equal(DefaultPolicy().Exec, p.Exec)
+
+	p, err = DecodeConfig(map[string]interface{}{
+		"exec":      []interface{}{"pandoc"},
+		"readpaths": []interface{}{"/data/", "static/files"},
+		"http":      map[string]interface{}{"methods": []interface{}{}},
+	})
+	assert.NoError(err)
+	assert.Equal([]string{"pandoc"}, p.Exec)
+	assert.Equal(DefaultPolicy().Getenv, p.Getenv)
+	assert.Equal([]string{"data", "static/files"}, p.ReadPaths)
+	assert.Equal(DefaultPolicy().HTTP.Hosts, p.HTTP.Hosts)
+	assert.Empty(p.HTTP.Methods)
+
+	for _, in := range []interface{}{
+		"exec",
+		map[string]interface{}{"readPaths": []interface{}{"../themes"}},
+		map[string]interface{}{"http": "all"},
+	} {
+		_, err := DecodeConfig(in)
+		assert.Error(err)
+	}
+}
+
+func TestCheckExec(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	p, err := DecodeConfig(map[string]interface{}{"exec": []interface{}{"asciidoc*"}})
+	assert.NoError(err)
+
+	assert.NoError(p.CheckExec("asciidoctor"))
+	assert.NoError(p.CheckExec("asciidoc"))
+	assert.True(IsAccessDenied(p.CheckExec("pandoc")))
+
+	var nilPolicy *Policy
+	assert.NoError(nilPolicy.CheckExec("pandoc"))
+}
+
+func TestCheckGetenv(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	var nilPolicy *Policy
+	assert.NoError(nilPolicy.CheckGetenv("HOME"))
+
+	p, err := DecodeConfig(map[string]interface{}{})
+	assert.NoError(err)
+
+	assert.NoError(p.CheckGetenv("GEAN_ENV"))
+	assert.NoError(p.CheckGetenv("CI"))
+	assert.Error(p.CheckGetenv("CIRCLE_TOKEN"))
+	assert.Error(p.CheckGetenv("gean_env"))
+
+	err = p.CheckGetenv("HOME")
+	assert.True(IsAccessDenied(err))
+	assert.Contains(err.Error(), `"HOME"`)
+	assert.Contains(err.Error(), "security.getenv")
+}
+
+func TestCheckReadPath(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	var nilPolicy *Policy
+	assert.NoError(nilPolicy.CheckReadPath("config.toml"))
+	assert.NoError(nilPolicy.CheckReadPath("../f2.txt"))
+
+	p, err := DecodeConfig(map[string]interface{}{})
+	assert.NoError(err)
+	assert.NoError(p.CheckReadPath("config.toml"))
+	assert.NoError(p.CheckReadPath("/f/f1.txt"))
+	assert.Error(p.CheckReadPath("../f2.txt"))
+	assert.Error(p.CheckReadPath("f/../../f2.txt"))
+
+	p, err = DecodeConfig(map[string]interface{}{"readPaths": []interface{}{"data"}})
+	assert.NoError(err)
+
+	for i, test := range []struct {
+		path   string
+		expect bool
+	}{
+		{"data", true},
+		{"data/", true},
+		{"/data/episodes.json", true},
+		{"data/../data/episodes.json", true},
+		{"data/../config.toml", false},
+		{"database/episodes.json", false},
+		{"config.toml", false},
+		{"", false},
+	} {
+		err := p.CheckReadPath(test.path)
+		if test.expect {
+			assert.NoError(err, "[%d] %s", i, test.path)
+		} else {
+			assert.True(IsAccessDenied(err), "[%d] %s", i, test.path)
+		}
+	}
+}
+
+func TestCheckHTTP(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	p, err := DecodeConfig(map[string]interface{}{
+		"http": map[string]interface{}{
+			"hosts":   []interface{}{"api.example.com", "*.githubusercontent.com"},
+			"methods": []interface{}{"GET"},
+		},
+	})
+	assert.NoError(err)
+
+	for i, test := range []struct {
+		method string
+		url    string
+		expect bool
+	}{
+		{"GET", "https://api.example.com/episodes", true},
+		{"get", "https://API.example.com:8080/episodes", true},
+		{"GET", "https://raw.githubusercontent.com/gean/data.json", true},
+		{"GET", "https://example.com/", false},
+		{"GET", "https://api.example.com.evil.com/", false},
+		{"POST", "https://api.example.com/episodes", false},
+		{"GET", "file:///etc/passwd", false},
+	} {
+		u, err := url.Parse(test.url)
+		assert.NoError(err)
+
+		err = p.CheckHTTP(test.method, u)
+		if test.expect {
+			assert.NoError(err, "[%d] %s %s", i, test.method, test.url)
+		} else {
+			assert.True(IsAccessDenied(err), "[%d] %s %s", i, test.method, test.url)
+		}
+	}
+}
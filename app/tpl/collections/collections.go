@@ -19,13 +19,15 @@ import (
 // New returns a new instance of the collections-namespaced template functions.
 func New(deps *deps.Deps) *Namespace {
 	return &Namespace{
-		deps: deps,
+		deps:    deps,
+		queries: newQueryCache(),
 	}
 }
 
 // Namespace provides template functions for the "collections" namespace.
 type Namespace struct {
-	deps *deps.Deps
+	deps    *deps.Deps
+	queries *queryCache
 }
 
 // After returns all the items after the first N in a rangeable list.
@@ -84,6 +84,13 @@ func init() {
 			[][2]string{},
 		)
 
+		ns.AddMethodMapping(ctx.Query,
+			[]string{"query"},
+			[][2]string{
+				{`{{ query (slice 5 3 8 1) "value > 2 order by value desc limit 2" }}`, `[8 5]`},
+			},
+		)
+
 		ns.AddMethodMapping(ctx.Querify,
 			[]string{"querify"},
 			[][2]string{
@@ -0,0 +1,988 @@
+package collections
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/govenue/assist"
+)
+
+// Query returns the elements in the given array or slice matching the given
+// query, e.g.:
+//
+//	{{ range query .Site.RegularPages "section = 'episodes' and (params.guest != nil or weight > 5)" }}
+//
+// A query is an expression of comparisons combined with and, or and not, and
+// grouped in parentheses, followed by an optional order by and limit clause:
+//
+//	"draft != true and title =~ '^Ep' order by date desc, title limit 10"
+//
+// The operands are:
+//   - fields, e.g. title or params.guest.name, evaluated like the key in
+//     where, but method and field names may be lower case.
+//     A missing map key is nil. value is the item itself, as in sort.
+//   - strings in single or double quotes, numbers, true, false and nil.
+//   - lists, e.g. ['news', 'episodes'].
+//   - now, optionally with a duration, e.g. now - 30d. The units are
+//     s, m, h, d and w.
+//
+// The operators are =, !=, <, <=, >, >=, in and not in, =~ and !~ for
+// regular expression matches, and exists, e.g. exists params.guest. A field
+// on its own is true if not empty. Strings are compared to dates as dates.
+//
+// The query is compiled once.
+func (ns *Namespace) Query(seq interface{}, query string) (interface{}, error) {
+	seqv, isNil := indirect(reflect.ValueOf(seq))
+	if isNil || !seqv.IsValid() {
+		return nil, errors.New("can't iterate over a nil value")
+	}
+
+	q, err := ns.queries.get(query)
+	if err != nil {
+		return nil, err
+	}
+
+	typ := seqv.Type()
+	switch seqv.Kind() {
+	case reflect.Array:
+		typ = reflect.SliceOf(typ.Elem())
+	case reflect.Slice:
+	default:
+		return nil, fmt.Errorf("can't iterate over %v", seq)
+	}
+
+	ctx := &queryContext{now: time.Now()}
+
+	var items []reflect.Value
+	for i := 0; i < seqv.Len(); i++ {
+		item := seqv.Index(i)
+		if q.filter != nil {
+			ok, err := q.filter.eval(ctx, item)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		items = append(items, item)
+	}
+
+	if len(q.order) > 0 {
+		s := &querySorter{items: items, keys: make([][]interface{}, len(items)), order: q.order}
+		for i, item := range items {
+			for _, o := range q.order {
+				v, err := o.field.value(ctx, item)
+				if err != nil {
+					return nil, err
+				}
+				s.keys[i] = append(s.keys[i], v)
+			}
+		}
+		sort.Stable(s)
+	}
+
+	if q.limit >= 0 && q.limit < len(items) {
+		items = items[:q.limit]
+	}
+
+	rv := reflect.MakeSlice(typ, 0, len(items))
+	for _, item := range items {
+		rv = reflect.Append(rv, item)
+	}
+
+	return rv.Interface(), nil
+}
+
+// maxCachedQueries is the number of compiled queries a queryCache holds
+// before it is cleared, e.g. when the queries are built from page data.
+const maxCachedQueries = 1000
+
+// queryCache caches the compiled queries of a Namespace.
+type queryCache struct {
+	sync.RWMutex
+	queries map[string]*compiledQuery
+}
+
+func newQueryCache() *queryCache {
+	return &queryCache{queries: make(map[string]*compiledQuery)}
+}
+
+// get returns the compiled query, compiling it on first use.
+func (c *queryCache) get(query string) (*compiledQuery, error) {
+	c.RLock()
+	q, found := c.queries[query]
+	c.RUnlock()
+	if found {
+		return q, nil
+	}
+
+	q, err := compileQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	if len(c.queries) >= maxCachedQueries {
+		c.queries = make(map[string]*compiledQuery)
+	}
+	c.queries[query] = q
+	c.Unlock()
+
+	return q, nil
+}
+
+type compiledQuery struct {
+	// nil if no filter.
+	filter queryNode
+	order  []queryOrder
+	// -1 if no limit.
+	limit int
+}
+
+type queryOrder struct {
+	field fieldOperand
+	desc  bool
+}
+
+type queryContext struct {
+	now time.Time
+}
+
+type queryNode interface {
+	eval(ctx *queryContext, item reflect.Value) (bool, error)
+}
+
+type queryOperand interface {
+	value(ctx *queryContext, item reflect.Value) (interface{}, error)
+}
+
+type andNode struct {
+	left, right queryNode
+}
+
+func (n andNode) eval(ctx *queryContext, item reflect.Value) (bool, error) {
+	ok, err := n.left.eval(ctx, item)
+	if !ok || err != nil {
+		return false, err
+	}
+	return n.right.eval(ctx, item)
+}
+
+type orNode struct {
+	left, right queryNode
+}
+
+func (n orNode) eval(ctx *queryContext, item reflect.Value) (bool, error) {
+	ok, err := n.left.eval(ctx, item)
+	if ok || err != nil {
+		return ok, err
+	}
+	return n.right.eval(ctx, item)
+}
+
+type notNode struct {
+	node queryNode
+}
+
+func (n notNode) eval(ctx *queryContext, item reflect.Value) (bool, error) {
+	ok, err := n.node.eval(ctx, item)
+	return !ok, err
+}
+
+type existsNode struct {
+	field fieldOperand
+}
+
+func (n existsNode) eval(ctx *queryContext, item reflect.Value) (bool, error) {
+	_, found, err := n.field.lookup(item)
+	return found, err
+}
+
+type truthNode struct {
+	operand queryOperand
+}
+
+func (n truthNode) eval(ctx *queryContext, item reflect.Value) (bool, error) {
+	v, err := n.operand.value(ctx, item)
+	if err != nil || v == nil {
+		return false, err
+	}
+	truth, _ := template.IsTrue(v)
+	return truth, nil
+}
+
+type compareNode struct {
+	left, right queryOperand
+	op          string
+	re          *regexp.Regexp
+}
+
+func (n compareNode) eval(ctx *queryContext, item reflect.Value) (bool, error) {
+	lv, err := n.left.value(ctx, item)
+	if err != nil {
+		return false, err
+	}
+
+	if n.re != nil {
+		s, err := assist.ToStringE(lv)
+		matched := lv != nil && err == nil && n.re.MatchString(s)
+		return matched == (n.op == "=~"), nil
+	}
+
+	rv, err := n.right.value(ctx, item)
+	if err != nil {
+		return false, err
+	}
+
+	switch n.op {
+	case "=", "==":
+		return queryEqual(lv, rv), nil
+	case "!=", "<>":
+		return !queryEqual(lv, rv), nil
+	case "in":
+		return queryIn(lv, rv), nil
+	case "not in":
+		return !queryIn(lv, rv), nil
+	}
+
+	c, ok := queryCompare(lv, rv)
+	if !ok {
+		return false, nil
+	}
+
+	switch n.op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	case ">=":
+		return c >= 0, nil
+	}
+
+	return false, fmt.Errorf("no such operator %q", n.op)
+}
+
+type literalOperand struct {
+	v interface{}
+}
+
+func (o literalOperand) value(ctx *queryContext, item reflect.Value) (interface{}, error) {
+	return o.v, nil
+}
+
+type listOperand struct {
+	items []queryOperand
+}
+
+func (o listOperand) value(ctx *queryContext, item reflect.Value) (interface{}, error) {
+	var list []interface{}
+	for _, op := range o.items {
+		v, err := op.value(ctx, item)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+type nowOperand struct {
+	offset time.Duration
+}
+
+func (o nowOperand) value(ctx *queryContext, item reflect.Value) (interface{}, error) {
+	return ctx.now.Add(o.offset), nil
+}
+
+type fieldOperand struct {
+	path []string
+}
+
+func (o fieldOperand) value(ctx *queryContext, item reflect.Value) (interface{}, error) {
+	v, found, err := o.lookup(item)
+	if !found || err != nil {
+		return nil, err
+	}
+
+	v, isNil := indirectInterface(v)
+	if isNil || !v.IsValid() || !v.CanInterface() {
+		return nil, nil
+	}
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil, nil
+	}
+
+	return v.Interface(), nil
+}
+
+// lookup evaluates the field path on the given item. It returns false if a
+// map key in the path is missing or a value in the path is nil.
+// As in sort, value is the item itself, unless a map or struct.
+func (o fieldOperand) lookup(item reflect.Value) (reflect.Value, bool, error) {
+	if len(o.path) == 1 && o.path[0] == "value" {
+		if iv, _ := indirect(item); iv.Kind() != reflect.Map && iv.Kind() != reflect.Struct {
+			return item, true, nil
+		}
+	}
+
+	v := item
+	for _, name := range o.path {
+		vv, isNil := indirect(v)
+		if isNil || !vv.IsValid() {
+			return zero, false, nil
+		}
+
+		var err error
+		v, err = evaluateQueryElem(v, name)
+		if err != nil {
+			if vv.Kind() != reflect.Struct {
+				// E.g. a string param where a map was expected.
+				return zero, false, nil
+			}
+			return zero, false, err
+		}
+		if !v.IsValid() {
+			return zero, false, nil
+		}
+	}
+	return v, true, nil
+}
+
+// evaluateQueryElem is evaluateSubElem, falling back to the exported name
+// of a method or field, e.g. Section for section, and the lower case key
+// of a map, e.g. guest for Guest.
+func evaluateQueryElem(obj reflect.Value, name string) (reflect.Value, error) {
+	v, err := evaluateSubElem(obj, name)
+	if err == nil && v.IsValid() {
+		return v, nil
+	}
+
+	if vv, _ := indirect(obj); vv.Kind() == reflect.Map {
+		if lower := strings.ToLower(name); lower != name {
+			return evaluateSubElem(obj, lower)
+		}
+		return v, err
+	}
+
+	if err != nil {
+		exported := strings.ToUpper(name[:1]) + name[1:]
+		if exported != name {
+			if v, err2 := evaluateSubElem(obj, exported); err2 == nil {
+				return v, nil
+			}
+		}
+	}
+
+	return v, err
+}
+
+// querySorter sorts the query result by the order by clause. Nil values
+// are always sorted last.
+type querySorter struct {
+	items []reflect.Value
+	keys  [][]interface{}
+	order []queryOrder
+}
+
+func (s *querySorter) Len() int { return len(s.items) }
+
+func (s *querySorter) Swap(i, j int) {
+	s.items[i], s.items[j] = s.items[j], s.items[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+func (s *querySorter) Less(i, j int) bool {
+	for k, o := range s.order {
+		vi, vj := s.keys[i][k], s.keys[j][k]
+		if vi == nil || vj == nil {
+			if (vi == nil) != (vj == nil) {
+				return vj == nil
+			}
+			continue
+		}
+		c, ok := queryCompare(vi, vj)
+		if !ok || c == 0 {
+			continue
+		}
+		if o.desc {
+			return c > 0
+		}
+		return c < 0
+	}
+	return false
+}
+
+// queryNormalize converts numbers to float64 and dereferences pointers.
+func queryNormalize(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	rv, isNil := indirect(reflect.ValueOf(v))
+	if isNil || !rv.IsValid() || !rv.CanInterface() {
+		return nil
+	}
+	if isNumber(rv.Kind()) {
+		f, _ := numberToFloat(rv)
+		return f
+	}
+	if rv.Kind() == reflect.String {
+		return rv.String()
+	}
+	return rv.Interface()
+}
+
+func queryEqual(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if c, ok := queryCompare(a, b); ok {
+		return c == 0
+	}
+	if ab, ok := a.(bool); ok {
+		if bb, ok := b.(bool); ok {
+			return ab == bb
+		}
+	}
+	return comp.Eq(a, b)
+}
+
+// queryCompare compares numbers, strings and dates, returning false if the
+// given values can't be compared.
+func queryCompare(a, b interface{}) (int, bool) {
+	a, b = queryNormalize(a), queryNormalize(b)
+
+	if at, ok := a.(time.Time); ok {
+		bt, err := assist.ToTimeE(b)
+		if err != nil {
+			return 0, false
+		}
+		return compareTimes(at, bt), true
+	}
+	if bt, ok := b.(time.Time); ok {
+		at, err := assist.ToTimeE(a)
+		if err != nil {
+			return 0, false
+		}
+		return compareTimes(at, bt), true
+	}
+
+	switch av := a.(type) {
+	case float64:
+		if bv, ok := b.(float64); ok {
+			switch {
+			case av < bv:
+				return -1, true
+			case av > bv:
+				return 1, true
+			}
+			return 0, true
+		}
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(av, bv), true
+		}
+	}
+
+	return 0, false
+}
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+// queryIn returns whether v is in the given list, or a substring of the
+// given string.
+func queryIn(v, list interface{}) bool {
+	if v == nil || list == nil {
+		return false
+	}
+
+	if s, ok := list.(string); ok {
+		vs, err := assist.ToStringE(v)
+		return err == nil && strings.Contains(s, vs)
+	}
+
+	lv, isNil := indirect(reflect.ValueOf(list))
+	if isNil {
+		return false
+	}
+
+	switch lv.Kind() {
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < lv.Len(); i++ {
+			item := lv.Index(i)
+			if !item.CanInterface() {
+				continue
+			}
+			if queryEqual(v, item.Interface()) {
+				return true
+			}
+		}
+	case reflect.String:
+		vs, err := assist.ToStringE(v)
+		return err == nil && strings.Contains(lv.String(), vs)
+	}
+
+	return false
+}
+
+type queryTokenType int
+
+const (
+	queryEOF queryTokenType = iota
+	queryIdent
+	queryString
+	queryNumber
+	queryDuration
+	queryOp
+	queryLeftParen
+	queryRightParen
+	queryLeftBracket
+	queryRightBracket
+	queryComma
+)
+
+type queryToken struct {
+	typ queryTokenType
+	val string
+	pos int
+}
+
+// The operators, longest first.
+var queryOperators = []string{"==", "!=", "<>", "<=", ">=", "=~", "!~", "&&", "||", "=", "<", ">", "!", "-", "+"}
+
+func lexQuery(query string) ([]queryToken, error) {
+	var (
+		tokens []queryToken
+		runes  = []rune(query)
+	)
+
+	isIdent := func(r rune) bool {
+		return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{queryLeftParen, "(", start})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{queryRightParen, ")", start})
+			i++
+		case r == '[':
+			tokens = append(tokens, queryToken{queryLeftBracket, "[", start})
+			i++
+		case r == ']':
+			tokens = append(tokens, queryToken{queryRightBracket, "]", start})
+			i++
+		case r == ',':
+			tokens = append(tokens, queryToken{queryComma, ",", start})
+			i++
+		case r == '\'' || r == '"':
+			var s []rune
+			i++
+			for ; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				s = append(s, runes[i])
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++
+			tokens = append(tokens, queryToken{queryString, string(s), start})
+		case unicode.IsDigit(r):
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			typ := queryNumber
+			if i < len(runes) && strings.ContainsRune("smhdw", runes[i]) && (i+1 == len(runes) || !isIdent(runes[i+1])) {
+				typ = queryDuration
+				i++
+			}
+			tokens = append(tokens, queryToken{typ, string(runes[start:i]), start})
+		case r == '_' || unicode.IsLetter(r):
+			for i < len(runes) && isIdent(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, queryToken{queryIdent, string(runes[start:i]), start})
+		default:
+			var op string
+			for _, candidate := range queryOperators {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at position %d", r, start)
+			}
+			i += len([]rune(op))
+			tokens = append(tokens, queryToken{queryOp, op, start})
+		}
+	}
+
+	return append(tokens, queryToken{queryEOF, "", len(runes)}), nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func compileQuery(query string) (*compiledQuery, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query %q: %s", query, err)
+	}
+
+	p := &queryParser{tokens: tokens}
+
+	q, err := p.parseQuery()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query %q: %s", query, err)
+	}
+
+	return q, nil
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	t := p.tokens[p.pos]
+	if t.typ != queryEOF {
+		p.pos++
+	}
+	return t
+}
+
+// isKeyword returns whether the next token is one of the given keywords.
+func (p *queryParser) isKeyword(keywords ...string) bool {
+	t := p.peek()
+	if t.typ != queryIdent {
+		return false
+	}
+	for _, keyword := range keywords {
+		if strings.EqualFold(t.val, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *queryParser) isOp(ops ...string) bool {
+	t := p.peek()
+	if t.typ != queryOp {
+		return false
+	}
+	for _, op := range ops {
+		if t.val == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *queryParser) unexpected() error {
+	t := p.peek()
+	if t.typ == queryEOF {
+		return errors.New("unexpected end of query")
+	}
+	return fmt.Errorf("unexpected %q at position %d", t.val, t.pos)
+}
+
+func (p *queryParser) parseQuery() (*compiledQuery, error) {
+	q := &compiledQuery{limit: -1}
+
+	if p.peek().typ != queryEOF && !p.isKeyword("order", "limit") {
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		q.filter = filter
+	}
+
+	if p.isKeyword("order") {
+		p.next()
+		if !p.isKeyword("by") {
+			return nil, p.unexpected()
+		}
+		p.next()
+		for {
+			field, err := p.parseField()
+			if err != nil {
+				return nil, err
+			}
+			o := queryOrder{field: field}
+			if p.isKeyword("asc", "desc") {
+				o.desc = strings.EqualFold(p.next().val, "desc")
+			}
+			q.order = append(q.order, o)
+			if p.peek().typ != queryComma {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if p.isKeyword("limit") {
+		p.next()
+		t := p.next()
+		limit, err := strconv.Atoi(t.val)
+		if t.typ != queryNumber || err != nil {
+			return nil, fmt.Errorf("invalid limit %q at position %d", t.val, t.pos)
+		}
+		q.limit = limit
+	}
+
+	if p.peek().typ != queryEOF {
+		return nil, p.unexpected()
+	}
+
+	return q, nil
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") || p.isOp("||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") || p.isOp("&&") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseNot() (queryNode, error) {
+	if p.isKeyword("not") || p.isOp("!") {
+		p.next()
+		node, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	if p.peek().typ == queryLeftParen {
+		p.next()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().typ != queryRightParen {
+			return nil, p.unexpected()
+		}
+		p.next()
+		return node, nil
+	}
+
+	if p.isKeyword("exists") {
+		p.next()
+		field, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		return existsNode{field}, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	var op string
+	switch {
+	case p.isOp("=", "==", "!=", "<>", "<", "<=", ">", ">=", "=~", "!~"):
+		op = p.next().val
+	case p.isKeyword("in"):
+		p.next()
+		op = "in"
+	case p.isKeyword("not") && p.tokens[p.pos+1].typ == queryIdent && strings.EqualFold(p.tokens[p.pos+1].val, "in"):
+		p.next()
+		p.next()
+		op = "not in"
+	default:
+		return truthNode{left}, nil
+	}
+
+	if op == "=~" || op == "!~" {
+		t := p.next()
+		if t.typ != queryString {
+			return nil, fmt.Errorf("expected a regular expression string at position %d", t.pos)
+		}
+		re, err := regexp.Compile(t.val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression at position %d: %s", t.pos, err)
+		}
+		return compareNode{left: left, op: op, re: re}, nil
+	}
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	return compareNode{left: left, right: right, op: op}, nil
+}
+
+func (p *queryParser) parseField() (fieldOperand, error) {
+	t := p.peek()
+	if t.typ != queryIdent || strings.HasPrefix(t.val, ".") || strings.HasSuffix(t.val, ".") || strings.Contains(t.val, "..") {
+		return fieldOperand{}, p.unexpected()
+	}
+	p.next()
+	return fieldOperand{path: strings.Split(t.val, ".")}, nil
+}
+
+func (p *queryParser) parseOperand() (queryOperand, error) {
+	t := p.peek()
+
+	switch t.typ {
+	case queryString:
+		p.next()
+		return literalOperand{t.val}, nil
+	case queryNumber:
+		p.next()
+		return parseQueryNumber(t, false)
+	case queryOp:
+		if t.val == "-" && p.tokens[p.pos+1].typ == queryNumber {
+			p.next()
+			return parseQueryNumber(p.next(), true)
+		}
+	case queryLeftBracket:
+		p.next()
+		var list listOperand
+		for p.peek().typ != queryRightBracket {
+			if len(list.items) > 0 {
+				if p.peek().typ != queryComma {
+					return nil, p.unexpected()
+				}
+				p.next()
+			}
+			item, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			list.items = append(list.items, item)
+		}
+		p.next()
+		return list, nil
+	case queryIdent:
+		switch strings.ToLower(t.val) {
+		case "true", "false":
+			p.next()
+			return literalOperand{strings.EqualFold(t.val, "true")}, nil
+		case "nil", "null":
+			p.next()
+			return literalOperand{nil}, nil
+		case "now":
+			p.next()
+			var now nowOperand
+			if p.isOp("-", "+") {
+				sign := p.next().val
+				d := p.next()
+				if d.typ != queryDuration {
+					return nil, fmt.Errorf("expected a duration at position %d", d.pos)
+				}
+				offset, err := parseQueryDuration(d.val)
+				if err != nil {
+					return nil, fmt.Errorf("invalid duration %q at position %d", d.val, d.pos)
+				}
+				if sign == "-" {
+					offset = -offset
+				}
+				now.offset = offset
+			}
+			return now, nil
+		}
+		return p.parseField()
+	}
+
+	return nil, p.unexpected()
+}
+
+func parseQueryNumber(t queryToken, negative bool) (queryOperand, error) {
+	s := t.val
+	if negative {
+		s = "-" + s
+	}
+	if !strings.Contains(s, ".") {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return literalOperand{i}, nil
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q at position %d", t.val, t.pos)
+	}
+	return literalOperand{f}, nil
+}
+
+// parseQueryDuration parses durations like 30d, with the units s, m, h, d
+// (days) and w (weeks).
+func parseQueryDuration(s string) (time.Duration, error) {
+	unit := s[len(s)-1:]
+	n, err := strconv.ParseFloat(s[:len(s)-1], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	switch unit {
+	case "d":
+		return time.Duration(n * float64(24*time.Hour)), nil
+	case "w":
+		return time.Duration(n * float64(7*24*time.Hour)), nil
+	}
+
+	return time.ParseDuration(s)
+}
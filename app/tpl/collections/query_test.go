@@ -0,0 +1,157 @@
+package collections
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/geego/gean/app/deps"
+	"github.com/govenue/assert"
+	"github.com/govenue/require"
+)
+
+type tstQueryPage struct {
+	Title   string
+	Weight  int
+	Date    time.Time
+	Params  map[string]interface{}
+	section string
+}
+
+func (p *tstQueryPage) Section() string {
+	return p.section
+}
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+
+	ns := New(&deps.Deps{})
+
+	now := time.Now()
+	pages := []*tstQueryPage{
+		{Title: "Ep 1", Weight: 1, Date: now.Add(-60 * 24 * time.Hour), section: "episodes",
+			Params: map[string]interface{}{"guest": "Alice", "rating": 4.5, "tags": []interface{}{"go", "web"}}},
+		{Title: "Ep 2", Weight: 10, Date: now.Add(-10 * 24 * time.Hour), section: "episodes",
+			Params: map[string]interface{}{}},
+		{Title: "Ep 3", Weight: 3, Date: now.Add(-5 * 24 * time.Hour), section: "episodes",
+			Params: map[string]interface{}{"rating": 3}},
+		{Title: "News", Weight: 7, Date: now.Add(5 * 24 * time.Hour), section: "news",
+			Params: map[string]interface{}{"guest": map[string]interface{}{"name": "Bob"}}},
+	}
+
+	titles := func(result interface{}) []string {
+		var t []string
+		for _, p := range result.([]*tstQueryPage) {
+			t = append(t, p.Title)
+		}
+		return t
+	}
+
+	for i, test := range []struct {
+		query  string
+		expect interface{}
+	}{
+		{"section = 'episodes' and (params.guest != nil or weight > 5)", []string{"Ep 1", "Ep 2"}},
+		{"Section = 'episodes' AND Weight > 5", []string{"Ep 2"}},
+		{"weight = 10 || weight == 1", []string{"Ep 1", "Ep 2"}},
+		{"not section = 'episodes'", []string{"News"}},
+		{"exists params.guest", []string{"Ep 1", "News"}},
+		{"params.guest.name = \"Bob\"", []string{"News"}},
+		{"params.guest", []string{"Ep 1", "News"}},
+		{"params.rating > 4", []string{"Ep 1"}},
+		{"params.rating = 3.0", []string{"Ep 3"}},
+		{"weight > -1 and weight <= 3", []string{"Ep 1", "Ep 3"}},
+		{"title =~ '^Ep [23]$'", []string{"Ep 2", "Ep 3"}},
+		{"title !~ '^Ep'", []string{"News"}},
+		{"date < now - 7d", []string{"Ep 1", "Ep 2"}},
+		{"date < now - 8w", []string{"Ep 1"}},
+		{"date > now", []string{"News"}},
+		{"date >= '2000-01-01' and date < '2100-01-01'", []string{"Ep 1", "Ep 2", "Ep 3", "News"}},
+		{"section in ['news', 'other']", []string{"News"}},
+		{"section not in ['news']", []string{"Ep 1", "Ep 2", "Ep 3"}},
+		{"'go' in params.tags", []string{"Ep 1"}},
+		{"section not in ['news'] order by weight desc limit 2", []string{"Ep 2", "Ep 3"}},
+		{"order by params.guest, title", []string{"Ep 1", "News", "Ep 2", "Ep 3"}},
+		{"order by section desc, weight", []string{"News", "Ep 1", "Ep 3", "Ep 2"}},
+		{"limit 1", []string{"Ep 1"}},
+		{"", []string{"Ep 1", "Ep 2", "Ep 3", "News"}},
+		{"title = ", false},
+		{"title =~ '['", false},
+		{"(title = 'Ep 1'", false},
+		{"title = 'Ep 1' limit x", false},
+		{"weight > 5 order weight", false},
+		{"title = 'unterminated", false},
+		{"date < now - 7", false},
+		{"nosuchfield = 1", false},
+	} {
+		errMsg := fmt.Sprintf("[%d] %s", i, test.query)
+
+		result, err := ns.Query(pages, test.query)
+
+		if b, ok := test.expect.(bool); ok && !b {
+			require.Error(t, err, errMsg)
+			continue
+		}
+
+		require.NoError(t, err, errMsg)
+		assert.Equal(t, test.expect, titles(result), errMsg)
+	}
+}
+
+func TestQueryValues(t *testing.T) {
+	t.Parallel()
+
+	ns := New(&deps.Deps{})
+
+	for i, test := range []struct {
+		seq    interface{}
+		query  string
+		expect interface{}
+	}{
+		{[]int{5, 3, 8, 1}, "value > 2 order by value desc limit 2", []int{8, 5}},
+		{[3]string{"b", "a", "c"}, "value != 'c' order by value", []string{"a", "b"}},
+		{[]map[string]interface{}{{"a": 1}, {"a": 3}, {"b": 2}}, "a >= 2 or exists b", []map[string]interface{}{{"a": 3}, {"b": 2}}},
+		{[]map[string]interface{}{{"a": 1}, {"a": 3}, {"b": 2}}, "order by a desc", []map[string]interface{}{{"a": 3}, {"a": 1}, {"b": 2}}},
+		{nil, "a = 1", false},
+		{(*[]int)(nil), "a = 1", false},
+		{"abc", "a = 1", false},
+	} {
+		errMsg := fmt.Sprintf("[%d] %v %s", i, test.seq, test.query)
+
+		result, err := ns.Query(test.seq, test.query)
+
+		if b, ok := test.expect.(bool); ok && !b {
+			require.Error(t, err, errMsg)
+			continue
+		}
+
+		require.NoError(t, err, errMsg)
+		assert.Equal(t, test.expect, result, errMsg)
+	}
+}
+
+func TestQueryCache(t *testing.T) {
+	t.Parallel()
+
+	c := newQueryCache()
+
+	q1, err := c.get("weight > 1 order by title")
+	require.NoError(t, err)
+	q2, err := c.get("weight > 1 order by title")
+	require.NoError(t, err)
+	require.True(t, q1 == q2)
+
+	_, err = c.get("title = ")
+	require.Error(t, err)
+	require.Len(t, c.queries, 1)
+
+	for i := 0; i < maxCachedQueries; i++ {
+		_, err := c.get(fmt.Sprintf("weight > %d", i))
+		require.NoError(t, err)
+	}
+	require.Len(t, c.queries, 1)
+
+	q3, err := c.get("weight > 1 order by title")
+	require.NoError(t, err)
+	require.False(t, q1 == q3)
+}
@@ -4,7 +4,9 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"errors"
+	"os"
 	"sync"
+	"time"
 
 	"github.com/geego/gean/app/config"
 	"github.com/geego/gean/app/helpers"
@@ -67,6 +69,74 @@ func writeCache(id string, c []byte, fs fsintra.Fs, cfg config.Provider, ignoreC
 	return nil
 }
 
+// cacheEntry is a cached remote file.
+type cacheEntry struct {
+	content []byte
+	modTime time.Time
+	etag    string
+}
+
+// isFresh returns whether the entry is younger than maxAge. A negative maxAge
+// means no expiry.
+func (e *cacheEntry) isFresh(maxAge time.Duration) bool {
+	return maxAge < 0 || time.Since(e.modTime) < maxAge
+}
+
+// getCacheEntry returns the cache entry for an ID with its age and ETag, or
+// nil if not found.
+func getCacheEntry(id string, fs fsintra.Fs, cfg config.Provider, ignoreCache bool) (*cacheEntry, error) {
+	if ignoreCache {
+		return nil, nil
+	}
+
+	cacheMu.RLock()
+	defer cacheMu.RUnlock()
+
+	fID := getCacheFileID(cfg, id)
+	fi, err := fs.Stat(fID)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := fsintra.ReadFile(fs, fID)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &cacheEntry{content: c, modTime: fi.ModTime()}
+	if etag, err := fsintra.ReadFile(fs, fID+".etag"); err == nil {
+		e.etag = string(etag)
+	}
+
+	return e, nil
+}
+
+// writeCacheEntry writes bytes associated with an ID into the file cache, with
+// the ETag to revalidate them with, if any.
+func writeCacheEntry(id string, c []byte, etag string, fs fsintra.Fs, cfg config.Provider, ignoreCache bool) error {
+	if err := writeCache(id, c, fs, cfg, ignoreCache); err != nil || ignoreCache {
+		return err
+	}
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	fID := getCacheFileID(cfg, id) + ".etag"
+	if etag == "" {
+		if err := fs.Remove(fID); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	return fsintra.WriteFile(fs, fID, []byte(etag), 0644)
+}
+
 func deleteCache(id string, fs fsintra.Fs, cfg config.Provider) error {
-	return fs.Remove(getCacheFileID(cfg, id))
+	fID := getCacheFileID(cfg, id)
+	fs.Remove(fID + ".etag")
+	return fs.Remove(fID)
 }
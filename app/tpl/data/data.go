@@ -5,20 +5,22 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/geego/gean/app/deps"
+	"github.com/geego/gean/app/parser"
+	"github.com/govenue/assist"
 	"github.com/govenue/notepad"
 )
 
 // New returns a new instance of the data-namespaced template functions.
 func New(deps *deps.Deps) *Namespace {
-	return &Namespace{
-		deps:   deps,
-		client: http.DefaultClient,
-	}
+	ns := &Namespace{deps: deps}
+	ns.client = &http.Client{CheckRedirect: ns.checkRedirect}
+	return ns
 }
 
 // Namespace provides template functions for the "data" namespace.
@@ -28,33 +30,46 @@ type Namespace struct {
 	client *http.Client
 }
 
+// checkRedirect checks every redirect against the security policy, as only
+// the first request is checked in getResource.
+func (ns *Namespace) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	return ns.deps.Security.CheckHTTP(req.Method, req.URL)
+}
+
 // GetCSV expects a data separator and one or n-parts of a URL to a resource which
 // can either be a local or a remote one.
 // The data separator can be a comma, semi-colon, pipe, etc, but only one character.
 // If you provide multiple parts for the URL they will be joined together to the final URL.
+// An options map, see GetData, may be given after the URL.
 // GetCSV returns nil or a slice slice to use in a short code.
-func (ns *Namespace) GetCSV(sep string, urlParts ...string) (d [][]string, err error) {
-	url := strings.Join(urlParts, "")
+func (ns *Namespace) GetCSV(sep string, args ...interface{}) (d [][]string, err error) {
+	url, opts, err := ns.parseArgs(args)
+	if err != nil {
+		notepad.ERROR.Printf("Failed to get csv resource: %s", err)
+		return nil, err
+	}
 
 	var clearCacheSleep = func(i int, u string) {
-		notepad.ERROR.Printf("Retry #%d for %s and sleeping for %s", i, url, resSleep)
-		time.Sleep(resSleep)
-		deleteCache(url, ns.deps.Fs.Source, ns.deps.Cfg)
+		deleteCache(opts.cacheID(url), ns.deps.Fs.Source, ns.deps.Cfg)
+		if i < resRetries {
+			notepad.ERROR.Printf("Retry #%d for %s and sleeping for %s", i+1, url, retrySleep(i))
+			time.Sleep(retrySleep(i))
+		}
 	}
 
 	for i := 0; i <= resRetries; i++ {
 		var req *http.Request
-		req, err = http.NewRequest("GET", url, nil)
+		req, err = opts.newRequest(url, "text/csv", "text/plain")
 		if err != nil {
-			notepad.ERROR.Printf("Failed to create request for getJSON: %s", err)
+			notepad.ERROR.Printf("Failed to create request for getCSV: %s", err)
 			return nil, err
 		}
 
-		req.Header.Add("Accept", "text/csv")
-		req.Header.Add("Accept", "text/plain")
-
 		var c []byte
-		c, err = ns.getResource(req)
+		c, err = ns.getResource(req, opts)
 		if err != nil {
 			notepad.ERROR.Printf("Failed to read csv resource %q with error message %s", url, err)
 			return nil, err
@@ -77,22 +92,25 @@ func (ns *Namespace) GetCSV(sep string, urlParts ...string) (d [][]string, err e
 
 // GetJSON expects one or n-parts of a URL to a resource which can either be a local or a remote one.
 // If you provide multiple parts they will be joined together to the final URL.
+// An options map, see GetData, may be given after the URL.
 // GetJSON returns nil or parsed JSON to use in a short code.
-func (ns *Namespace) GetJSON(urlParts ...string) (v interface{}, err error) {
-	url := strings.Join(urlParts, "")
+func (ns *Namespace) GetJSON(args ...interface{}) (v interface{}, err error) {
+	url, opts, err := ns.parseArgs(args)
+	if err != nil {
+		notepad.ERROR.Printf("Failed to get json resource: %s", err)
+		return nil, err
+	}
 
 	for i := 0; i <= resRetries; i++ {
 		var req *http.Request
-		req, err = http.NewRequest("GET", url, nil)
+		req, err = opts.newRequest(url, "application/json")
 		if err != nil {
 			notepad.ERROR.Printf("Failed to create request for getJSON: %s", err)
 			return nil, err
 		}
 
-		req.Header.Add("Accept", "application/json")
-
 		var c []byte
-		c, err = ns.getResource(req)
+		c, err = ns.getResource(req, opts)
 		if err != nil {
 			notepad.ERROR.Printf("Failed to get json resource %s with error message %s", url, err)
 			return nil, err
@@ -101,9 +119,70 @@ func (ns *Namespace) GetJSON(urlParts ...string) (v interface{}, err error) {
 		err = json.Unmarshal(c, &v)
 		if err != nil {
 			notepad.ERROR.Printf("Cannot read json from resource %s with error message %s", url, err)
-			notepad.ERROR.Printf("Retry #%d for %s and sleeping for %s", i, url, resSleep)
-			time.Sleep(resSleep)
-			deleteCache(url, ns.deps.Fs.Source, ns.deps.Cfg)
+			deleteCache(opts.cacheID(url), ns.deps.Fs.Source, ns.deps.Cfg)
+			if i < resRetries {
+				notepad.ERROR.Printf("Retry #%d for %s and sleeping for %s", i+1, url, retrySleep(i))
+				time.Sleep(retrySleep(i))
+			}
+			continue
+		}
+		break
+	}
+	return
+}
+
+// GetData expects one or n-parts of a URL to a resource which can either be a local or a remote one,
+// optionally followed by an options map with:
+//
+//	method:  the HTTP method, GET by default.
+//	headers: a map of HTTP headers, e.g. an Authorization header.
+//	body:    the request body.
+//	timeout: the request timeout, e.g. "10s", or the remoteDataTimeout config, 30s by default.
+//	maxAge:  how long to cache the data, e.g. "1h", or the remoteDataMaxAge config. -1, the
+//	         default, caches the data until the cache is cleared and 0 disables the cache.
+//	format:  the data format, see below.
+//
+// Stale cached data is revalidated with its ETag, if any. When running with --offline, only
+// cached data is used, whatever its age, and uncached remote data is an error.
+//
+// GetData returns the data parsed in the format given in the options, or by the URL's
+// extension, e.g. .yaml, or else detected from the data. The formats are json, yaml, toml,
+// xml, csv and tsv, see the parser package's UnmarshalData.
+func (ns *Namespace) GetData(args ...interface{}) (v interface{}, err error) {
+	url, opts, err := ns.parseArgs(args)
+	if err != nil {
+		notepad.ERROR.Printf("Failed to get data resource: %s", err)
+		return nil, err
+	}
+
+	format := opts.format
+	if format == "" {
+		format = dataFormatFromURL(url)
+	}
+
+	for i := 0; i <= resRetries; i++ {
+		var req *http.Request
+		req, err = opts.newRequest(url, dataFormatAccept[format]...)
+		if err != nil {
+			notepad.ERROR.Printf("Failed to create request for getData: %s", err)
+			return nil, err
+		}
+
+		var c []byte
+		c, err = ns.getResource(req, opts)
+		if err != nil {
+			notepad.ERROR.Printf("Failed to get data resource %s with error message %s", url, err)
+			return nil, err
+		}
+
+		v, err = parser.UnmarshalData(c, format)
+		if err != nil {
+			notepad.ERROR.Printf("Cannot read data from resource %s with error message %s", url, err)
+			deleteCache(opts.cacheID(url), ns.deps.Fs.Source, ns.deps.Cfg)
+			if i < resRetries {
+				notepad.ERROR.Printf("Retry #%d for %s and sleeping for %s", i+1, url, retrySleep(i))
+				time.Sleep(retrySleep(i))
+			}
 			continue
 		}
 		break
@@ -111,6 +190,33 @@ func (ns *Namespace) GetJSON(urlParts ...string) (v interface{}, err error) {
 	return
 }
 
+// parseArgs returns the URL joined from the given URL parts and the request
+// options from the options map after them, if any.
+func (ns *Namespace) parseArgs(args []interface{}) (string, remoteOptions, error) {
+	var m map[string]interface{}
+
+	if len(args) > 0 {
+		switch v := args[len(args)-1].(type) {
+		case map[string]interface{}, map[interface{}]interface{}:
+			m = assist.ToStringMap(v)
+			args = args[:len(args)-1]
+		}
+	}
+
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		part, err := assist.ToStringE(arg)
+		if err != nil {
+			return "", remoteOptions{}, fmt.Errorf("invalid URL part %v: %s", arg, err)
+		}
+		parts[i] = part
+	}
+
+	opts, err := newRemoteOptions(ns.deps.Cfg, m)
+
+	return strings.Join(parts, ""), opts, err
+}
+
 // parseCSV parses bytes of CSV data into a slice slice string or an error
 func parseCSV(c []byte, sep string) ([][]string, error) {
 	if len(sep) != 1 {
@@ -2,12 +2,14 @@ package data
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/geego/gean/app/security"
 	"github.com/govenue/assert"
 	"github.com/govenue/configurator"
 	"github.com/govenue/require"
@@ -187,6 +189,179 @@ func TestGetJSON(t *testing.T) {
 	}
 }
 
+func TestGetJSONWithOptions(t *testing.T) {
+	t.Parallel()
+
+	ns := New(newDeps(configurator.New()))
+
+	var srv *httptest.Server
+	srv, ns.client = getTestServer(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		if r.Method != "POST" || r.Header.Get("Authorization") != "Bearer xyz" || string(b) != `{"q":1}` {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"gomeetup":"Sydney"}`))
+	})
+	defer func() { srv.Close() }()
+
+	opts := map[string]interface{}{
+		"method":  "post",
+		"headers": map[string]interface{}{"Authorization": "Bearer xyz"},
+		"body":    `{"q":1}`,
+	}
+
+	got, err := ns.GetJSON("http://success/", "options", opts)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"gomeetup": "Sydney"}, got)
+
+	_, err = ns.GetJSON("http://success/options", map[string]interface{}{"nosuch": true})
+	require.Error(t, err)
+
+	_, err = ns.GetJSON("http://success/options", map[string]interface{}{"method": "post"})
+	require.Error(t, err)
+}
+
+func TestGetData(t *testing.T) {
+	t.Parallel()
+
+	ns := New(newDeps(configurator.New()))
+
+	for i, test := range []struct {
+		args    []interface{}
+		accept  string
+		content string
+		expect  interface{}
+	}{
+		{[]interface{}{"http://success/episodes.yaml"}, "application/yaml", "gomeetup: Sydney", map[string]interface{}{"gomeetup": "Sydney"}},
+		{[]interface{}{"http://success/episodes.toml?page=2"}, "application/toml", `gomeetup = "Sydney"`, map[string]interface{}{"gomeetup": "Sydney"}},
+		{[]interface{}{"http://success/episodes.xml"}, "application/xml", "<gomeetup>Sydney</gomeetup>", map[string]interface{}{"gomeetup": "Sydney"}},
+		{[]interface{}{"http://success/", "episodes"}, "", `{"gomeetup":"Sydney"}`, map[string]interface{}{"gomeetup": "Sydney"}},
+		{[]interface{}{"http://success/episodes", map[string]interface{}{"format": "csv"}}, "text/csv", "gomeetup,city\nyes,Sydney", [][]string{{"gomeetup", "city"}, {"yes", "Sydney"}}},
+		// errors
+		{[]interface{}{"http://malformed/episodes.json"}, "application/json", `{gomeetup:"Sydney"}`, false},
+		{[]interface{}{"http://malformed/episodes", map[string]interface{}{"format": "ini"}}, "", `gomeetup = "Sydney"`, false},
+		{[]interface{}{"http://nofound/404"}, "", "", false},
+	} {
+		msg := fmt.Sprintf("Test %d", i)
+
+		var srv *httptest.Server
+		srv, ns.client = getTestServer(func(w http.ResponseWriter, r *http.Request) {
+			if test.accept != "" && !haveHeader(r.Header, "Accept", test.accept) {
+				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return
+			}
+
+			if r.URL.Path == "/404" {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				return
+			}
+
+			w.Write([]byte(test.content))
+		})
+		defer func() { srv.Close() }()
+
+		got, err := ns.GetData(test.args...)
+
+		if _, ok := test.expect.(bool); ok {
+			assert.Error(t, err, msg)
+			continue
+		}
+		require.NoError(t, err, msg)
+
+		assert.EqualValues(t, test.expect, got, msg)
+	}
+}
+
+func TestGetDataSecurity(t *testing.T) {
+	t.Parallel()
+
+	ns := New(newDeps(configurator.New()))
+
+	var err error
+	ns.deps.Security, err = security.DecodeConfig(map[string]interface{}{
+		"readPaths": []interface{}{"data"},
+		"http": map[string]interface{}{
+			"hosts":   []interface{}{"*.example.com"},
+			"methods": []interface{}{"GET"},
+		},
+	})
+	require.NoError(t, err)
+
+	var srv *httptest.Server
+	srv, ns.client = getTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"gomeetup":"Sydney"}`))
+	})
+	defer func() { srv.Close() }()
+
+	for _, name := range []string{"data/episodes.json", "config.json"} {
+		f, err := ns.deps.Fs.Source.Create(filepath.Join(ns.deps.Cfg.GetString("workingDir"), name))
+		require.NoError(t, err)
+		f.WriteString(`{"gomeetup":"Sydney"}`)
+		f.Close()
+	}
+
+	for i, test := range []struct {
+		args   []interface{}
+		expect bool
+	}{
+		{[]interface{}{"https://api.example.com/episodes.json"}, true},
+		{[]interface{}{"data/episodes.json"}, true},
+		{[]interface{}{"https://example.org/episodes.json"}, false},
+		{[]interface{}{"https://api.example.com/episodes.json", map[string]interface{}{"method": "post"}}, false},
+		{[]interface{}{"config.json"}, false},
+		{[]interface{}{"data/../config.json"}, false},
+	} {
+		msg := fmt.Sprintf("Test %d", i)
+
+		got, err := ns.GetData(test.args...)
+
+		if !test.expect {
+			require.Error(t, err, msg)
+			assert.True(t, security.IsAccessDenied(err), msg)
+			continue
+		}
+		require.NoError(t, err, msg)
+
+		assert.Equal(t, map[string]interface{}{"gomeetup": "Sydney"}, got, msg)
+	}
+}
+
+func TestGetDataSecurityRedirect(t *testing.T) {
+	t.Parallel()
+
+	ns := New(newDeps(configurator.New()))
+
+	var err error
+	ns.deps.Security, err = security.DecodeConfig(map[string]interface{}{
+		"http": map[string]interface{}{
+			"hosts": []interface{}{"*.example.com"},
+		},
+	})
+	require.NoError(t, err)
+
+	srv, cl := getTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://example.org/episodes.json", http.StatusFound)
+			return
+		}
+		w.Write([]byte(`{"gomeetup":"Sydney"}`))
+	})
+	defer func() { srv.Close() }()
+
+	// Keep the redirect check of the namespace's client.
+	ns.client.Transport = cl.Transport
+
+	got, err := ns.GetData("http://api.example.com/episodes.json")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"gomeetup": "Sydney"}, got)
+
+	_, err = ns.GetData("http://api.example.com/redirect")
+	require.Error(t, err)
+	assert.True(t, security.IsAccessDenied(err))
+	assert.Contains(t, err.Error(), "example.org")
+}
+
 func TestParseCSV(t *testing.T) {
 	t.Parallel()
 
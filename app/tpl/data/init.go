@@ -21,6 +21,11 @@ func init() {
 			[][2]string{},
 		)
 
+		ns.AddMethodMapping(ctx.GetData,
+			[]string{"getData"},
+			[][2]string{},
+		)
+
 		ns.AddMethodMapping(ctx.GetJSON,
 			[]string{"getJSON"},
 			[][2]string{},
@@ -0,0 +1,196 @@
+package data
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/geego/gean/app/config"
+	"github.com/govenue/assist"
+)
+
+// defaultRemoteTimeout is the remote request timeout if not configured with
+// remoteDataTimeout.
+const defaultRemoteTimeout = 30 * time.Second
+
+// remoteOptions configures a request for a local or remote file, see GetData.
+type remoteOptions struct {
+	method  string
+	headers http.Header
+	body    string
+	timeout time.Duration
+
+	// The max age of the cached file. A negative max age means no expiry,
+	// and 0 no caching.
+	maxAge time.Duration
+
+	// The data format, see GetData.
+	format string
+}
+
+// newRemoteOptions creates the request options from the site config, i.e.
+// remoteDataTimeout and remoteDataMaxAge, and the given options map, e.g.:
+//
+//	{{ $opts := dict "headers" (dict "Authorization" "Bearer xyz") "maxAge" "1h" }}
+//	{{ $data := getJSON "https://api.example.com/episodes" $opts }}
+func newRemoteOptions(cfg config.Provider, in map[string]interface{}) (remoteOptions, error) {
+	opts := remoteOptions{
+		method:  "GET",
+		headers: make(http.Header),
+		timeout: defaultRemoteTimeout,
+		maxAge:  -1,
+	}
+
+	var err error
+
+	if cfg.IsSet("remoteDataTimeout") {
+		if opts.timeout, err = toDuration(cfg.Get("remoteDataTimeout")); err != nil {
+			return opts, fmt.Errorf("invalid remoteDataTimeout config: %s", err)
+		}
+	}
+	if cfg.IsSet("remoteDataMaxAge") {
+		if opts.maxAge, err = toDuration(cfg.Get("remoteDataMaxAge")); err != nil {
+			return opts, fmt.Errorf("invalid remoteDataMaxAge config: %s", err)
+		}
+	}
+
+	for k, v := range in {
+		switch strings.ToLower(k) {
+		case "method":
+			opts.method = strings.ToUpper(assist.ToString(v))
+		case "headers":
+			headers, err := assist.ToStringMapE(v)
+			if err != nil {
+				return opts, fmt.Errorf("invalid headers option: %s", err)
+			}
+			for name, hv := range headers {
+				if s, ok := hv.(string); ok {
+					opts.headers.Add(name, s)
+					continue
+				}
+				values, err := assist.ToStringSliceE(hv)
+				if err != nil {
+					return opts, fmt.Errorf("invalid value for header %q: %s", name, err)
+				}
+				for _, s := range values {
+					opts.headers.Add(name, s)
+				}
+			}
+		case "body":
+			opts.body = assist.ToString(v)
+		case "timeout":
+			if opts.timeout, err = toDuration(v); err != nil {
+				return opts, fmt.Errorf("invalid timeout option: %s", err)
+			}
+		case "maxage":
+			if opts.maxAge, err = toDuration(v); err != nil {
+				return opts, fmt.Errorf("invalid maxAge option: %s", err)
+			}
+		case "format":
+			opts.format = strings.ToLower(assist.ToString(v))
+			if _, found := dataFormatAccept[opts.format]; !found {
+				return opts, fmt.Errorf("unsupported data format %q", opts.format)
+			}
+		default:
+			return opts, fmt.Errorf("unknown option %q", k)
+		}
+	}
+
+	return opts, nil
+}
+
+// toDuration converts durations, e.g. "1h30m", and numbers of seconds, e.g. 90
+// or "-1", to a time.Duration.
+func toDuration(v interface{}) (time.Duration, error) {
+	if s, ok := v.(string); ok {
+		if d, err := time.ParseDuration(s); err == nil {
+			return d, nil
+		}
+	}
+
+	secs, err := assist.ToFloat64E(v)
+	if err != nil {
+		return 0, fmt.Errorf("%v is neither a duration nor a number of seconds", v)
+	}
+
+	return time.Duration(secs * float64(time.Second)), nil
+}
+
+// cacheID returns the cache ID for the given URL requested with these options.
+func (o remoteOptions) cacheID(url string) string {
+	if o.method == "GET" && o.body == "" && len(o.headers) == 0 {
+		return url
+	}
+
+	var b bytes.Buffer
+	b.WriteString(url)
+	b.WriteString("#" + o.method)
+	b.WriteString("#" + o.body)
+
+	var names []string
+	for name := range o.headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		b.WriteString("#" + name + ":" + strings.Join(o.headers[name], ","))
+	}
+
+	return b.String()
+}
+
+// newRequest creates the request for the given URL. The given Accept header
+// values are replaced by any Accept header in the options.
+func (o remoteOptions) newRequest(url string, accept ...string) (*http.Request, error) {
+	var body io.Reader
+	if o.body != "" {
+		body = strings.NewReader(o.body)
+	}
+
+	req, err := http.NewRequest(o.method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range accept {
+		req.Header.Add("Accept", a)
+	}
+
+	for name, values := range o.headers {
+		req.Header[name] = values
+	}
+
+	return req, nil
+}
+
+// dataFormatAccept maps the data formats to their media types.
+var dataFormatAccept = map[string][]string{
+	"json": {"application/json"},
+	"yaml": {"application/yaml", "text/yaml"},
+	"yml":  {"application/yaml", "text/yaml"},
+	"toml": {"application/toml"},
+	"xml":  {"application/xml", "text/xml"},
+	"csv":  {"text/csv"},
+	"tsv":  {"text/tab-separated-values"},
+}
+
+// dataFormatFromURL returns the data format given by the URL's extension, if
+// any, e.g. "yaml" for https://example.com/episodes.yaml?page=2.
+func dataFormatFromURL(url string) string {
+	if i := strings.IndexAny(url, "?#"); i != -1 {
+		url = url[:i]
+	}
+
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(url), "."))
+	if _, found := dataFormatAccept[ext]; found {
+		return ext
+	}
+
+	return ""
+}
@@ -0,0 +1,112 @@
+package data
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/govenue/assert"
+	"github.com/govenue/configurator"
+	"github.com/govenue/require"
+)
+
+func TestNewRemoteOptions(t *testing.T) {
+	t.Parallel()
+
+	cfg := configurator.New()
+
+	opts, err := newRemoteOptions(cfg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "GET", opts.method)
+	assert.Equal(t, defaultRemoteTimeout, opts.timeout)
+	assert.Equal(t, time.Duration(-1), opts.maxAge)
+
+	cfg.Set("remoteDataTimeout", "5s")
+	cfg.Set("remoteDataMaxAge", 60)
+
+	opts, err = newRemoteOptions(cfg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, opts.timeout)
+	assert.Equal(t, time.Minute, opts.maxAge)
+
+	opts, err = newRemoteOptions(cfg, map[string]interface{}{
+		"Method":  "post",
+		"headers": map[string]interface{}{"x-token": "a b", "Accept": []string{"text/csv", "text/plain"}},
+		"body":    "q=1",
+		"timeout": 1.5,
+		"maxAge":  "-1",
+		"format":  "YAML",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "POST", opts.method)
+	assert.Equal(t, http.Header{"X-Token": {"a b"}, "Accept": {"text/csv", "text/plain"}}, opts.headers)
+	assert.Equal(t, "q=1", opts.body)
+	assert.Equal(t, 1500*time.Millisecond, opts.timeout)
+	assert.Equal(t, -time.Second, opts.maxAge)
+	assert.Equal(t, "yaml", opts.format)
+
+	for i, in := range []map[string]interface{}{
+		{"nosuch": true},
+		{"timeout": "soon"},
+		{"maxAge": "never"},
+		{"headers": "x-token"},
+		{"headers": map[string]interface{}{"x-token": map[string]interface{}{"a": "b"}}},
+		{"format": "ini"},
+	} {
+		_, err := newRemoteOptions(configurator.New(), in)
+		require.Error(t, err, fmt.Sprintf("[%d] %v", i, in))
+	}
+
+	cfg.Set("remoteDataMaxAge", "never")
+	_, err = newRemoteOptions(cfg, nil)
+	require.Error(t, err)
+}
+
+func TestRemoteOptionsRequest(t *testing.T) {
+	t.Parallel()
+
+	url := "http://Foo.Bar/foo_Bar-Foo"
+
+	opts, err := newRemoteOptions(configurator.New(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, url, opts.cacheID(url))
+
+	req, err := opts.newRequest(url, "application/json")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"application/json"}, req.Header["Accept"])
+
+	opts, err = newRemoteOptions(configurator.New(), map[string]interface{}{
+		"headers": map[string]interface{}{"Accept": "text/yaml", "X-Token": "a"},
+	})
+	require.NoError(t, err)
+	id := opts.cacheID(url)
+	assert.NotEqual(t, url, id)
+	assert.Equal(t, id, opts.cacheID(url))
+
+	req, err = opts.newRequest(url, "application/json")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"text/yaml"}, req.Header["Accept"])
+	assert.Equal(t, "a", req.Header.Get("X-Token"))
+
+	opts.headers.Set("X-Token", "b")
+	assert.NotEqual(t, id, opts.cacheID(url))
+}
+
+func TestDataFormatFromURL(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range []struct {
+		url    string
+		expect string
+	}{
+		{"http://example.org/episodes.json", "json"},
+		{"http://example.org/episodes.YML?page=2", "yml"},
+		{"data/episodes.xml#top", "xml"},
+		{"http://example.org/episodes", ""},
+		{"http://example.org/episodes.html", ""},
+		{"http://example.org/?f=a.csv", ""},
+	} {
+		assert.Equal(t, test.expect, dataFormatFromURL(test.url), fmt.Sprintf("[%d] %s", i, test.url))
+	}
+}
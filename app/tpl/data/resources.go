@@ -1,9 +1,11 @@
 package data
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"path/filepath"
 	"sync"
 	"time"
@@ -13,12 +15,13 @@ import (
 
 	"github.com/geego/gean/app/config"
 	"github.com/geego/gean/app/helpers"
+	"github.com/geego/gean/app/security"
 )
 
 var (
 	remoteURLLock = &remoteLock{m: make(map[string]*sync.Mutex)}
-	resSleep      = time.Second * 2 // if JSON decoding failed sleep for n seconds before retrying
-	resRetries    = 1               // number of retries to load the JSON from URL or local file system
+	resSleep      = time.Second * 2 // if a download or decoding failed sleep for n seconds before the first retry
+	resRetries    = 1               // number of retries to load the data from URL or local file system
 )
 
 type remoteLock struct {
@@ -51,55 +54,129 @@ func (l *remoteLock) URLUnlock(url string) {
 }
 
 // getRemote loads the content of a remote file. This method is thread safe.
-func getRemote(req *http.Request, fs fsintra.Fs, cfg config.Provider, hc *http.Client) ([]byte, error) {
-	url := req.URL.String()
+// The content is cached until older than the options' max age, and then
+// revalidated with its ETag, if any. In offline mode, only the cache is used,
+// whatever its age.
+func getRemote(req *http.Request, opts remoteOptions, fs fsintra.Fs, cfg config.Provider, hc *http.Client) ([]byte, error) {
+	var (
+		url         = req.URL.String()
+		id          = opts.cacheID(url)
+		offline     = cfg.GetBool("offline")
+		ignoreCache = (cfg.GetBool("ignoreCache") || opts.maxAge == 0) && !offline
+	)
 
-	c, err := getCache(url, fs, cfg, cfg.GetBool("ignoreCache"))
+	e, err := getCacheEntry(id, fs, cfg, ignoreCache)
 	if err != nil {
 		return nil, err
 	}
-	if c != nil {
-		return c, nil
+	if e != nil && (offline || e.isFresh(opts.maxAge)) {
+		return e.content, nil
+	}
+	if offline {
+		return nil, fmt.Errorf("Failed to retrieve remote file %s: not found in the cache in offline mode", url)
 	}
 
 	// avoid race condition with locks, block other goroutines if the current url is processing
-	remoteURLLock.URLLock(url)
-	defer func() { remoteURLLock.URLUnlock(url) }()
+	remoteURLLock.URLLock(id)
+	defer func() { remoteURLLock.URLUnlock(id) }()
 
-	// avoid multiple locks due to calling getCache twice
-	c, err = getCache(url, fs, cfg, cfg.GetBool("ignoreCache"))
+	// avoid multiple downloads due to calling getCacheEntry twice
+	e, err = getCacheEntry(id, fs, cfg, ignoreCache)
 	if err != nil {
 		return nil, err
 	}
-	if c != nil {
-		return c, nil
+	if e != nil && e.isFresh(opts.maxAge) {
+		return e.content, nil
+	}
+
+	if e != nil && e.etag != "" {
+		req.Header.Set("If-None-Match", e.etag)
 	}
 
 	notepad.INFO.Printf("Downloading: %s ...", url)
-	res, err := hc.Do(req)
+	res, err := doRemoteRequest(req, opts, hc)
 	if err != nil {
+		if e != nil {
+			notepad.WARN.Printf("Failed to retrieve remote file %s, using the stale cache: %s", url, err)
+			return e.content, nil
+		}
 		return nil, err
 	}
 
+	if res.StatusCode == http.StatusNotModified && e != nil {
+		res.Body.Close()
+		notepad.INFO.Printf("... not modified since cached to: %s", getCacheFileID(cfg, id))
+		// Reset the cache entry's age.
+		return e.content, writeCacheEntry(id, e.content, e.etag, fs, cfg, ignoreCache)
+	}
+
 	if res.StatusCode < 200 || res.StatusCode > 299 {
+		res.Body.Close()
 		return nil, fmt.Errorf("Failed to retrieve remote file: %s", http.StatusText(res.StatusCode))
 	}
 
-	c, err = ioutil.ReadAll(res.Body)
+	c, err := ioutil.ReadAll(res.Body)
 	res.Body.Close()
 	if err != nil {
 		return nil, err
 	}
 
-	err = writeCache(url, c, fs, cfg, cfg.GetBool("ignoreCache"))
+	err = writeCacheEntry(id, c, res.Header.Get("ETag"), fs, cfg, ignoreCache)
 	if err != nil {
 		return nil, err
 	}
 
-	notepad.INFO.Printf("... and cached to: %s", getCacheFileID(cfg, url))
+	if !ignoreCache {
+		notepad.INFO.Printf("... and cached to: %s", getCacheFileID(cfg, id))
+	}
 	return c, nil
 }
 
+// doRemoteRequest does the request with the options' timeout. Network errors
+// and server errors are retried, see retrySleep. A server error on the last
+// try is returned as an error, as a network error is.
+func doRemoteRequest(req *http.Request, opts remoteOptions, hc *http.Client) (*http.Response, error) {
+	client := *hc
+	client.Timeout = opts.timeout
+
+	for i := 0; ; i++ {
+		res, err := client.Do(req)
+		if uerr, ok := err.(*url.Error); ok && security.IsAccessDenied(uerr.Err) {
+			// A redirect denied by the security policy, see New.
+			return nil, uerr.Err
+		}
+		if err == nil && res.StatusCode < 500 && res.StatusCode != http.StatusTooManyRequests {
+			return res, nil
+		}
+
+		if err == nil {
+			res.Body.Close()
+			err = errors.New(http.StatusText(res.StatusCode))
+		}
+
+		if i >= resRetries {
+			return nil, err
+		}
+
+		sleep := retrySleep(i)
+		notepad.ERROR.Printf("Failed to retrieve remote file %s: %s", req.URL, err)
+		notepad.ERROR.Printf("Retry #%d for %s and sleeping for %s", i+1, req.URL, sleep)
+		time.Sleep(sleep)
+
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// retrySleep returns the time to sleep before the given retry, starting at 0,
+// doubled for every retry.
+func retrySleep(i int) time.Duration {
+	return resSleep << uint(i)
+}
+
 // getLocal loads the content of a local file
 func getLocal(url string, fs fsintra.Fs, cfg config.Provider) ([]byte, error) {
 	filename := filepath.Join(cfg.GetString("workingDir"), url)
@@ -111,12 +188,19 @@ func getLocal(url string, fs fsintra.Fs, cfg config.Provider) ([]byte, error) {
 
 }
 
-// getResource loads the content of a local or remote file
-func (ns *Namespace) getResource(req *http.Request) ([]byte, error) {
+// getResource loads the content of a local or remote file, if allowed by the
+// security policy.
+func (ns *Namespace) getResource(req *http.Request, opts remoteOptions) ([]byte, error) {
 	switch req.URL.Scheme {
 	case "":
+		if err := ns.deps.Security.CheckReadPath(req.URL.String()); err != nil {
+			return nil, err
+		}
 		return getLocal(req.URL.String(), ns.deps.Fs.Source, ns.deps.Cfg)
 	default:
-		return getRemote(req, ns.deps.Fs.Source, ns.deps.Cfg, ns.client)
+		if err := ns.deps.Security.CheckHTTP(req.Method, req.URL); err != nil {
+			return nil, err
+		}
+		return getRemote(req, opts, ns.deps.Fs.Source, ns.deps.Cfg, ns.client)
 	}
 }
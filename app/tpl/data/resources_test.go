@@ -3,6 +3,7 @@ package data
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -95,8 +96,10 @@ func TestScpGetRemote(t *testing.T) {
 		defer func() { srv.Close() }()
 
 		cfg := configurator.New()
+		opts, err := newRemoteOptions(cfg, nil)
+		require.NoError(t, err, msg)
 
-		c, err := getRemote(req, fs, cfg, cl)
+		c, err := getRemote(req, opts, fs, cfg, cl)
 		require.NoError(t, err, msg)
 		assert.Equal(t, string(test.content), string(c))
 
@@ -127,6 +130,9 @@ func TestScpGetRemoteParallel(t *testing.T) {
 	req, err := http.NewRequest("GET", url, nil)
 	require.NoError(t, err)
 
+	opts, err := newRemoteOptions(ns.deps.Cfg, nil)
+	require.NoError(t, err)
+
 	for _, ignoreCache := range []bool{false, true} {
 		cfg := configurator.New()
 		cfg.Set("ignoreCache", ignoreCache)
@@ -138,7 +144,7 @@ func TestScpGetRemoteParallel(t *testing.T) {
 			go func(gor int) {
 				defer wg.Done()
 				for j := 0; j < 10; j++ {
-					c, err := getRemote(req, ns.deps.Fs.Source, ns.deps.Cfg, cl)
+					c, err := getRemote(req, opts, ns.deps.Fs.Source, ns.deps.Cfg, cl)
 					assert.NoError(t, err)
 					assert.Equal(t, string(content), string(c))
 
@@ -164,3 +170,196 @@ func newDeps(cfg config.Provider) *deps.Deps {
 		ContentSpec: cs,
 	}
 }
+
+func TestScpGetRemoteMaxAge(t *testing.T) {
+	t.Parallel()
+	fs := new(fsintra.MemMapFs)
+	cfg := configurator.New()
+
+	var (
+		mu                sync.Mutex
+		hits, notModified int
+		content           = "v1"
+	)
+
+	srv, cl := getTestServer(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		hits++
+		etag := `"` + content + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(content))
+	})
+	defer func() { srv.Close() }()
+
+	get := func(maxAge string) string {
+		opts, err := newRemoteOptions(cfg, map[string]interface{}{"maxAge": maxAge})
+		require.NoError(t, err)
+		req, err := opts.newRequest("http://Foo.Bar/max-age")
+		require.NoError(t, err)
+		c, err := getRemote(req, opts, fs, cfg, cl)
+		require.NoError(t, err)
+		return string(c)
+	}
+
+	counts := func() (int, int) {
+		mu.Lock()
+		defer mu.Unlock()
+		return hits, notModified
+	}
+
+	assert.Equal(t, "v1", get("1h"))
+	assert.Equal(t, "v1", get("1h"))
+	h, nm := counts()
+	assert.Equal(t, 1, h)
+	assert.Equal(t, 0, nm)
+
+	// Stale, but not modified.
+	assert.Equal(t, "v1", get("1ns"))
+	h, nm = counts()
+	assert.Equal(t, 2, h)
+	assert.Equal(t, 1, nm)
+
+	mu.Lock()
+	content = "v2"
+	mu.Unlock()
+
+	assert.Equal(t, "v1", get("1h"))
+	assert.Equal(t, "v2", get("1ns"))
+	assert.Equal(t, "v2", get("1h"))
+	h, nm = counts()
+	assert.Equal(t, 3, h)
+	assert.Equal(t, 1, nm)
+
+	// Not cached.
+	assert.Equal(t, "v2", get("0"))
+	assert.Equal(t, "v2", get("0"))
+	h, _ = counts()
+	assert.Equal(t, 5, h)
+}
+
+func TestScpGetRemoteOffline(t *testing.T) {
+	t.Parallel()
+	fs := new(fsintra.MemMapFs)
+	cfg := configurator.New()
+	cfg.Set("offline", true)
+	cfg.Set("ignoreCache", true)
+
+	var (
+		mu   sync.Mutex
+		hits int
+	)
+
+	srv, cl := getTestServer(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits++
+		mu.Unlock()
+		w.Write([]byte(`T€st Content 123`))
+	})
+	defer func() { srv.Close() }()
+
+	opts, err := newRemoteOptions(cfg, map[string]interface{}{"maxAge": "1ns"})
+	require.NoError(t, err)
+
+	cached := "http://Foo.Bar/cached"
+	require.NoError(t, writeCache(cached, []byte(`T€st Cached 123`), fs, cfg, false))
+
+	req, err := opts.newRequest(cached)
+	require.NoError(t, err)
+	c, err := getRemote(req, opts, fs, cfg, cl)
+	require.NoError(t, err)
+	assert.Equal(t, `T€st Cached 123`, string(c))
+
+	req, err = opts.newRequest("http://Foo.Bar/not-cached")
+	require.NoError(t, err)
+	_, err = getRemote(req, opts, fs, cfg, cl)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "offline")
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 0, hits)
+}
+
+func TestScpGetRemoteRetry(t *testing.T) {
+	t.Parallel()
+	fs := new(fsintra.MemMapFs)
+	cfg := configurator.New()
+
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+
+	srv, cl := getTestServer(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		bodies = append(bodies, string(b))
+		if len(bodies) == 1 {
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`T€st Content 123`))
+	})
+	defer func() { srv.Close() }()
+
+	opts, err := newRemoteOptions(cfg, map[string]interface{}{"method": "post", "body": "q=1"})
+	require.NoError(t, err)
+	req, err := opts.newRequest("http://Foo.Bar/retry")
+	require.NoError(t, err)
+
+	c, err := getRemote(req, opts, fs, cfg, cl)
+	require.NoError(t, err)
+	assert.Equal(t, `T€st Content 123`, string(c))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"q=1", "q=1"}, bodies)
+}
+
+func TestScpGetRemoteServerError(t *testing.T) {
+	t.Parallel()
+	fs := new(fsintra.MemMapFs)
+	cfg := configurator.New()
+
+	var (
+		mu   sync.Mutex
+		hits int
+	)
+
+	srv, cl := getTestServer(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits++
+		mu.Unlock()
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+	})
+	defer func() { srv.Close() }()
+
+	opts, err := newRemoteOptions(cfg, map[string]interface{}{"maxAge": "1ns"})
+	require.NoError(t, err)
+
+	stale := "http://Foo.Bar/stale"
+	require.NoError(t, writeCacheEntry(opts.cacheID(stale), []byte(`T€st Stale 123`), "", fs, cfg, false))
+
+	req, err := opts.newRequest(stale)
+	require.NoError(t, err)
+	c, err := getRemote(req, opts, fs, cfg, cl)
+	require.NoError(t, err)
+	assert.Equal(t, `T€st Stale 123`, string(c))
+
+	req, err = opts.newRequest("http://Foo.Bar/not-cached")
+	require.NoError(t, err)
+	_, err = getRemote(req, opts, fs, cfg, cl)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), http.StatusText(http.StatusTooManyRequests))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2*(resRetries+1), hits)
+}
@@ -1,10 +1,13 @@
 package encoding
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"html/template"
 
+	"github.com/geego/gean/app/helpers"
+	"github.com/geego/gean/app/parser"
 	"github.com/govenue/assist"
 )
 
@@ -46,3 +49,21 @@ func (ns *Namespace) Jsonify(v interface{}) (template.HTML, error) {
 
 	return template.HTML(b), nil
 }
+
+// ToYAML encodes a given object to YAML.
+func (ns *Namespace) ToYAML(v interface{}) (string, error) {
+	return marshal(v, "yaml")
+}
+
+// ToTOML encodes a given object to TOML. The object must be a map.
+func (ns *Namespace) ToTOML(v interface{}) (string, error) {
+	return marshal(v, "toml")
+}
+
+func marshal(v interface{}, format string) (string, error) {
+	var b bytes.Buffer
+	if err := parser.InterfaceToConfig(helpers.StringifyMapKeys(v), parser.FormatToLeadRune(format), &b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
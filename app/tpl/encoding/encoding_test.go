@@ -94,3 +94,61 @@ func TestJsonify(t *testing.T) {
 		assert.Equal(t, test.expect, result, errMsg)
 	}
 }
+
+func TestToYAML(t *testing.T) {
+	t.Parallel()
+
+	ns := New()
+
+	for i, test := range []struct {
+		v      interface{}
+		expect interface{}
+	}{
+		{map[string]interface{}{"a": []string{"b"}}, "a:\n- b\n"},
+		{map[interface{}]interface{}{"a": map[interface{}]interface{}{"b": true}}, "a:\n  b: true\n"},
+		{[]string{"a", "b"}, "- a\n- b\n"},
+		// errors
+		{nil, false},
+	} {
+		errMsg := fmt.Sprintf("[%d] %v", i, test.v)
+
+		result, err := ns.ToYAML(test.v)
+
+		if b, ok := test.expect.(bool); ok && !b {
+			require.Error(t, err, errMsg)
+			continue
+		}
+
+		require.NoError(t, err, errMsg)
+		assert.Equal(t, test.expect, result, errMsg)
+	}
+}
+
+func TestToTOML(t *testing.T) {
+	t.Parallel()
+
+	ns := New()
+
+	for i, test := range []struct {
+		v      interface{}
+		expect interface{}
+	}{
+		{map[string]interface{}{"a": []string{"b"}}, "a = [\"b\"]\n"},
+		{map[interface{}]interface{}{"b": 1}, "b = 1\n"},
+		// errors
+		{[]string{"a", "b"}, false},
+		{nil, false},
+	} {
+		errMsg := fmt.Sprintf("[%d] %v", i, test.v)
+
+		result, err := ns.ToTOML(test.v)
+
+		if b, ok := test.expect.(bool); ok && !b {
+			require.Error(t, err, errMsg)
+			continue
+		}
+
+		require.NoError(t, err, errMsg)
+		assert.Equal(t, test.expect, result, errMsg)
+	}
+}
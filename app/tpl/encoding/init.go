@@ -38,6 +38,20 @@ func init() {
 			},
 		)
 
+		ns.AddMethodMapping(ctx.ToYAML,
+			[]string{"toYAML"},
+			[][2]string{
+				{`{{ dict "a" 1 | toYAML | chomp }}`, `a: 1`},
+			},
+		)
+
+		ns.AddMethodMapping(ctx.ToTOML,
+			[]string{"toTOML"},
+			[][2]string{
+				{`{{ dict "a" 1 | toTOML | chomp }}`, `a = 1`},
+			},
+		)
+
 		return ns
 
 	}
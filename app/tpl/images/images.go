@@ -44,7 +44,7 @@ type Namespace struct {
 }
 
 // Config returns the image.Config for the specified path relative to the
-// working directory.
+// working directory, if allowed by the security policy.
 func (ns *Namespace) Config(path interface{}) (image.Config, error) {
 	filename, err := assist.ToStringE(path)
 	if err != nil {
@@ -55,6 +55,10 @@ func (ns *Namespace) Config(path interface{}) (image.Config, error) {
 		return image.Config{}, errors.New("config needs a filename")
 	}
 
+	if err := ns.deps.Security.CheckReadPath(filename); err != nil {
+		return image.Config{}, err
+	}
+
 	// Check cache for image config.
 	ns.cacheMu.RLock()
 	config, ok := ns.cache[filename]
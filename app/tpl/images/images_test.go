@@ -24,6 +24,7 @@ import (
 
 	"github.com/geego/gean/app/deps"
 	"github.com/geego/gean/app/geanfs"
+	"github.com/geego/gean/app/security"
 	"github.com/govenue/assert"
 	"github.com/govenue/assist"
 	"github.com/govenue/configurator"
@@ -78,6 +79,7 @@ var configTests = []struct {
 	{path: tstNoStringer{}, expect: false},
 	{path: "non-existent.png", expect: false},
 	{path: "", expect: false},
+	{path: "../a.png", expect: false},
 }
 
 func TestNSConfig(t *testing.T) {
@@ -111,6 +113,28 @@ func TestNSConfig(t *testing.T) {
 	}
 }
 
+func TestNSConfigSecurity(t *testing.T) {
+	t.Parallel()
+
+	v := configurator.New()
+	v.Set("workingDir", "/a/b")
+
+	policy, err := security.DecodeConfig(map[string]interface{}{"readPaths": []interface{}{"static"}})
+	require.NoError(t, err)
+
+	ns := New(&deps.Deps{Fs: geanfs.NewMem(v), Security: policy})
+
+	fsintra.WriteFile(ns.deps.Fs.Source, filepath.FromSlash("/a/b/static/a.png"), blankImage(10, 10), 0755)
+	fsintra.WriteFile(ns.deps.Fs.Source, filepath.FromSlash("/a/b/b.png"), blankImage(10, 10), 0755)
+
+	result, err := ns.Config(filepath.FromSlash("static/a.png"))
+	require.NoError(t, err)
+	assert.Equal(t, 10, result.Width)
+
+	_, err = ns.Config("b.png")
+	assert.True(t, security.IsAccessDenied(err))
+}
+
 func blankImage(width, height int) []byte {
 	var buf bytes.Buffer
 	img := image.NewRGBA(image.Rect(0, 0, width, height))
@@ -37,12 +37,17 @@ type Namespace struct {
 
 // Getenv retrieves the value of the environment variable named by the key.
 // It returns the value, which will be empty if the variable is not present.
+// Only the variables allowed by the security.getenv setting can be read.
 func (ns *Namespace) Getenv(key interface{}) (string, error) {
 	skey, err := assist.ToStringE(key)
 	if err != nil {
 		return "", nil
 	}
 
+	if err := ns.deps.Security.CheckGetenv(skey); err != nil {
+		return "", err
+	}
+
 	return _os.Getenv(skey), nil
 }
 
@@ -73,22 +78,34 @@ func readFile(fs *fsintra.BasePathFs, filename string) (string, error) {
 // ReadFile reads the file named by filename relative to the configured WorkingDir.
 // It returns the contents as a string.
 // There is an upper size limit set at 1 megabytes.
+// Only the files below the security.readPaths setting can be read.
 func (ns *Namespace) ReadFile(i interface{}) (string, error) {
 	s, err := assist.ToStringE(i)
 	if err != nil {
 		return "", err
 	}
 
+	if s != "" {
+		if err := ns.deps.Security.CheckReadPath(s); err != nil {
+			return "", err
+		}
+	}
+
 	return readFile(ns.deps.Fs.WorkingDir, s)
 }
 
 // ReadDir lists the directory contents relative to the configured WorkingDir.
+// Only the directories below the security.readPaths setting can be read.
 func (ns *Namespace) ReadDir(i interface{}) ([]_os.FileInfo, error) {
 	path, err := assist.ToStringE(i)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := ns.deps.Security.CheckReadPath(path); err != nil {
+		return nil, err
+	}
+
 	list, err := fsintra.ReadDir(ns.deps.Fs.WorkingDir, path)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to read Directory %s with error message %s", path, err)
@@ -98,6 +115,7 @@ func (ns *Namespace) ReadDir(i interface{}) ([]_os.FileInfo, error) {
 }
 
 // FileExists checks whether a file exists under the given path.
+// Only the paths below the security.readPaths setting can be checked.
 func (ns *Namespace) FileExists(i interface{}) (bool, error) {
 	path, err := assist.ToStringE(i)
 	if err != nil {
@@ -108,6 +126,10 @@ func (ns *Namespace) FileExists(i interface{}) (bool, error) {
 		return false, errors.New("fileExists needs a path to a file")
 	}
 
+	if err := ns.deps.Security.CheckReadPath(path); err != nil {
+		return false, err
+	}
+
 	status, err := fsintra.Exists(ns.deps.Fs.WorkingDir, path)
 	if err != nil {
 		return false, err
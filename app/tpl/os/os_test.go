@@ -15,11 +15,13 @@ package os
 
 import (
 	"fmt"
+	_os "os"
 	"path/filepath"
 	"testing"
 
 	"github.com/geego/gean/app/deps"
 	"github.com/geego/gean/app/geanfs"
+	"github.com/geego/gean/app/security"
 	"github.com/govenue/assert"
 	"github.com/govenue/configurator"
 	"github.com/govenue/fsintra"
@@ -99,3 +101,75 @@ func TestFileExists(t *testing.T) {
 		assert.Equal(t, test.expect, result, errMsg)
 	}
 }
+
+func TestGetenv(t *testing.T) {
+	t.Parallel()
+
+	_os.Setenv("GEAN_TEST_GETENV", "gean")
+	_os.Setenv("TEST_GETENV_SECRET", "secret")
+
+	// Nothing is restricted without a security section.
+	ns := New(&deps.Deps{})
+
+	result, err := ns.Getenv("TEST_GETENV_SECRET")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", result)
+
+	ns.deps.Security, err = security.DecodeConfig(map[string]interface{}{})
+	require.NoError(t, err)
+
+	result, err = ns.Getenv("GEAN_TEST_GETENV")
+	require.NoError(t, err)
+	assert.Equal(t, "gean", result)
+
+	_, err = ns.Getenv("TEST_GETENV_SECRET")
+	require.Error(t, err)
+	assert.True(t, security.IsAccessDenied(err))
+
+	ns.deps.Security, err = security.DecodeConfig(map[string]interface{}{"getenv": []interface{}{"TEST_GETENV_*"}})
+	require.NoError(t, err)
+
+	result, err = ns.Getenv("TEST_GETENV_SECRET")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", result)
+
+	_, err = ns.Getenv("GEAN_TEST_GETENV")
+	require.Error(t, err)
+}
+
+func TestReadPathsSecurity(t *testing.T) {
+	t.Parallel()
+
+	workingDir := "/home/hugo"
+
+	v := configurator.New()
+	v.Set("workingDir", workingDir)
+
+	policy, err := security.DecodeConfig(map[string]interface{}{"readPaths": []interface{}{"data"}})
+	require.NoError(t, err)
+
+	ns := New(&deps.Deps{Fs: geanfs.NewMem(v), Security: policy})
+
+	fsintra.WriteFile(ns.deps.Fs.Source, filepath.Join(workingDir, "data", "d1.txt"), []byte("d1-content"), 0755)
+	fsintra.WriteFile(ns.deps.Fs.Source, filepath.Join(workingDir, "config.toml"), []byte("title = \"Gean\""), 0755)
+
+	result, err := ns.ReadFile(filepath.FromSlash("data/d1.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "d1-content", result)
+
+	list, err := ns.ReadDir("data")
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	_, err = ns.ReadFile("config.toml")
+	assert.True(t, security.IsAccessDenied(err))
+
+	_, err = ns.ReadFile(filepath.FromSlash("data/../config.toml"))
+	assert.True(t, security.IsAccessDenied(err))
+
+	_, err = ns.ReadDir("/")
+	assert.True(t, security.IsAccessDenied(err))
+
+	_, err = ns.FileExists("config.toml")
+	assert.True(t, security.IsAccessDenied(err))
+}
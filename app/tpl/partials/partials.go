@@ -19,6 +19,7 @@ import (
 	"strings"
 	"sync"
 	texttemplate "text/template"
+	"time"
 
 	bp "github.com/geego/gean/app/bufferpool"
 	"github.com/geego/gean/app/deps"
@@ -69,6 +70,10 @@ func (ns *Namespace) Include(name string, contextList ...interface{}) (interface
 			templ = ns.deps.Tmpl.Lookup(n + ".html")
 		}
 		if templ != nil {
+			if ns.deps.PartialObserver != nil {
+				defer ns.deps.PartialObserver.ObservePartial(n, context, time.Now())
+			}
+
 			b := bp.GetBuffer()
 			defer bp.PutBuffer(b)
 
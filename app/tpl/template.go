@@ -101,6 +101,60 @@ func (t *TemplateAdapter) Tree() string {
 	return s
 }
 
+// PartialObserver gets notified about every partial execution.
+type PartialObserver interface {
+	// ObservePartial is called with the partial name and its context when
+	// the partial execution started at start is done.
+	// Used with defer and time.Now().
+	ObservePartial(name string, context interface{}, start time.Time)
+}
+
+// TemplateAnalyzer is implemented by template handlers that can do a static
+// analysis of the parsed templates.
+type TemplateAnalyzer interface {
+	AnalyzeTemplates() *TemplateAnalysis
+}
+
+// TemplateAnalysis is the result of a static analysis of the templates.
+type TemplateAnalysis struct {
+	// The names of the templates loaded from files, sorted.
+	Templates []string
+
+	// The templates that failed to parse, e.g. because of a call to an
+	// undefined function.
+	Errors []TemplateError
+
+	// Calls to partial and partialCached with a constant name.
+	Partials []TemplateRef
+
+	// Calls to templates with the template keyword.
+	TemplateCalls []TemplateRef
+
+	// Page params keys read with .Params.key, index .Params "key" and
+	// similar. The keys are lower case.
+	Params []TemplateRef
+
+	// Params keys read with .Param "key" and .Page.Param "key", which fall
+	// back to the site params. The keys are lower case.
+	ParamLookups []TemplateRef
+}
+
+// TemplateError is a template that failed to parse.
+type TemplateError struct {
+	Name string
+	Err  error
+}
+
+// TemplateRef is a reference to a name from a template.
+type TemplateRef struct {
+	// The template file and 1-based line the reference is in.
+	Template string
+	Line     int
+
+	// The name referenced, e.g. the partial name or the params key.
+	Name string
+}
+
 // TemplateFuncsGetter allows to get a map of functions.
 type TemplateFuncsGetter interface {
 	GetFuncs() map[string]interface{}
@@ -0,0 +1,252 @@
+// Copyright 2017-present The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tplimpl
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"text/template/parse"
+
+	"github.com/geego/gean/app/tpl"
+)
+
+var _ tpl.TemplateAnalyzer = (*templateHandler)(nil)
+
+// pageParamsPaths are the paths to the page params, see paramsPaths.
+var pageParamsPaths = [][]string{
+	{"Params"},
+	{"Page", "Params"},
+}
+
+// templateAnalysis collects references while walking the template trees.
+type templateAnalysis struct {
+	*tpl.TemplateAnalysis
+
+	// The same tree may be added more than once, e.g. the shortcode
+	// templates and the base templates cloned into every overlay.
+	seen map[string]bool
+
+	tree *parse.Tree
+}
+
+// AnalyzeTemplates walks the parsed templates and collects the partials,
+// templates and page params they reference.
+func (t *templateHandler) AnalyzeTemplates() *tpl.TemplateAnalysis {
+	a := &templateAnalysis{TemplateAnalysis: &tpl.TemplateAnalysis{}, seen: make(map[string]bool)}
+
+	names := make(map[string]bool)
+
+	addTree := func(name string, tree *parse.Tree) {
+		if tree == nil || tree.Root == nil {
+			return
+		}
+		if name == tree.ParseName {
+			names[name] = true
+		}
+		a.tree = tree
+		c := newTemplateContext(nil)
+		c.analyze(a, tree.Root)
+	}
+
+	for _, templ := range t.html.t.Templates() {
+		addTree(templ.Name(), templ.Tree)
+	}
+	for _, overlay := range t.html.overlays {
+		for _, templ := range overlay.Templates() {
+			addTree(templ.Name(), templ.Tree)
+		}
+	}
+	for _, templ := range t.text.t.Templates() {
+		addTree(templ.Name(), templ.Tree)
+	}
+	for _, overlay := range t.text.overlays {
+		for _, templ := range overlay.Templates() {
+			addTree(templ.Name(), templ.Tree)
+		}
+	}
+
+	for name := range names {
+		a.Templates = append(a.Templates, name)
+	}
+	sort.Strings(a.Templates)
+
+	for _, e := range t.errors {
+		a.Errors = append(a.Errors, tpl.TemplateError{Name: e.name, Err: e.err})
+	}
+
+	return a.TemplateAnalysis
+}
+
+func (a *templateAnalysis) add(kind string, n parse.Node, name string) {
+	location, _ := a.tree.ErrorContext(n)
+
+	// location is on the form name:line:col.
+	var (
+		templ = location
+		line  int
+	)
+	if parts := strings.Split(location, ":"); len(parts) >= 3 {
+		templ = strings.Join(parts[:len(parts)-2], ":")
+		line, _ = strconv.Atoi(parts[len(parts)-2])
+	}
+
+	key := kind + "|" + location + "|" + name
+	if a.seen[key] {
+		return
+	}
+	a.seen[key] = true
+
+	ref := tpl.TemplateRef{Template: templ, Line: line, Name: name}
+
+	switch kind {
+	case "partial":
+		a.Partials = append(a.Partials, ref)
+	case "template":
+		a.TemplateCalls = append(a.TemplateCalls, ref)
+	case "param":
+		a.Params = append(a.Params, ref)
+	case "paramLookup":
+		a.ParamLookups = append(a.ParamLookups, ref)
+	}
+}
+
+// analyze walks the node the same way as paramsKeysToLower, keeping track
+// of the variable declarations, and collects the references found.
+func (c *templateContext) analyze(a *templateAnalysis, n parse.Node) {
+	switch x := n.(type) {
+	case *parse.ListNode:
+		if x != nil {
+			c.analyzeNodes(a, x.Nodes...)
+		}
+	case *parse.ActionNode:
+		c.analyzeNodes(a, x.Pipe)
+	case *parse.IfNode:
+		c.analyzeNodes(a, x.Pipe, x.List, x.ElseList)
+	case *parse.WithNode:
+		c.analyzeNodes(a, x.Pipe, x.List, x.ElseList)
+	case *parse.RangeNode:
+		c.analyzeNodes(a, x.Pipe, x.List, x.ElseList)
+	case *parse.TemplateNode:
+		a.add("template", x, x.Name)
+		if x.Pipe != nil {
+			c.analyze(a, x.Pipe)
+		}
+	case *parse.PipeNode:
+		if x == nil {
+			return
+		}
+		for i, elem := range x.Decl {
+			if len(x.Cmds) > i {
+				c.decl[elem.Ident[0]] = x.Cmds[i].String()
+			}
+		}
+
+		for _, cmd := range x.Cmds {
+			c.analyze(a, cmd)
+		}
+	case *parse.CommandNode:
+		c.analyzeCommand(a, x)
+		for _, elem := range x.Args {
+			switch an := elem.(type) {
+			case *parse.FieldNode:
+				c.analyzeIdents(a, an, an.Ident)
+			case *parse.VariableNode:
+				c.analyzeIdents(a, an, an.Ident)
+			case *parse.ChainNode:
+				if f, ok := an.Node.(*parse.FieldNode); ok {
+					c.analyzeIdents(a, an, append(f.Ident, an.Field...))
+				}
+			case *parse.PipeNode:
+				c.analyze(a, an)
+			}
+		}
+	}
+}
+
+func (c *templateContext) analyzeNodes(a *templateAnalysis, nodes ...parse.Node) {
+	for _, node := range nodes {
+		c.analyze(a, node)
+	}
+}
+
+// analyzeCommand handles function and method calls with a constant name
+// argument, e.g. partial "header.html" and .Param "color".
+func (c *templateContext) analyzeCommand(a *templateAnalysis, x *parse.CommandNode) {
+	if len(x.Args) < 2 {
+		return
+	}
+
+	switch fn := x.Args[0].(type) {
+	case *parse.IdentifierNode:
+		switch fn.Ident {
+		case "partial", "partialCached":
+			if s, ok := x.Args[1].(*parse.StringNode); ok {
+				a.add("partial", x, strings.TrimPrefix(s.Text, "partials/"))
+			}
+		case "index", "isset":
+			if len(x.Args) < 3 {
+				return
+			}
+			var idents []string
+			switch arg := x.Args[1].(type) {
+			case *parse.FieldNode:
+				idents = arg.Ident
+			case *parse.VariableNode:
+				idents = arg.Ident
+			default:
+				return
+			}
+			if s, ok := x.Args[2].(*parse.StringNode); ok && c.isPageParams(idents) {
+				a.add("param", x, strings.ToLower(s.Text))
+			}
+		}
+	case *parse.FieldNode:
+		// .Param "key" and .Page.Param "key"
+		last := len(fn.Ident) - 1
+		if fn.Ident[last] != "Param" || (last == 1 && fn.Ident[0] != "Page") || last > 1 {
+			return
+		}
+		if s, ok := x.Args[1].(*parse.StringNode); ok {
+			a.add("paramLookup", x, strings.ToLower(s.Text))
+		}
+	}
+}
+
+// analyzeIdents collects the page params key in idents, if any.
+func (c *templateContext) analyzeIdents(a *templateAnalysis, n parse.Node, idents []string) {
+	if len(idents) == 0 {
+		return
+	}
+
+	resolved := c.decl.resolveIdents(idents)
+
+	for _, path := range pageParamsPaths {
+		if len(resolved) > len(path) && sliceStartsWith(resolved, path...) {
+			a.add("param", n, strings.ToLower(resolved[len(path)]))
+			return
+		}
+	}
+}
+
+// isPageParams returns whether idents resolves to the page params.
+func (c *templateContext) isPageParams(idents []string) bool {
+	resolved := c.decl.resolveIdents(idents)
+	for _, path := range pageParamsPaths {
+		if len(resolved) == len(path) && sliceStartsWith(resolved, path...) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+// Copyright 2017-present The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tplimpl
+
+import (
+	"testing"
+
+	"github.com/geego/gean/app/deps"
+	"github.com/geego/gean/app/geanfs"
+	"github.com/geego/gean/app/tpl"
+	"github.com/govenue/configurator"
+	"github.com/govenue/require"
+)
+
+func TestAnalyzeTemplates(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	v := configurator.New()
+	fs := geanfs.NewMem(v)
+
+	depsCfg := newDepsConfig(v)
+	depsCfg.Fs = fs
+	d, err := deps.New(depsCfg)
+	assert.NoError(err)
+
+	provider := DefaultTemplateProvider
+	provider.Update(d)
+
+	h := d.Tmpl.(handler)
+
+	assert.NoError(h.addTemplate("_default/single.html", `{{ partial "header.html" . }}
+{{ .Params.Color }}
+{{ $p := .Page.Params }}{{ $p.size }}
+{{ .Param "Author" }}
+{{ index .Params "weight" }}
+{{ template "_default/summary.html" . }}`))
+	assert.NoError(h.addTemplate("_default/summary.html", `{{ partialCached "partials/footer.html" . }}{{ .Title }}`))
+
+	a := d.Tmpl.(tpl.TemplateAnalyzer).AnalyzeTemplates()
+
+	assert.Equal([]string{"_default/single.html", "_default/summary.html"}, a.Templates)
+	assert.Empty(a.Errors)
+
+	assert.Equal([]tpl.TemplateRef{
+		{Template: "_default/single.html", Line: 1, Name: "header.html"},
+		{Template: "_default/summary.html", Line: 1, Name: "footer.html"},
+	}, a.Partials)
+
+	assert.Equal([]tpl.TemplateRef{
+		{Template: "_default/single.html", Line: 6, Name: "_default/summary.html"},
+	}, a.TemplateCalls)
+
+	var params []string
+	for _, ref := range a.Params {
+		params = append(params, ref.Name)
+	}
+	assert.Equal([]string{"color", "size", "weight"}, params)
+
+	assert.Equal([]tpl.TemplateRef{
+		{Template: "_default/single.html", Line: 4, Name: "author"},
+	}, a.ParamLookups)
+}
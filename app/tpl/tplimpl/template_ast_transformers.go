@@ -188,10 +188,28 @@ func (d decl) indexOfReplacementStart(idents []string) int {
 		}
 	}
 
+	resolvedIdents := d.resolveIdents(idents)
+	if resolvedIdents == nil {
+		return -1
+	}
+
+	for _, paramPath := range paramsPaths {
+		if index := indexOfFirstRealIdentAfterWords(resolvedIdents, idents, paramPath...); index != -1 {
+			return index
+		}
+	}
+
+	return -1
+
+}
+
+// resolveIdents resolves the variables in idents using the declarations,
+// so $blue => [Params Colors Blue] etc.
+// It returns nil if a variable can not be resolved.
+func (d decl) resolveIdents(idents []string) []string {
 	var (
-		resolvedIdents []string
-		replacements   []string
-		replaced       []string
+		replacements []string
+		replaced     []string
 	)
 
 	// An Ident can start out as one of
@@ -206,7 +224,7 @@ func (d decl) indexOfReplacementStart(idents []string) int {
 
 		if i > 20 {
 			// bail out
-			return -1
+			return nil
 		}
 
 		potentialVar := replacements[i]
@@ -225,7 +243,7 @@ func (d decl) indexOfReplacementStart(idents []string) int {
 
 		if !ok {
 			// Temporary range vars. We do not care about those.
-			return -1
+			return nil
 		}
 
 		replacement = strings.TrimPrefix(replacement, ".")
@@ -242,16 +260,7 @@ func (d decl) indexOfReplacementStart(idents []string) int {
 		}
 	}
 
-	resolvedIdents = append(replaced, idents[1:]...)
-
-	for _, paramPath := range paramsPaths {
-		if index := indexOfFirstRealIdentAfterWords(resolvedIdents, idents, paramPath...); index != -1 {
-			return index
-		}
-	}
-
-	return -1
-
+	return append(replaced, idents[1:]...)
 }
 
 func indexOfFirstRealIdentAfterWords(resolvedIdents, idents []string, words ...string) int {
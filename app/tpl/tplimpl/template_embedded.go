@@ -268,6 +268,32 @@ func (t *templateHandler) embedTemplates() {
 <meta itemprop="keywords" content="{{ if .IsPage}}{{ range $index, $tag := .Params.tags }}{{ $tag }},{{ end }}{{ else }}{{ range $plural, $terms := .Site.Taxonomies }}{{ range $term, $val := $terms }}{{ printf "%s," $term }}{{ end }}{{ end }}{{ end }}" />
 {{ end }}`)
 
+	t.addInternalTemplate("", "breadcrumbs.html", `{{- $crumbs := .Breadcrumbs -}}
+{{- $last := sub (len $crumbs) 1 -}}
+<nav aria-label="breadcrumb">
+<ol class="breadcrumb">
+{{- range $i, $p := $crumbs }}
+  <li class="breadcrumb-item{{ if eq $i $last }} active{{ end }}"{{ if eq $i $last }} aria-current="page"{{ end }}><a href="{{ $p.RelPermalink }}">{{ $p.Title }}</a></li>
+{{- end }}
+</ol>
+</nav>
+<script type="application/ld+json">
+{
+  "@context": "https://schema.org",
+  "@type": "BreadcrumbList",
+  "itemListElement": [
+  {{- range $i, $p := $crumbs }}{{ if $i }},{{ end }}
+    {
+      "@type": "ListItem",
+      "position": {{ add $i 1 }},
+      "name": {{ $p.Title }},
+      "item": {{ $p.Permalink }}
+    }
+  {{- end }}
+  ]
+}
+</script>`)
+
 	t.addInternalTemplate("", "google_analytics.html", `{{ with .Site.GoogleAnalytics }}
 <script>
 (function(i,s,o,g,r,a,m){i['GoogleAnalyticsObject']=r;i[r]=i[r]||function(){
@@ -88,6 +88,21 @@ func init() {
 			},
 		)
 
+		ns.AddMethodMapping(ctx.Remarshal,
+			nil,
+			[][2]string{
+				{`{{ transform.Remarshal "yaml" "a = 1" | chomp }}`, `a: 1`},
+			},
+		)
+
+		ns.AddMethodMapping(ctx.Unmarshal,
+			[]string{"unmarshal"},
+			[][2]string{
+				{`{{ $m := "a: b" | unmarshal }}{{ $m.a }}`, `b`},
+				{`{{ range transform.Unmarshal "csv" "a,b\nc,d" }}{{ index . 1 }}{{ end }}`, `bd`},
+			},
+		)
+
 		return ns
 
 	}
@@ -0,0 +1,88 @@
+package transform
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/geego/gean/app/helpers"
+	"github.com/geego/gean/app/parser"
+	"github.com/govenue/assist"
+)
+
+// Unmarshal parses the given data, e.g. the output of readFile or a front
+// matter field, and returns the decoded value. The format is detected from
+// the data if it is not given, as in:
+//
+//	{{ $data := transform.Unmarshal "toml" $s }}
+//	{{ $data := $s | transform.Unmarshal }}
+//
+// The supported formats are json, yaml, toml, xml, csv and tsv, see the
+// parser package's UnmarshalData.
+func (ns *Namespace) Unmarshal(args ...interface{}) (interface{}, error) {
+	var (
+		format string
+		data   interface{}
+	)
+
+	switch len(args) {
+	case 1:
+		data = args[0]
+	case 2:
+		f, err := assist.ToStringE(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid unmarshal format: %s", err)
+		}
+		format = f
+		data = args[1]
+	default:
+		return nil, errors.New("unmarshal takes an optional format and the data to unmarshal")
+	}
+
+	s, err := assist.ToStringE(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal %T: %s", data, err)
+	}
+
+	return parser.UnmarshalData([]byte(s), format)
+}
+
+// Remarshal converts the given data to the given format, json, yaml or toml.
+// The data is either a string in any of the formats supported by Unmarshal,
+// or a map or a list, e.g. a page param:
+//
+//	{{ transform.Remarshal "yaml" (readFile "config.toml") }}
+func (ns *Namespace) Remarshal(format string, data interface{}) (string, error) {
+	mark, err := marshalMark(format)
+	if err != nil {
+		return "", err
+	}
+
+	switch data.(type) {
+	case string, template.HTML, []byte:
+		data, err = parser.UnmarshalData([]byte(assist.ToString(data)), "")
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var b bytes.Buffer
+	if err := parser.InterfaceToConfig(helpers.StringifyMapKeys(data), mark, &b); err != nil {
+		return "", fmt.Errorf("failed to marshal %s: %s", format, err)
+	}
+
+	return b.String(), nil
+}
+
+// marshalMark returns the front matter lead rune for the given format, as used
+// in InterfaceToConfig.
+func marshalMark(format string) (rune, error) {
+	switch strings.ToLower(format) {
+	case "json", "yaml", "yml", "toml":
+		return parser.FormatToLeadRune(format), nil
+	default:
+		return 0, fmt.Errorf("unsupported marshal format %q", format)
+	}
+}
@@ -0,0 +1,98 @@
+package transform
+
+import (
+	"fmt"
+	"html/template"
+	"testing"
+
+	"github.com/govenue/assert"
+	"github.com/govenue/configurator"
+	"github.com/govenue/require"
+)
+
+func TestUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	ns := New(newDeps(configurator.New()))
+
+	for i, test := range []struct {
+		args   []interface{}
+		expect interface{}
+	}{
+		{[]interface{}{`{"a": [1, "b"]}`}, map[string]interface{}{"a": []interface{}{float64(1), "b"}}},
+		{[]interface{}{`["a", "b"]`}, []interface{}{"a", "b"}},
+		{[]interface{}{"# Comment\ntitle = \"Gean\"\n[params]\nweight = 2"},
+			map[string]interface{}{"title": "Gean", "params": map[string]interface{}{"weight": int64(2)}}},
+		{[]interface{}{"[params]\nweight = 2"}, map[string]interface{}{"params": map[string]interface{}{"weight": int64(2)}}},
+		{[]interface{}{"+++\ntitle = \"Gean\"\n+++"}, map[string]interface{}{"title": "Gean"}},
+		{[]interface{}{"title: Gean\nparams:\n  weight: 2"},
+			map[string]interface{}{"title": "Gean", "params": map[string]interface{}{"weight": 2}}},
+		{[]interface{}{template.HTML("---\n- a\n- b: c")}, []interface{}{"a", map[string]interface{}{"b": "c"}}},
+		{[]interface{}{"a,b\n1,2"}, [][]string{{"a", "b"}, {"1", "2"}}},
+		{[]interface{}{"a\tb\n1\t2"}, [][]string{{"a", "b"}, {"1", "2"}}},
+		{[]interface{}{"csv", "a"}, [][]string{{"a"}}},
+		{[]interface{}{"YAML", []byte("a: 1")}, map[string]interface{}{"a": 1}},
+		{[]interface{}{`<?xml version="1.0"?><episodes><episode id="1">First</episode><episode id="2"><title>Second</title></episode><host>Alice</host></episodes>`},
+			map[string]interface{}{"episodes": map[string]interface{}{
+				"episode": []interface{}{
+					map[string]interface{}{"-id": "1", "#text": "First"},
+					map[string]interface{}{"-id": "2", "title": "Second"},
+				},
+				"host": "Alice",
+			}}},
+		// errors
+		{[]interface{}{}, false},
+		{[]interface{}{"json", "a", "b"}, false},
+		{[]interface{}{"ini", "a = 1"}, false},
+		{[]interface{}{tstNoStringer{}}, false},
+		{[]interface{}{`{"a": }`}, false},
+		{[]interface{}{"<a><b></a>"}, false},
+		{[]interface{}{"toml", "a = "}, false},
+	} {
+		errMsg := fmt.Sprintf("[%d] %v", i, test.args)
+
+		result, err := ns.Unmarshal(test.args...)
+
+		if b, ok := test.expect.(bool); ok && !b {
+			require.Error(t, err, errMsg)
+			continue
+		}
+
+		require.NoError(t, err, errMsg)
+		assert.Equal(t, test.expect, result, errMsg)
+	}
+}
+
+func TestRemarshal(t *testing.T) {
+	t.Parallel()
+
+	ns := New(newDeps(configurator.New()))
+
+	for i, test := range []struct {
+		format string
+		data   interface{}
+		expect interface{}
+	}{
+		{"yaml", "title = \"Gean\"", "title: Gean\n"},
+		{"toml", "title: Gean", "title = \"Gean\"\n"},
+		{"json", "params:\n  tags: [a]", "{\n   \"params\": {\n      \"tags\": [\n         \"a\"\n      ]\n   }\n}\n"},
+		{"TOML", map[interface{}]interface{}{"weight": 2}, "weight = 2\n"},
+		// errors
+		{"ini", "title: Gean", false},
+		{"yaml", "{", false},
+		{"toml", []interface{}{"a"}, false},
+		{"json", nil, false},
+	} {
+		errMsg := fmt.Sprintf("[%d] %s %v", i, test.format, test.data)
+
+		result, err := ns.Remarshal(test.format, test.data)
+
+		if b, ok := test.expect.(bool); ok && !b {
+			require.Error(t, err, errMsg)
+			continue
+		}
+
+		require.NoError(t, err, errMsg)
+		assert.Equal(t, test.expect, result, errMsg)
+	}
+}
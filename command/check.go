@@ -1,6 +1,7 @@
 package command
 
 import (
+	"github.com/geego/gean/app/geanlib"
 	"github.com/govenue/goman"
 )
 
@@ -8,3 +9,22 @@ var commandCheck = &goman.Command{
 	Use:   "check",
 	Short: "Contains some verification checks",
 }
+
+// buildForCheck builds the sites to memory, including the static files,
+// so the checks can inspect the output.
+func (c *commandeer) buildForCheck() (*geanlib.HugoSites, error) {
+	if err := c.copyStatic(); err != nil {
+		return nil, newSystemError("Error copying static files:", err)
+	}
+
+	sites, err := geanlib.NewHugoSites(*c.DepsCfg)
+	if err != nil {
+		return nil, newSystemError("Error creating sites", err)
+	}
+
+	if err := sites.Build(geanlib.BuildCfg{}); err != nil {
+		return nil, newSystemError("Error building site:", err)
+	}
+
+	return sites, nil
+}
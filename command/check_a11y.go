@@ -0,0 +1,115 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/geego/gean/app/checks"
+	"github.com/govenue/goman"
+	"github.com/govenue/notepad"
+)
+
+var checkA11yFormat string
+
+var checkA11yCmd = &goman.Command{
+	Use:   "a11y",
+	Short: "Check the rendered site for accessibility issues",
+	Long: `Render the site to memory and audit every HTML page for common
+accessibility issues: images without alt text, skipped heading levels, links
+without text, a missing lang attribute on <html>, duplicate element IDs, audio
+and video without captions or a transcript and low contrast inline styles.
+
+A transcript is found if the media element refers to it with
+aria-describedby. The report is grouped by page and can be printed as text
+or JSON.`,
+}
+
+func init() {
+	initHugoBuilderFlags(checkA11yCmd)
+	checkA11yCmd.Flags().StringVar(&checkA11yFormat, "format", "text", "report format, text or json")
+
+	checkA11yCmd.RunE = checkA11y
+}
+
+// a11yPageReport is the report for a page as printed.
+type a11yPageReport struct {
+	Filename string             `json:"filename"`
+	Source   string             `json:"source,omitempty"`
+	Issues   []checks.A11yIssue `json:"issues"`
+}
+
+func checkA11y(cmd *goman.Command, args []string) error {
+	if checkA11yFormat != "text" && checkA11yFormat != "json" {
+		return newUserError(fmt.Sprintf("unknown format %q, must be text or json", checkA11yFormat))
+	}
+
+	renderToMemory = true
+
+	cfg, err := InitializeConfig(checkA11yCmd)
+	if err != nil {
+		return err
+	}
+
+	c, err := newCommandeer(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Needed to map the output files to their source.
+	c.Set("trackRenderInfo", true)
+
+	sites, err := c.buildForCheck()
+	if err != nil {
+		return err
+	}
+
+	reports, err := checks.CheckA11y(c.Fs.Destination, "/")
+	if err != nil {
+		return newSystemError("Error checking accessibility:", err)
+	}
+
+	contentDir := c.Cfg.GetString("contentDir")
+
+	var (
+		pages  = make([]a11yPageReport, 0, len(reports))
+		issues int
+	)
+	for _, r := range reports {
+		pages = append(pages, a11yPageReport{
+			Filename: r.Filename,
+			Source:   checkSource(sites, contentDir, r.Filename),
+			Issues:   r.Issues,
+		})
+		issues += len(r.Issues)
+	}
+
+	if checkA11yFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(pages); err != nil {
+			return newSystemError("Error writing report:", err)
+		}
+	} else {
+		for _, p := range pages {
+			if p.Source != "" {
+				notepad.FEEDBACK.Printf("%s (source: %s)\n", p.Filename, p.Source)
+			} else {
+				notepad.FEEDBACK.Println(p.Filename)
+			}
+			for _, issue := range p.Issues {
+				notepad.FEEDBACK.Println("  " + issue.String())
+			}
+		}
+	}
+
+	if issues > 0 {
+		return newSystemErrorF("%d accessibility issue(s) found in %d page(s)", issues, len(pages))
+	}
+
+	if checkA11yFormat == "text" {
+		notepad.FEEDBACK.Println("No accessibility issues found")
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/geego/gean/app/checks"
+	"github.com/geego/gean/app/geanlib"
+	"github.com/geego/gean/app/helpers"
+	"github.com/govenue/goman"
+	"github.com/govenue/notepad"
+)
+
+// snapshotsDir is the dir in cacheDir the snapshots are stored in.
+const snapshotsDir = "gean_snapshots"
+
+var (
+	checkDiffAgainst string
+	checkDiffSave    string
+	checkDiffSummary bool
+)
+
+var checkDiffCmd = &goman.Command{
+	Use:   "diff",
+	Short: "Compare the rendered site to a previous build",
+	Long: `Render the site to memory and compare every output file to a
+previous build, e.g. to review the effect of a theme upgrade. The previous
+build is either a snapshot saved with --save or a directory, e.g. an earlier
+copy of the publish dir.
+
+The generator tag and timestamps are ignored. A summary of the files added,
+removed and changed is printed, along with a unified diff of every changed
+file unless --summary is set.
+
+Snapshots are stored in cacheDir. Use --save to store the current build,
+with or without --against:
+
+  gean check diff --save before
+  # upgrade the theme
+  gean check diff --against before`,
+}
+
+func init() {
+	initHugoBuilderFlags(checkDiffCmd)
+	checkDiffCmd.Flags().StringVar(&checkDiffAgainst, "against", "", "snapshot name or directory to compare to")
+	checkDiffCmd.Flags().StringVar(&checkDiffSave, "save", "", "save the current build as a snapshot with this name")
+	checkDiffCmd.Flags().BoolVar(&checkDiffSummary, "summary", false, "only print the summary")
+
+	checkDiffCmd.RunE = checkDiff
+}
+
+func checkDiff(cmd *goman.Command, args []string) error {
+	if checkDiffAgainst == "" && checkDiffSave == "" {
+		return newUserError("one of --against and --save is required")
+	}
+
+	renderToMemory = true
+
+	cfg, err := InitializeConfig(checkDiffCmd)
+	if err != nil {
+		return err
+	}
+
+	c, err := newCommandeer(cfg)
+	if err != nil {
+		return err
+	}
+
+	var previousDir string
+	if checkDiffAgainst != "" {
+		// Fail before the build if there is nothing to compare to.
+		previousDir, err = c.diffTarget(checkDiffAgainst)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := c.buildForCheck(); err != nil {
+		return err
+	}
+
+	current, err := checks.ReadOutput(c.Fs.Destination, "/")
+	if err != nil {
+		return newSystemError("Error reading the output:", err)
+	}
+	delete(current, geanlib.ManifestFilename)
+
+	var differ int
+	if checkDiffAgainst != "" {
+		previous, err := checks.ReadOutput(c.Fs.Source, previousDir)
+		if err != nil {
+			return newSystemError("Error reading the previous build:", err)
+		}
+		delete(previous, geanlib.ManifestFilename)
+
+		differ = printOutputDiffs(checks.DiffOutput(previous, current), len(current))
+	}
+
+	if checkDiffSave != "" {
+		dir := c.snapshotDir(checkDiffSave)
+		if err := checks.WriteSnapshot(c.Fs.Source, dir, current); err != nil {
+			return newSystemError("Error saving snapshot:", err)
+		}
+		notepad.FEEDBACK.Printf("Saved snapshot %q to %s\n", checkDiffSave, dir)
+	}
+
+	if differ > 0 {
+		return newSystemErrorF("%d output file(s) differ from %s", differ, checkDiffAgainst)
+	}
+
+	return nil
+}
+
+// snapshotDir returns the dir of the snapshot with the given name.
+func (c *commandeer) snapshotDir(name string) string {
+	return filepath.Join(c.Cfg.GetString("cacheDir"), snapshotsDir, name)
+}
+
+// diffTarget returns the dir of the snapshot with the given name if found,
+// else against as a dir.
+func (c *commandeer) diffTarget(against string) (string, error) {
+	if dir := c.snapshotDir(against); filepath.Base(against) == against {
+		if exists, _ := helpers.DirExists(dir, c.Fs.Source); exists {
+			return dir, nil
+		}
+	}
+
+	dir := c.PathSpec().AbsPathify(against)
+	if exists, _ := helpers.DirExists(dir, c.Fs.Source); !exists {
+		return "", newUserError(fmt.Sprintf("no snapshot or directory named %q found", against))
+	}
+
+	return dir, nil
+}
+
+// printOutputDiffs prints the diffs and a summary, and returns the number
+// of files that differ.
+func printOutputDiffs(diffs []*checks.OutputDiff, total int) int {
+	var added, removed, changed int
+
+	for _, d := range diffs {
+		switch d.Status {
+		case checks.OutputAdded:
+			added++
+		case checks.OutputRemoved:
+			removed++
+		case checks.OutputChanged:
+			changed++
+		}
+
+		if checkDiffSummary {
+			continue
+		}
+
+		if d.Diff != "" {
+			notepad.FEEDBACK.Print(d.Diff)
+		} else {
+			notepad.FEEDBACK.Printf("%s %s\n", d.Status, d.Filename)
+		}
+	}
+
+	notepad.FEEDBACK.Printf("%d added, %d removed, %d changed, %d unchanged\n",
+		added, removed, changed, total-added-changed)
+
+	return len(diffs)
+}
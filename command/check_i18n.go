@@ -0,0 +1,159 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/geego/gean/app/geanlib"
+	"github.com/geego/gean/app/i18n"
+	"github.com/govenue/goman"
+	"github.com/govenue/notepad"
+)
+
+var checkI18nFormat string
+
+var checkI18nCmd = &goman.Command{
+	Use:   "i18n",
+	Short: "Check the translation coverage of every language",
+	Long: `Build the site to memory and compare the i18n bundle of every
+language to the bundle of the default content language. This lists the
+translation IDs that are missing, those with the same translation as in the
+default language, which most likely are never translated, and those never
+used while rendering.
+
+The content pages not translated to every language are listed too. Pages are
+translations of each other if they have the same translation key, see
+TranslationKey. The report starts with a coverage table and can be printed as
+text or JSON.`,
+}
+
+func init() {
+	initHugoBuilderFlags(checkI18nCmd)
+	checkI18nCmd.Flags().StringVar(&checkI18nFormat, "format", "text", "report format, text or json")
+
+	checkI18nCmd.RunE = checkI18n
+}
+
+// i18nLangReport is the report for a language as printed.
+type i18nLangReport struct {
+	Lang    string                   `json:"lang"`
+	Default bool                     `json:"default"`
+	I18n    *i18n.Coverage           `json:"i18n"`
+	Content *geanlib.ContentCoverage `json:"content"`
+}
+
+func checkI18n(cmd *goman.Command, args []string) error {
+	if checkI18nFormat != "text" && checkI18nFormat != "json" {
+		return newUserError(fmt.Sprintf("unknown format %q, must be text or json", checkI18nFormat))
+	}
+
+	renderToMemory = true
+
+	cfg, err := InitializeConfig(checkI18nCmd)
+	if err != nil {
+		return err
+	}
+
+	c, err := newCommandeer(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Keep a reference to the bundles and the IDs looked up.
+	tp := i18n.NewTranslationProvider()
+	c.DepsCfg.TranslationProvider = tp
+
+	sites, err := c.buildForCheck()
+	if err != nil {
+		return err
+	}
+
+	var (
+		defaultLang = c.Cfg.GetString("defaultContentLanguage")
+		langs       []string
+	)
+	for _, s := range sites.Sites {
+		langs = append(langs, s.Language.Lang)
+	}
+
+	var (
+		reports  []i18nLangReport
+		content  = sites.ContentCoverage()
+		missing  int
+		coverage = tp.Coverage(defaultLang, langs)
+	)
+	for i, lang := range langs {
+		r := i18nLangReport{Lang: lang, Default: lang == defaultLang, Content: content[i]}
+		if coverage != nil {
+			r.I18n = coverage[i]
+			missing += len(r.I18n.Missing)
+		}
+		missing += len(r.Content.Missing)
+		reports = append(reports, r)
+	}
+
+	if checkI18nFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			return newSystemError("Error writing report:", err)
+		}
+	} else {
+		printI18nReports(reports)
+	}
+
+	if missing > 0 {
+		return newSystemErrorF("%d missing translation(s) found", missing)
+	}
+
+	return nil
+}
+
+func printI18nReports(reports []i18nLangReport) {
+	notepad.FEEDBACK.Printf("%-12s %-16s %-10s %-8s %-16s\n", "LANGUAGE", "I18N", "IDENTICAL", "UNUSED", "PAGES")
+
+	for _, r := range reports {
+		lang := r.Lang
+		if r.Default {
+			lang += "*"
+		}
+
+		keys, identical, unused := "-", "-", "-"
+		if r.I18n != nil {
+			keys = coverageString(r.I18n.Translated(), r.I18n.Total)
+			identical = fmt.Sprint(len(r.I18n.Identical))
+			unused = fmt.Sprint(len(r.I18n.Unused))
+		}
+
+		notepad.FEEDBACK.Printf("%-12s %-16s %-10s %-8s %-16s\n", lang, keys, identical, unused,
+			coverageString(r.Content.Translated(), r.Content.Total))
+	}
+
+	for _, r := range reports {
+		if r.I18n != nil {
+			printI18nIDs(r.Lang, "missing", r.I18n.Missing)
+			printI18nIDs(r.Lang, "identical", r.I18n.Identical)
+			printI18nIDs(r.Lang, "unused", r.I18n.Unused)
+		}
+		for _, filename := range r.Content.Missing {
+			notepad.FEEDBACK.Printf("%s: missing translation of %s\n", r.Lang, filename)
+		}
+	}
+}
+
+func printI18nIDs(lang, what string, ids []string) {
+	if len(ids) > 0 {
+		notepad.FEEDBACK.Printf("%s: %s i18n IDs: %s\n", lang, what, strings.Join(ids, ", "))
+	}
+}
+
+// coverageString formats n of total as e.g. "9/10 (90%)".
+func coverageString(n, total int) string {
+	pct := 100
+	if total > 0 {
+		pct = n * 100 / total
+	}
+	return fmt.Sprintf("%d/%d (%d%%)", n, total, pct)
+}
@@ -0,0 +1,119 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/geego/gean/app/checks"
+	"github.com/geego/gean/app/geanlib"
+	"github.com/govenue/goman"
+	"github.com/govenue/notepad"
+)
+
+var (
+	checkLinksIgnore       []string
+	checkLinksExternal     bool
+	checkLinksExternalBase string
+)
+
+var checkLinksCmd = &goman.Command{
+	Use:   "links",
+	Short: "Check the links in the rendered site",
+	Long: `Render the site to memory and check that every internal link and
+image resolves to a published file, that every #fragment points to an element
+ID on the target page and that every ref and relref lookup succeeds.
+
+Links to other sites are only checked with --external. Use --externalBase to
+send those requests to a local stand-in instead, e.g. in CI.`,
+}
+
+func init() {
+	initHugoBuilderFlags(checkLinksCmd)
+	checkLinksCmd.Flags().StringSliceVar(&checkLinksIgnore, "ignore", []string{}, "regular expressions matching links to skip")
+	checkLinksCmd.Flags().BoolVar(&checkLinksExternal, "external", false, "also check links to other sites")
+	checkLinksCmd.Flags().StringVar(&checkLinksExternalBase, "externalBase", "", "request external links from this base URL, with the host as the first path element")
+
+	checkLinksCmd.RunE = checkLinks
+}
+
+func checkLinks(cmd *goman.Command, args []string) error {
+	renderToMemory = true
+
+	cfg, err := InitializeConfig(checkLinksCmd)
+	if err != nil {
+		return err
+	}
+
+	c, err := newCommandeer(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Needed to map the output files to their source.
+	c.Set("trackRenderInfo", true)
+
+	sites, err := c.buildForCheck()
+	if err != nil {
+		return err
+	}
+
+	checker, err := checks.NewLinkChecker(c.Fs.Destination, "/", c.Cfg.GetString("baseURL"))
+	if err != nil {
+		return newUserError(err)
+	}
+
+	for _, pattern := range checkLinksIgnore {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return newUserError("invalid --ignore pattern:", err)
+		}
+		checker.Ignore = append(checker.Ignore, re)
+	}
+
+	checker.External = checkLinksExternal || checkLinksExternalBase != ""
+	checker.ExternalBase = checkLinksExternalBase
+
+	linkErrs, err := checker.Check()
+	if err != nil {
+		return newSystemError("Error checking links:", err)
+	}
+
+	contentDir := c.Cfg.GetString("contentDir")
+
+	for _, e := range linkErrs {
+		msg := e.Error()
+		if source := checkSource(sites, contentDir, e.Filename); source != "" {
+			msg += " (source: " + source + ")"
+		}
+		notepad.FEEDBACK.Println(msg)
+	}
+
+	refErrs := sites.RefErrors()
+	for _, e := range refErrs {
+		pos := filepath.ToSlash(filepath.Join(contentDir, e.Filename))
+		if e.Line > 0 {
+			pos = fmt.Sprintf("%s:%d", pos, e.Line)
+		}
+		notepad.FEEDBACK.Printf("%s: ref %q: %s\n", pos, e.Ref, strings.TrimSpace(e.Err.Error()))
+	}
+
+	if n := len(linkErrs) + len(refErrs); n > 0 {
+		return newSystemErrorF("%d broken link(s) found", n)
+	}
+
+	notepad.FEEDBACK.Println("No broken links found")
+
+	return nil
+}
+
+// checkSource returns the content file that rendered the given file,
+// relative to the publish dir, or an empty string if it isn't known.
+func checkSource(sites *geanlib.HugoSites, contentDir, filename string) string {
+	info := sites.RenderInfo(filepath.Join(string(filepath.Separator), filepath.FromSlash(filename)))
+	if info == nil || info.Page == nil || info.Page.File.Path() == "" {
+		return ""
+	}
+	return filepath.ToSlash(filepath.Join(contentDir, info.Page.File.Path()))
+}
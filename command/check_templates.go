@@ -0,0 +1,57 @@
+package command
+
+import (
+	"github.com/govenue/goman"
+	"github.com/govenue/notepad"
+)
+
+var checkTemplatesCmd = &goman.Command{
+	Use:   "templates",
+	Short: "Check the templates for problems",
+	Long: `Build the site to memory and analyze the templates. This reports
+templates that fail to parse, e.g. because of calls to unknown functions,
+partials that don't exist, shortcodes used in content that aren't defined,
+layouts no page is rendered with and page params read in the templates but
+never set in any front matter.`,
+}
+
+func init() {
+	initHugoBuilderFlags(checkTemplatesCmd)
+
+	checkTemplatesCmd.RunE = checkTemplates
+}
+
+func checkTemplates(cmd *goman.Command, args []string) error {
+	renderToMemory = true
+
+	cfg, err := InitializeConfig(checkTemplatesCmd)
+	if err != nil {
+		return err
+	}
+
+	c, err := newCommandeer(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Needed to find the layouts not used.
+	c.Set("trackRenderInfo", true)
+
+	sites, err := c.buildForCheck()
+	if err != nil {
+		return err
+	}
+
+	problems := sites.CheckTemplates()
+	for _, p := range problems {
+		notepad.FEEDBACK.Println(p)
+	}
+
+	if len(problems) > 0 {
+		return newSystemErrorF("%d template problem(s) found", len(problems))
+	}
+
+	notepad.FEEDBACK.Println("No template problems found")
+
+	return nil
+}
@@ -14,6 +14,10 @@ type commandeer struct {
 
 	serverPorts []int
 
+	// The files copied from the static dirs in the last full sync, mapping
+	// the absolute filename in the publish dir to a description of the source.
+	staticFiles map[string]string
+
 	configured bool
 }
 
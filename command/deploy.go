@@ -0,0 +1,116 @@
+package command
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/geego/gean/app/deploy"
+	"github.com/geego/gean/app/publish"
+	"github.com/govenue/goman"
+)
+
+var (
+	deployTarget     string
+	deployDryRun     bool
+	deployForce      bool
+	deployMaxDeletes int
+)
+
+var deployCmd = &goman.Command{
+	Use:   "deploy",
+	Short: "Deploy your site to a target",
+	Long: `Deploy your site to one of the targets configured in the deploy
+section of the site config.
+
+The published files are compared with the target and only new or changed
+files are uploaded. Files removed locally are deleted from the target if
+the target has deleteStale set.
+
+Build the site before deploying.`,
+	RunE: deployRun,
+}
+
+func init() {
+	deployCmd.Flags().StringVar(&deployTarget, "target", "", "the target to deploy to (default is the first configured target)")
+	deployCmd.Flags().BoolVar(&deployDryRun, "dryRun", false, "print the changes without deploying")
+	deployCmd.Flags().BoolVar(&deployForce, "force", false, "deploy without confirmation even if more than maxDeletes files will be deleted")
+	deployCmd.Flags().IntVar(&deployMaxDeletes, "maxDeletes", -1, "ask for confirmation when deleting more than this number of files (default from config, 256)")
+	deployCmd.Flags().StringVarP(&source, "source", "s", "", "filesystem path to read files relative from")
+	deployCmd.Flags().StringVarP(&destination, "destination", "d", "", "filesystem path to deploy files from")
+}
+
+func deployRun(cmd *goman.Command, args []string) error {
+	cfg, err := InitializeConfig()
+	if err != nil {
+		return err
+	}
+
+	c, err := newCommandeer(cfg)
+	if err != nil {
+		return err
+	}
+
+	if !c.Cfg.IsSet("deploy") {
+		return newUserError("no deploy section found in site config")
+	}
+
+	dcfg, err := deploy.DecodeConfig(c.Cfg.GetStringMap("deploy"))
+	if err != nil {
+		return newUserError(err)
+	}
+
+	if deployMaxDeletes >= 0 {
+		dcfg.MaxDeletes = deployMaxDeletes
+	}
+
+	publishDir, err := deployPublishDir(c.PathSpec().AbsPathify(c.Cfg.GetString("publishDir")), c.Cfg.GetBool("atomicPublish"))
+	if err != nil {
+		return newUserError(err)
+	}
+
+	d, err := deploy.New(dcfg, deployTarget, c.Fs.Destination, publishDir)
+	if err != nil {
+		return newUserError(err)
+	}
+
+	d.DryRun = deployDryRun
+	d.Force = deployForce
+	d.Out = os.Stdout
+	d.Confirm = confirm
+
+	if _, err := d.Deploy(); err != nil {
+		return newSystemError("Error deploying to", d.Target().Name+":", err)
+	}
+
+	return nil
+}
+
+// deployPublishDir returns the dir to deploy from. With atomicPublish, that is
+// the current build the current link points to, as the deploy walk does not
+// follow symlinks.
+func deployPublishDir(publishDir string, atomicPublish bool) (string, error) {
+	if !atomicPublish {
+		return publishDir, nil
+	}
+
+	dir, err := filepath.EvalSymlinks(filepath.Join(publishDir, publish.CurrentLink))
+	if err != nil {
+		return "", fmt.Errorf("no current build found in %q; build the site before deploying: %s", publishDir, err)
+	}
+
+	return dir, nil
+}
+
+// confirm asks the user a yes/no question on stdin.
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N] ", prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,64 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/geego/gean/app/deploy"
+	"github.com/geego/gean/app/publish"
+	"github.com/govenue/fsintra"
+	"github.com/govenue/require"
+)
+
+func TestDeployAtomicPublish(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skip TestDeployAtomicPublish as os.Symlink needs administrator rights on Windows")
+	}
+
+	assert := require.New(t)
+
+	root, err := ioutil.TempDir("", "gean-deploy")
+	assert.NoError(err)
+	defer os.RemoveAll(root)
+
+	publishDir := filepath.Join(root, "public")
+	remoteDir := filepath.Join(root, "remote")
+
+	_, err = deployPublishDir(publishDir, true)
+	assert.Error(err)
+
+	dir, err := publish.NewBuild(publishDir, time.Date(2017, 10, 19, 10, 15, 0, 0, time.UTC))
+	assert.NoError(err)
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte("Home"), 0644))
+	assert.NoError(publish.Activate(publishDir, filepath.Base(dir)))
+
+	dcfg, err := deploy.DecodeConfig(map[string]interface{}{
+		"targets": []map[string]interface{}{
+			{"name": "test", "url": "file://" + filepath.ToSlash(remoteDir)},
+		},
+	})
+	assert.NoError(err)
+
+	deployDir, err := deployPublishDir(publishDir, true)
+	assert.NoError(err)
+
+	d, err := deploy.New(dcfg, "", fsintra.NewOsFs(), deployDir)
+	assert.NoError(err)
+
+	p, err := d.Deploy()
+	assert.NoError(err)
+	assert.Len(p.Uploads, 1)
+	assert.Equal("index.html", p.Uploads[0].Path)
+
+	b, err := ioutil.ReadFile(filepath.Join(remoteDir, "index.html"))
+	assert.NoError(err)
+	assert.Equal("Home", string(b))
+
+	deployDir, err = deployPublishDir(publishDir, false)
+	assert.NoError(err)
+	assert.Equal(publishDir, deployDir)
+}
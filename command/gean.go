@@ -2,6 +2,7 @@ package command
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
@@ -109,6 +110,13 @@ built with love by spf13 and friends in Go. `,
 			c.watchConfig()
 		}
 
+		if cfg.Cfg.GetBool("atomicPublish") {
+			if buildWatch {
+				return newUserError("--atomicPublish cannot be combined with --watch")
+			}
+			return c.buildAtomic()
+		}
+
 		return c.build()
 	},
 }
@@ -166,12 +174,19 @@ func AddCommands() {
 	HugoCmd.AddCommand(envCmd)
 	HugoCmd.AddCommand(configCmd)
 	HugoCmd.AddCommand(commandCheck)
+	commandCheck.AddCommand(checkLinksCmd)
+	commandCheck.AddCommand(checkTemplatesCmd)
+	commandCheck.AddCommand(checkA11yCmd)
+	commandCheck.AddCommand(checkI18nCmd)
+	commandCheck.AddCommand(checkDiffCmd)
 	HugoCmd.AddCommand(commandBenchmark)
 	HugoCmd.AddCommand(convertCmd)
 	HugoCmd.AddCommand(newCmd)
 	HugoCmd.AddCommand(listCmd)
 	HugoCmd.AddCommand(undraftCmd)
 	HugoCmd.AddCommand(importCmd)
+	HugoCmd.AddCommand(deployCmd)
+	HugoCmd.AddCommand(rollbackCmd)
 
 	HugoCmd.AddCommand(genCmd)
 	genCmd.AddCommand(genautocompleteCmd)
@@ -200,6 +215,8 @@ func initRootPersistentFlags() {
 // Called by initHugoBuilderFlags.
 func initHugoBuildCommonFlags(cmd *goman.Command) {
 	cmd.Flags().Bool("cleanDestinationDir", false, "remove files from destination not found in static directories")
+	cmd.Flags().Bool("cleanStaleOutput", true, "remove files written by the previous build that are no longer produced")
+	cmd.Flags().Bool("strict", false, "fail the build on problems otherwise reported as warnings, e.g. output path collisions")
 	cmd.Flags().BoolP("buildDrafts", "D", false, "include content marked as draft")
 	cmd.Flags().BoolP("buildFuture", "F", false, "include content with publishdate in the future")
 	cmd.Flags().BoolP("buildExpired", "E", false, "include expired content")
@@ -211,6 +228,7 @@ func initHugoBuildCommonFlags(cmd *goman.Command) {
 	cmd.Flags().StringVarP(&layoutDir, "layoutDir", "l", "", "filesystem path to layout directory")
 	cmd.Flags().StringVarP(&cacheDir, "cacheDir", "", "", "filesystem path to cache directory. Defaults: $TMPDIR/hugo_cache/")
 	cmd.Flags().BoolP("ignoreCache", "", false, "ignores the cache directory")
+	cmd.Flags().Bool("offline", false, "use only cached remote data, and fail on remote data not in the cache")
 	cmd.Flags().StringVarP(&destination, "destination", "d", "", "filesystem path to write files to")
 	cmd.Flags().StringVarP(&theme, "theme", "t", "", "theme to use (located in /themes/THEMENAME/)")
 	cmd.Flags().StringVarP(&themesDir, "themesDir", "", "", "filesystem path to themes directory")
@@ -256,6 +274,7 @@ func init() {
 	initBenchmarkBuildingFlags(HugoCmd)
 
 	HugoCmd.Flags().BoolVarP(&buildWatch, "watch", "w", false, "watch filesystem for changes and recreate as needed")
+	HugoCmd.Flags().Bool("atomicPublish", false, "render into a new build dir and switch the current symlink in publishDir on success")
 	hugoCmdV = HugoCmd
 
 	// Set bash-completion
@@ -416,6 +435,11 @@ func createLogger(cfg config.Provider) (*notepad.Notepad, error) {
 		stdoutThreshold = notepad.LevelInfo
 	}
 
+	if isInspectorEnabled(cfg) {
+		// Keep the warnings around for the server's inspector.
+		logHandle = io.MultiWriter(logHandle, buildWarnings)
+	}
+
 	if cfg.GetBool("debug") {
 		stdoutThreshold = notepad.LevelDebug
 	}
@@ -453,11 +477,15 @@ func (c *commandeer) initializeFlags(cmd *goman.Command) {
 		"pluralizeListTitles",
 		"preserveTaxonomyNames",
 		"ignoreCache",
+		"offline",
 		"forceSyncStatic",
 		"noTimes",
 		"noChmod",
 		"templateMetrics",
 		"templateMetricsHints",
+		"atomicPublish",
+		"cleanStaleOutput",
+		"strict",
 	}
 
 	// Remove these in Hugo 0.23.
@@ -515,6 +543,16 @@ func (c *commandeer) build(watches ...bool) error {
 		return fmt.Errorf("Error building site: %s", err)
 	}
 
+	if err := c.checkOutputCollisions(!watch && c.Cfg.GetBool("strict")); err != nil {
+		return fmt.Errorf("Error building site: %s", err)
+	}
+
+	if !watch && c.Cfg.GetBool("cleanStaleOutput") {
+		if err := c.cleanStaleOutput(); err != nil {
+			return fmt.Errorf("Error removing stale output: %s", err)
+		}
+	}
+
 	if buildWatch {
 		watchDirs, err := c.getDirList()
 		if err != nil {
@@ -528,7 +566,44 @@ func (c *commandeer) build(watches ...bool) error {
 	return nil
 }
 
+// checkOutputCollisions reports the output files written by more than one
+// source in the last build, e.g. two pages with the same URL or a page and
+// an alias. They are warnings unless strict is set, which it never is for the
+// full rebuild on config changes.
+func (c *commandeer) checkOutputCollisions(strict bool) error {
+	publishDir := c.PathSpec().AbsPathify(c.Cfg.GetString("publishDir"))
+
+	log := c.Logger.WARN
+	if strict {
+		log = c.Logger.ERROR
+	}
+
+	collisions := Hugo.OutputCollisions(c.staticFiles)
+	for _, collision := range collisions {
+		filename, err := filepath.Rel(publishDir, collision.Filename)
+		if err != nil {
+			filename = collision.Filename
+		}
+		log.Printf("%q is written by %s\n", filepath.ToSlash(filename), joinSources(collision.Sources))
+	}
+
+	if strict && len(collisions) > 0 {
+		return fmt.Errorf("%d output path collision(s)", len(collisions))
+	}
+
+	return nil
+}
+
+// joinSources joins the sources as "a and b" or "a, b and c".
+func joinSources(sources []string) string {
+	if len(sources) < 2 {
+		return strings.Join(sources, "")
+	}
+	return strings.Join(sources[:len(sources)-1], ", ") + " and " + sources[len(sources)-1]
+}
+
 func (c *commandeer) copyStatic() error {
+	c.staticFiles = make(map[string]string)
 	return c.doWithPublishDirs(c.copyStaticTo)
 }
 
@@ -600,7 +675,18 @@ func (c *commandeer) copyStaticTo(dirs *src.Dirs, publishDir string) error {
 
 	// because we are using a baseFs (to get the union right).
 	// set sync src to root
-	return syncer.Sync(publishDir, helpers.FilePathSeparator)
+	if err := syncer.Sync(publishDir, helpers.FilePathSeparator); err != nil {
+		return err
+	}
+
+	// Record the synced files so they are kept by the stale output cleanup.
+	return fsintra.Walk(staticSourceFs, helpers.FilePathSeparator, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		c.staticFiles[filepath.Join(publishDir, path)] = "static file " + filepath.ToSlash(path)
+		return nil
+	})
 }
 
 // getDirList provides NewWatcher() with a list of directories to watch for changes.
@@ -685,6 +771,8 @@ func (c *commandeer) getDirList() ([]string, error) {
 	_ = helpers.SymbolicWalk(c.Fs.Source, c.PathSpec().AbsPathify(c.Cfg.GetString("contentDir")), walker)
 	_ = helpers.SymbolicWalk(c.Fs.Source, i18nDir, walker)
 	_ = helpers.SymbolicWalk(c.Fs.Source, layoutDir, walker)
+	// The archetype dirs hold the front matter schemas.
+	_ = helpers.SymbolicWalk(c.Fs.Source, c.PathSpec().AbsPathify(c.Cfg.GetString("archetypeDir")), walker)
 	for _, staticDir := range staticDirs {
 		_ = helpers.SymbolicWalk(c.Fs.Source, staticDir, walker)
 	}
@@ -694,6 +782,7 @@ func (c *commandeer) getDirList() ([]string, error) {
 		_ = helpers.SymbolicWalk(c.Fs.Source, filepath.Join(themesDir, "layouts"), walker)
 		_ = helpers.SymbolicWalk(c.Fs.Source, filepath.Join(themesDir, "i18n"), walker)
 		_ = helpers.SymbolicWalk(c.Fs.Source, filepath.Join(themesDir, "data"), walker)
+		_ = helpers.SymbolicWalk(c.Fs.Source, filepath.Join(themesDir, "archetypes"), walker)
 	}
 
 	return a, nil
@@ -706,7 +795,13 @@ func (c *commandeer) recreateAndBuildSites(watching bool) (err error) {
 	if !quiet {
 		c.Logger.FEEDBACK.Println("Started building sites ...")
 	}
-	return Hugo.Build(geanlib.BuildCfg{CreateSitesFromConfig: true, Watching: watching, PrintStats: !quiet})
+	if err := Hugo.Build(geanlib.BuildCfg{CreateSitesFromConfig: true, Watching: watching, PrintStats: !quiet}); err != nil {
+		return err
+	}
+	if watching {
+		return c.checkOutputCollisions(false)
+	}
+	return nil
 }
 
 func (c *commandeer) resetAndBuildSites(watching bool) (err error) {
@@ -754,6 +849,8 @@ func (c *commandeer) rebuildSites(events []fsnotify.Event) error {
 		// Make sure we always render the home page
 		visited[home] = true
 	}
+	// The output collisions are only recorded by full builds, so they are not
+	// checked here.
 	return Hugo.Build(geanlib.BuildCfg{PrintStats: !quiet, Watching: true, RecentlyVisited: visited}, events...)
 }
 
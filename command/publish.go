@@ -0,0 +1,151 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/geego/gean/app/geanlib"
+	"github.com/geego/gean/app/publish"
+	"github.com/govenue/goman"
+	"github.com/govenue/notepad"
+)
+
+var rollbackList bool
+
+var rollbackCmd = &goman.Command{
+	Use:   "rollback [build]",
+	Short: "Repoint the published site to an earlier build",
+	Long: `Repoint the "current" symlink in publishDir to an earlier build.
+
+This only works for sites built with --atomicPublish or atomicPublish = true
+in the site config. Without arguments, the build before the current one is
+activated. Use --list to see the available builds.`,
+	RunE: rollback,
+}
+
+func init() {
+	rollbackCmd.Flags().BoolVar(&rollbackList, "list", false, "list the available builds")
+	rollbackCmd.Flags().StringVarP(&source, "source", "s", "", "filesystem path to read files relative from")
+	rollbackCmd.Flags().StringVarP(&destination, "destination", "d", "", "filesystem path the site is published to")
+}
+
+// buildAtomic builds the site into a new build dir below publishDir and
+// points the current symlink to it when the build succeeds. A build that
+// logged errors, e.g. template errors, which don't fail the build, is not
+// activated.
+func (c *commandeer) buildAtomic() error {
+	root := c.PathSpec().AbsPathify(c.Cfg.GetString("publishDir"))
+
+	dir, err := publish.NewBuild(root, time.Now())
+	if err != nil {
+		return newSystemError("Error creating build dir:", err)
+	}
+
+	c.Cfg.Set("publishDir", dir)
+
+	errorCount := func() uint64 {
+		return c.Logger.LogCountForLevelsGreaterThanorEqualTo(notepad.LevelError) +
+			notepad.LogCountForLevelsGreaterThanorEqualTo(notepad.LevelError)
+	}
+	errorsBefore := errorCount()
+
+	if err := c.build(); err != nil {
+		// Don't leave a half-written build behind.
+		os.RemoveAll(dir)
+		return err
+	}
+
+	if errorCount() > errorsBefore {
+		os.RemoveAll(dir)
+		return newUserError("Build not published: errors were logged while building", filepath.Base(dir))
+	}
+
+	if err := publish.Activate(root, filepath.Base(dir)); err != nil {
+		return newSystemError("Error activating build:", err)
+	}
+
+	c.Logger.FEEDBACK.Println("Published build", filepath.Base(dir))
+
+	removed, err := publish.Prune(root, c.Cfg.GetInt("keepBuilds"))
+	if err != nil {
+		return newSystemError("Error removing old builds:", err)
+	}
+	for _, name := range removed {
+		c.Logger.INFO.Println("Removed old build", name)
+	}
+
+	return nil
+}
+
+// cleanStaleOutput removes the files written by the previous build that
+// were not written by this one, as recorded in the build manifest.
+func (c *commandeer) cleanStaleOutput() error {
+	publishDir := c.PathSpec().AbsPathify(c.Cfg.GetString("publishDir"))
+
+	published := Hugo.PublishedFiles()
+	for filename := range c.staticFiles {
+		published = append(published, filename)
+	}
+
+	removed, err := geanlib.CleanStaleOutput(c.Fs.Destination, publishDir, published)
+	if err != nil {
+		return err
+	}
+
+	if len(removed) > 0 && !quiet {
+		c.Logger.FEEDBACK.Printf("Removed %d stale file(s) from %s:\n", len(removed), publishDir)
+		for _, rel := range removed {
+			c.Logger.FEEDBACK.Println("  " + rel)
+		}
+	}
+
+	return nil
+}
+
+func rollback(cmd *goman.Command, args []string) error {
+	cfg, err := InitializeConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	c, err := newCommandeer(cfg)
+	if err != nil {
+		return err
+	}
+
+	root := c.PathSpec().AbsPathify(c.Cfg.GetString("publishDir"))
+
+	if rollbackList {
+		builds, err := publish.List(root)
+		if err != nil {
+			return newSystemError(err)
+		}
+		for _, b := range builds {
+			if b.Current {
+				notepad.FEEDBACK.Println(b.Name, "(current)")
+			} else {
+				notepad.FEEDBACK.Println(b.Name)
+			}
+		}
+		return nil
+	}
+
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		name, err = publish.Previous(root)
+		if err != nil {
+			return newUserError(err)
+		}
+	}
+
+	if err := publish.Activate(root, name); err != nil {
+		return newUserError(err)
+	}
+
+	notepad.FEEDBACK.Println("Rolled back to build", name)
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package command
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/geego/gean/app/deps"
+	"github.com/geego/gean/app/geanfs"
+	"github.com/geego/gean/app/geanlib"
+	"github.com/geego/gean/app/publish"
+	"github.com/govenue/fsintra"
+	"github.com/govenue/notepad"
+	"github.com/govenue/require"
+)
+
+func TestBuildAtomicWithErrors(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skip TestBuildAtomicWithErrors as os.Symlink needs administrator rights on Windows")
+	}
+
+	assert := require.New(t)
+
+	root, err := ioutil.TempDir("", "gean-publish")
+	assert.NoError(err)
+	defer os.RemoveAll(root)
+
+	for filename, content := range map[string]string{
+		"config.toml": `
+baseURL = "http://example.com/"
+disableKinds = ["taxonomy", "taxonomyTerm", "sitemap", "robotsTXT", "404", "RSS"]
+`,
+		"content/p1.md":                "---\ntitle: P1\nnums: [1]\n---\nContent",
+		"layouts/_default/single.html": `{{ index .Params.nums 5 }}`,
+		"layouts/_default/list.html":   `List|{{ .Title }}`,
+	} {
+		filename = filepath.Join(root, filepath.FromSlash(filename))
+		assert.NoError(os.MkdirAll(filepath.Dir(filename), 0755))
+		assert.NoError(ioutil.WriteFile(filename, []byte(content), 0644))
+	}
+
+	cfg, err := geanlib.LoadConfig(fsintra.NewOsFs(), root, filepath.Join(root, "config.toml"))
+	assert.NoError(err)
+	cfg.Set("workingDir", root)
+	cfg.Set("publishDir", filepath.Join(root, "public"))
+
+	logger := notepad.NewNotepad(notepad.LevelError, notepad.LevelError, ioutil.Discard, ioutil.Discard, "", log.Ldate|log.Ltime)
+
+	c, err := newCommandeer(&deps.DepsCfg{Cfg: cfg, Fs: geanfs.NewDefault(cfg), Logger: logger})
+	assert.NoError(err)
+
+	oldHugo := Hugo
+	Hugo = nil
+	defer func() { Hugo = oldHugo }()
+
+	err = c.buildAtomic()
+	assert.Error(err)
+	assert.Contains(err.Error(), "not published")
+
+	builds, err := publish.List(filepath.Join(root, "public"))
+	assert.NoError(err)
+	assert.Empty(builds)
+
+	_, err = os.Lstat(filepath.Join(root, "public", publish.CurrentLink))
+	assert.True(os.IsNotExist(err))
+}
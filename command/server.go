@@ -33,6 +33,7 @@ var (
 	noHTTPCache       bool
 
 	disableFastRender bool
+	serverInspector   bool
 )
 
 var serverCmd = &goman.Command{
@@ -87,6 +88,7 @@ func init() {
 	serverCmd.Flags().BoolVar(&navigateToChanged, "navigateToChanged", false, "navigate to changed content file on live browser reload")
 	serverCmd.Flags().BoolVar(&renderToDisk, "renderToDisk", false, "render to Destination path (default is render to memory & serve from there)")
 	serverCmd.Flags().BoolVar(&disableFastRender, "disableFastRender", false, "enables full re-renders on changes")
+	serverCmd.Flags().BoolVar(&serverInspector, "inspector", false, "enable the site inspector at "+inspectorPath)
 
 	serverCmd.Flags().String("memstats", "", "log memory usage to this file")
 	serverCmd.Flags().String("meminterval", "100ms", "interval to poll memory usage (requires --memstats), valid time units are \"ns\", \"us\" (or \"µs\"), \"ms\", \"s\", \"m\", \"h\".")
@@ -122,6 +124,10 @@ func server(cmd *goman.Command, args []string) error {
 		c.Set("watch", true)
 	}
 
+	if isInspectorEnabled(c.Cfg) {
+		c.Set("trackRenderInfo", true)
+	}
+
 	if c.Cfg.GetBool("watch") {
 		serverWatch = true
 		c.watchConfig()
@@ -185,6 +191,11 @@ func server(cmd *goman.Command, args []string) error {
 		c.Set("baseURL", baseURL)
 	}
 
+	// Validate the proxy config before we start building.
+	if _, err := decodeProxyRules(c.Cfg); err != nil {
+		return newUserError(err)
+	}
+
 	if err := memStats(); err != nil {
 		notepad.ERROR.Println("memstats error:", err)
 	}
@@ -299,6 +310,34 @@ func (f *fileServer) createEndpoint(i int) (*http.ServeMux, string, string, erro
 	fileserver := decorate(http.FileServer(fs))
 	mu := http.NewServeMux()
 
+	proxyRules, err := decodeProxyRules(f.c.Cfg)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	// Mount the proxies first so they take precedence over any published
+	// file with the same path prefix.
+	if err := mountProxies(mu, proxyRules); err != nil {
+		return nil, "", "", err
+	}
+
+	if i == 0 {
+		for _, rule := range proxyRules {
+			notepad.FEEDBACK.Printf("Proxying %s to %s\n", rule.Path, rule.Upstream)
+		}
+	}
+
+	if isInspectorEnabled(f.c.Cfg) {
+		siteIdx := -1
+		if f.c.languages().IsMultihost() {
+			siteIdx = i
+		}
+		mu.Handle(inspectorPath, newInspector(absPublishDir, u.Path, siteIdx))
+		if i == 0 {
+			notepad.FEEDBACK.Printf("Site inspector is available at %s\n", strings.TrimSuffix(u.String(), u.Path)+inspectorPath)
+		}
+	}
+
 	if u.Path == "" || u.Path == "/" {
 		mu.Handle("/", fileserver)
 	} else {
@@ -344,6 +383,10 @@ func (c *commandeer) serve() {
 
 	for i, _ := range baseURLs {
 		mu, serverURL, endpoint, err := srv.createEndpoint(i)
+		if err != nil {
+			notepad.ERROR.Printf("Error: %s\n", err.Error())
+			os.Exit(1)
+		}
 
 		if doLiveReload {
 			mu.HandleFunc("/livereload.js", livereload.ServeJS)
@@ -0,0 +1,413 @@
+package command
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/geego/gean/app/common/types"
+	"github.com/geego/gean/app/config"
+	"github.com/geego/gean/app/geanlib"
+	"github.com/govenue/assist"
+)
+
+// inspectorPath is the URL path the inspector is mounted on in the
+// development server.
+const inspectorPath = "/__gean/"
+
+// buildWarnings holds the most recent warnings and errors logged.
+// It is only written to when the inspector is enabled.
+var buildWarnings = newLogRecorder(200)
+
+// isInspectorEnabled returns whether the inspector is enabled, either by
+// the --inspector flag or in the server section of the site config.
+func isInspectorEnabled(cfg config.Provider) bool {
+	if serverInspector {
+		return true
+	}
+	serverConfig := cfg.GetStringMap("server")
+	if serverConfig == nil {
+		return false
+	}
+	return assist.ToBool(serverConfig["inspector"])
+}
+
+// logRecorder is an io.Writer that keeps the last WARN and ERROR
+// log lines written to it.
+type logRecorder struct {
+	q *types.EvictingStringQueue
+}
+
+func newLogRecorder(size int) *logRecorder {
+	return &logRecorder{q: types.NewEvictingStringQueue(size)}
+}
+
+func (l *logRecorder) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(string(p), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "WARN") || strings.HasPrefix(line, "ERROR") {
+			l.q.Add(line)
+		}
+	}
+	return len(p), nil
+}
+
+// Lines returns the recorded lines, newest first.
+func (l *logRecorder) Lines() []string {
+	return l.q.PeekAll()
+}
+
+// inspector serves the introspection UI for one server endpoint.
+type inspector struct {
+	// The absolute publish dir served by this endpoint.
+	absPublishDir string
+
+	// The path part of the baseURL.
+	basePath string
+
+	// The site index in Hugo.Sites when in multihost mode, else -1.
+	siteIdx int
+}
+
+func newInspector(absPublishDir, basePath string, siteIdx int) *inspector {
+	return &inspector{
+		absPublishDir: absPublishDir,
+		basePath:      basePath,
+		siteIdx:       siteIdx,
+	}
+}
+
+func (i *inspector) sites() []*geanlib.Site {
+	if Hugo == nil {
+		return nil
+	}
+	if i.siteIdx >= 0 && i.siteIdx < len(Hugo.Sites) {
+		return Hugo.Sites[i.siteIdx : i.siteIdx+1]
+	}
+	return Hugo.Sites
+}
+
+func (i *inspector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	var (
+		name string
+		data interface{}
+	)
+
+	switch strings.TrimPrefix(r.URL.Path, inspectorPath) {
+	case "":
+		name, data = "index", i.indexData()
+	case "page":
+		name, data = "page", i.pageData(r.URL.Query().Get("url"))
+	case "tree":
+		name, data = "tree", i.treeData()
+	case "taxonomies":
+		name, data = "taxonomies", i.taxonomiesData()
+	case "warnings":
+		name, data = "warnings", buildWarnings.Lines()
+	case "metrics":
+		name, data = "metrics", i.metricsData()
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	var b bytes.Buffer
+	if err := inspectorTemplates.ExecuteTemplate(&b, name, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.Copy(w, &b)
+}
+
+type inspectorIndex struct {
+	Files int
+	Infos []*geanlib.RenderInfo
+}
+
+func (i *inspector) indexData() inspectorIndex {
+	var infos []*geanlib.RenderInfo
+	if Hugo != nil {
+		for _, info := range Hugo.RenderInfos() {
+			if strings.HasPrefix(info.Filename, i.absPublishDir) {
+				infos = append(infos, info)
+			}
+		}
+	}
+	return inspectorIndex{Files: len(infos), Infos: infos}
+}
+
+type inspectorParam struct {
+	Key   string
+	Value interface{}
+}
+
+type inspectorParamsByKey []inspectorParam
+
+func (p inspectorParamsByKey) Len() int           { return len(p) }
+func (p inspectorParamsByKey) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p inspectorParamsByKey) Less(i, j int) bool { return p[i].Key < p[j].Key }
+
+type inspectorPage struct {
+	URL      string
+	Filename string
+	Info     *geanlib.RenderInfo
+	Params   []inspectorParam
+}
+
+// filenameFor resolves the given URL to an absolute filename in the
+// publish dir.
+func (i *inspector) filenameFor(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return ""
+	}
+
+	p := u.Path
+	if i.basePath != "" && i.basePath != "/" {
+		p = strings.TrimPrefix(p, strings.TrimSuffix(i.basePath, "/"))
+	}
+
+	if p == "" || strings.HasSuffix(p, "/") {
+		p += "index.html"
+	} else if path.Ext(p) == "" {
+		p += "/index.html"
+	}
+
+	return filepath.Join(i.absPublishDir, filepath.FromSlash(p))
+}
+
+func (i *inspector) pageData(rawurl string) inspectorPage {
+	data := inspectorPage{URL: rawurl}
+
+	if rawurl == "" || Hugo == nil {
+		return data
+	}
+
+	data.Filename = i.filenameFor(rawurl)
+	data.Info = Hugo.RenderInfo(data.Filename)
+
+	if data.Info != nil && data.Info.Page != nil {
+		for k, v := range data.Info.Page.Params {
+			data.Params = append(data.Params, inspectorParam{Key: k, Value: v})
+		}
+		sort.Sort(inspectorParamsByKey(data.Params))
+	}
+
+	return data
+}
+
+type inspectorTreeNode struct {
+	Page     *geanlib.Page
+	Regular  int
+	Children []inspectorTreeNode
+}
+
+type inspectorSiteTree struct {
+	Lang string
+	Root inspectorTreeNode
+}
+
+func newInspectorTreeNode(p *geanlib.Page) inspectorTreeNode {
+	node := inspectorTreeNode{Page: p}
+	for _, pp := range p.Pages {
+		if pp.IsPage() {
+			node.Regular++
+		}
+	}
+	for _, s := range p.Sections() {
+		node.Children = append(node.Children, newInspectorTreeNode(s))
+	}
+	return node
+}
+
+func (i *inspector) treeData() []inspectorSiteTree {
+	var trees []inspectorSiteTree
+	for _, s := range i.sites() {
+		home, err := s.Info.Home()
+		if err != nil || home == nil {
+			continue
+		}
+		trees = append(trees, inspectorSiteTree{Lang: s.Language.Lang, Root: newInspectorTreeNode(home)})
+	}
+	return trees
+}
+
+type inspectorTaxonomy struct {
+	Lang    string
+	Plural  string
+	Entries geanlib.OrderedTaxonomy
+}
+
+func (i *inspector) taxonomiesData() []inspectorTaxonomy {
+	var taxonomies []inspectorTaxonomy
+	for _, s := range i.sites() {
+		var plurals []string
+		for plural := range s.Taxonomies {
+			plurals = append(plurals, plural)
+		}
+		sort.Strings(plurals)
+
+		for _, plural := range plurals {
+			taxonomies = append(taxonomies, inspectorTaxonomy{
+				Lang:    s.Language.Lang,
+				Plural:  plural,
+				Entries: s.Taxonomies[plural].Alphabetical(),
+			})
+		}
+	}
+	return taxonomies
+}
+
+func (i *inspector) metricsData() string {
+	if Hugo == nil || Hugo.Metrics == nil {
+		return ""
+	}
+	var b bytes.Buffer
+	Hugo.Metrics.WriteMetrics(&b)
+	return b.String()
+}
+
+var inspectorTemplates = template.Must(template.New("").Funcs(template.FuncMap{
+	"inspectorPath": func() string { return inspectorPath },
+}).Parse(`
+{{ define "header" }}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>gean inspector</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+nav a { margin-right: 1em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+td, th { border: 1px solid #ccc; padding: 0.2em 0.5em; text-align: left; vertical-align: top; }
+code, pre { font-size: 0.9em; }
+</style>
+</head>
+<body>
+<nav>
+<a href="{{ inspectorPath }}">Inspector</a>
+<a href="{{ inspectorPath }}tree">Site tree</a>
+<a href="{{ inspectorPath }}taxonomies">Taxonomies</a>
+<a href="{{ inspectorPath }}warnings">Warnings</a>
+<a href="{{ inspectorPath }}metrics">Template metrics</a>
+</nav>
+<form action="{{ inspectorPath }}page">
+<input type="text" name="url" size="60" placeholder="/posts/my-post/" value="{{ . }}">
+<input type="submit" value="Inspect">
+</form>
+{{ end }}
+
+{{ define "footer" }}</body>
+</html>
+{{ end }}
+
+{{ define "index" }}{{ template "header" "" }}
+<h1>Rendered files ({{ .Files }})</h1>
+<table>
+<tr><th>File</th><th>Template</th><th>Duration</th></tr>
+{{ range .Infos }}
+<tr><td><code>{{ .Filename }}</code></td><td><code>{{ .Template }}</code></td><td>{{ .Duration }}</td></tr>
+{{ end }}
+</table>
+{{ template "footer" }}{{ end }}
+
+{{ define "page" }}{{ template "header" .URL }}
+{{ if not .URL }}
+<p>Enter a URL to inspect.</p>
+{{ else if not .Info }}
+<p>No render info found for <code>{{ .URL }}</code> (<code>{{ .Filename }}</code>).</p>
+{{ else }}
+{{ with .Info }}
+<h1>{{ with .Page }}{{ .Title }}{{ else }}{{ .Filename }}{{ end }}</h1>
+<table>
+<tr><th>File</th><td><code>{{ .Filename }}</code></td></tr>
+<tr><th>Template</th><td><code>{{ .Template }}</code></td></tr>
+<tr><th>Layouts tried</th><td>{{ range .Layouts }}<code>{{ . }}</code><br>{{ end }}</td></tr>
+<tr><th>Rendered</th><td>{{ .Time.Format "15:04:05.000" }} in {{ .Duration }}</td></tr>
+{{ with .Page }}
+<tr><th>Kind</th><td>{{ .Kind }}</td></tr>
+<tr><th>Type</th><td>{{ .Type }}</td></tr>
+<tr><th>Section</th><td>{{ .Section }}</td></tr>
+<tr><th>Language</th><td>{{ .Lang }}</td></tr>
+<tr><th>Source</th><td><code>{{ .FullFilePath }}</code></td></tr>
+{{ end }}
+<tr><th>Output format</th><td>{{ .OutputFormat.Name }}</td></tr>
+{{ with .Page }}
+<tr><th>Output formats</th><td>{{ range .OutputFormats }}<a href="{{ .RelPermalink }}">{{ .Name }}</a> {{ end }}</td></tr>
+{{ end }}
+</table>
+<h2>Partials</h2>
+{{ with .Partials }}
+<table>
+<tr><th>Partial</th><th>Duration</th></tr>
+{{ range . }}<tr><td><code>{{ .Name }}</code></td><td>{{ .Duration }}</td></tr>{{ end }}
+</table>
+{{ else }}
+<p>No partials executed with the page as context.</p>
+{{ end }}
+{{ end }}
+<h2>Params</h2>
+<table>
+{{ range .Params }}<tr><th>{{ .Key }}</th><td><code>{{ printf "%#v" .Value }}</code></td></tr>{{ end }}
+</table>
+{{ end }}
+{{ template "footer" }}{{ end }}
+
+{{ define "treenode" }}
+<li>{{ with .Page }}<a href="{{ inspectorPath }}page?url={{ .RelPermalink }}">{{ if .Title }}{{ .Title }}{{ else }}{{ .RelPermalink }}{{ end }}</a> <small>{{ .Kind }}</small>{{ end }} ({{ .Regular }} pages)
+{{ with .Children }}<ul>{{ range . }}{{ template "treenode" . }}{{ end }}</ul>{{ end }}
+</li>
+{{ end }}
+
+{{ define "tree" }}{{ template "header" "" }}
+<h1>Site tree</h1>
+{{ range . }}
+<h2>{{ .Lang }}</h2>
+<ul>{{ template "treenode" .Root }}</ul>
+{{ end }}
+{{ template "footer" }}{{ end }}
+
+{{ define "taxonomies" }}{{ template "header" "" }}
+<h1>Taxonomies</h1>
+{{ range . }}
+<h2>{{ .Plural }} <small>{{ .Lang }}</small></h2>
+<table>
+<tr><th>Term</th><th>Count</th><th>Pages</th></tr>
+{{ range .Entries }}
+<tr><td>{{ .Term }}</td><td>{{ .Count }}</td><td>{{ range .Pages }}<a href="{{ inspectorPath }}page?url={{ .RelPermalink }}">{{ .Title }}</a><br>{{ end }}</td></tr>
+{{ end }}
+</table>
+{{ else }}
+<p>No taxonomies.</p>
+{{ end }}
+{{ template "footer" }}{{ end }}
+
+{{ define "warnings" }}{{ template "header" "" }}
+<h1>Build warnings</h1>
+{{ with . }}
+<pre>{{ range . }}{{ . }}
+{{ end }}</pre>
+{{ else }}
+<p>No warnings.</p>
+{{ end }}
+{{ template "footer" }}{{ end }}
+
+{{ define "metrics" }}{{ template "header" "" }}
+<h1>Template metrics</h1>
+{{ with . }}
+<pre>{{ . }}</pre>
+{{ else }}
+<p>Start the server with <code>--templateMetrics</code> to collect template metrics.</p>
+{{ end }}
+{{ template "footer" }}{{ end }}
+`))
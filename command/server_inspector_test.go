@@ -0,0 +1,127 @@
+package command
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/geego/gean/app/deps"
+	"github.com/geego/gean/app/geanfs"
+	"github.com/geego/gean/app/geanlib"
+	"github.com/govenue/fsintra"
+	"github.com/govenue/require"
+)
+
+func TestInspectorHandlers(t *testing.T) {
+	assert := require.New(t)
+
+	mf := fsintra.NewMemMapFs()
+
+	for filename, content := range map[string]string{
+		"config.toml": `
+baseURL = "http://example.com/"
+trackRenderInfo = true
+templateMetrics = true
+disableKinds = ["sitemap", "robotsTXT", "404", "RSS"]
+`,
+		"content/sect/p1.md":             "---\ntitle: P1\ntags: [gean]\ncolor: blue\n---\nContent",
+		"layouts/_default/single.html":   `{{ partial "head.html" . }}|{{ .Title }}`,
+		"layouts/_default/list.html":     `List|{{ .Title }}`,
+		"layouts/_default/terms.html":    `Terms|{{ .Title }}`,
+		"layouts/partials/head.html":     `head`,
+		"layouts/_default/taxonomy.html": `Taxonomy|{{ .Title }}`,
+	} {
+		assert.NoError(fsintra.WriteFile(mf, filepath.FromSlash(filename), []byte(content), 0755))
+	}
+
+	cfg, err := geanlib.LoadConfig(mf, "", "config.toml")
+	assert.NoError(err)
+
+	h, err := geanlib.NewHugoSites(deps.DepsCfg{Fs: geanfs.NewFrom(mf, cfg), Cfg: cfg})
+	assert.NoError(err)
+	assert.NoError(h.Build(geanlib.BuildCfg{}))
+
+	oldHugo := Hugo
+	Hugo = h
+	defer func() { Hugo = oldHugo }()
+
+	buildWarnings.Write([]byte("WARN 2017/10/19 inspector test warning\nINFO not recorded\n"))
+
+	absPublishDir := h.Sites[0].PathSpec.AbsPathify(cfg.GetString("publishDir"))
+
+	srv := httptest.NewServer(newInspector(absPublishDir, "/", -1))
+	defer srv.Close()
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := http.Get(srv.URL + path)
+		assert.NoError(err)
+		defer resp.Body.Close()
+		b, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(err)
+		return resp, string(b)
+	}
+
+	for _, test := range []struct {
+		path   string
+		expect []string
+	}{
+		{inspectorPath, []string{"Rendered files", filepath.FromSlash("sect/p1/index.html"), "_default/single.html"}},
+		{inspectorPath + "page?url=/sect/p1/", []string{"<h1>P1</h1>", "_default/single.html", "partials/head.html", "<th>color</th>"}},
+		{inspectorPath + "page?url=/nosuch/", []string{"No render info found"}},
+		{inspectorPath + "page", []string{"Enter a URL to inspect."}},
+		{inspectorPath + "tree", []string{"Site tree", "page?url=/sect/", "(1 pages)"}},
+		{inspectorPath + "taxonomies", []string{"<h2>tags", "<td>gean</td>", "page?url=/sect/p1/"}},
+		{inspectorPath + "warnings", []string{"WARN 2017/10/19 inspector test warning"}},
+		{inspectorPath + "metrics", []string{"_default/single.html"}},
+	} {
+		resp, body := get(test.path)
+		assert.Equal(http.StatusOK, resp.StatusCode, test.path)
+		assert.Equal("no-store", resp.Header.Get("Cache-Control"), test.path)
+		assert.Equal("text/html; charset=utf-8", resp.Header.Get("Content-Type"), test.path)
+		for _, s := range test.expect {
+			assert.Contains(body, s, test.path)
+		}
+	}
+
+	_, body := get(inspectorPath + "warnings")
+	assert.NotContains(body, "not recorded")
+
+	resp, _ := get(inspectorPath + "nosuch")
+	assert.Equal(http.StatusNotFound, resp.StatusCode)
+}
+
+func TestInspectorWithoutSites(t *testing.T) {
+	assert := require.New(t)
+
+	oldHugo := Hugo
+	Hugo = nil
+	defer func() { Hugo = oldHugo }()
+
+	srv := httptest.NewServer(newInspector("/public", "/", -1))
+	defer srv.Close()
+
+	for _, path := range []string{"", "page?url=/", "tree", "taxonomies", "metrics"} {
+		resp, err := http.Get(srv.URL + inspectorPath + path)
+		assert.NoError(err)
+		resp.Body.Close()
+		assert.Equal(http.StatusOK, resp.StatusCode, path)
+	}
+}
+
+func TestInspectorFilenameFor(t *testing.T) {
+	i := newInspector(filepath.FromSlash("/public"), "/docs/", -1)
+
+	for _, test := range []struct {
+		url    string
+		expect string
+	}{
+		{"/docs/", "/public/index.html"},
+		{"/docs/sect/p1/", "/public/sect/p1/index.html"},
+		{"/docs/sect/p1", "/public/sect/p1/index.html"},
+		{"/docs/index.xml?page=2", "/public/index.xml"},
+	} {
+		require.Equal(t, filepath.FromSlash(test.expect), i.filenameFor(test.url), test.url)
+	}
+}
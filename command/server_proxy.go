@@ -0,0 +1,189 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	"github.com/geego/gean/app/config"
+	"github.com/govenue/mapstructure"
+)
+
+// proxyRule configures a reverse proxy mounted in the development server.
+//
+// An example site config.toml:
+//
+//	[[server.proxy]]
+//	path = "/api/"
+//	upstream = "http://localhost:8080"
+//	stripPrefix = true
+//	websocket = true
+//	[server.proxy.headers]
+//	Authorization = "Bearer dev-token"
+//	[server.proxy.responseHeaders]
+//	Access-Control-Allow-Origin = "*"
+type proxyRule struct {
+	// The URL path prefix to proxy, e.g. "/api/". A trailing slash is
+	// added if missing, so "/api" proxies everything below /api/.
+	Path string
+
+	// The URL of the upstream server requests are forwarded to.
+	Upstream string
+
+	// Remove Path from the request URL before forwarding it.
+	StripPrefix bool
+
+	// Allow websocket upgrades to pass through to the upstream.
+	Websocket bool
+
+	// Headers set on the request before it is forwarded.
+	// An empty value removes the header.
+	Headers map[string]string
+
+	// Headers set on the response returned from the upstream.
+	// An empty value removes the header.
+	ResponseHeaders map[string]string
+}
+
+// decodeProxyRules reads the proxy rules from the server section of
+// the site config.
+func decodeProxyRules(cfg config.Provider) ([]proxyRule, error) {
+	serverConfig := cfg.GetStringMap("server")
+	if serverConfig == nil {
+		return nil, nil
+	}
+
+	v, found := serverConfig["proxy"]
+	if !found {
+		return nil, nil
+	}
+
+	var rules []proxyRule
+
+	if err := mapstructure.WeakDecode(v, &rules); err != nil {
+		return nil, fmt.Errorf("failed to decode server proxy config: %s", err)
+	}
+
+	seen := make(map[string]bool)
+
+	for i, rule := range rules {
+		if rule.Path == "" {
+			return nil, errors.New("server proxy: path must be set")
+		}
+		if !strings.HasPrefix(rule.Path, "/") {
+			rule.Path = "/" + rule.Path
+		}
+		if !strings.HasSuffix(rule.Path, "/") {
+			// http.ServeMux only matches subtrees for paths ending in a slash.
+			rule.Path += "/"
+		}
+		rules[i].Path = rule.Path
+		if rule.Path == "/" {
+			return nil, errors.New("server proxy: path \"/\" would shadow the entire site")
+		}
+		if seen[rule.Path] {
+			return nil, fmt.Errorf("server proxy %q: path configured more than once", rule.Path)
+		}
+		seen[rule.Path] = true
+		if rule.Upstream == "" {
+			return nil, fmt.Errorf("server proxy %q: upstream must be set", rule.Path)
+		}
+		if _, err := parseUpstream(rule); err != nil {
+			return nil, err
+		}
+	}
+
+	return rules, nil
+}
+
+// newProxyHandler creates a http.Handler that forwards requests matching
+// the given rule to its upstream.
+func newProxyHandler(rule proxyRule) (http.Handler, error) {
+	target, err := parseUpstream(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	director := proxy.Director
+
+	proxy.Director = func(r *http.Request) {
+		if rule.StripPrefix {
+			p := strings.TrimPrefix(r.URL.Path, strings.TrimSuffix(rule.Path, "/"))
+			if !strings.HasPrefix(p, "/") {
+				p = "/" + p
+			}
+			r.URL.Path = p
+			r.URL.RawPath = ""
+		}
+
+		director(r)
+
+		// Most upstreams route on the Host header, so make the request
+		// look like it was sent to the upstream directly.
+		r.Host = target.Host
+
+		for k, v := range rule.Headers {
+			if v == "" {
+				r.Header.Del(k)
+			} else {
+				r.Header.Set(k, v)
+			}
+		}
+	}
+
+	if len(rule.ResponseHeaders) > 0 {
+		proxy.ModifyResponse = func(resp *http.Response) error {
+			for k, v := range rule.ResponseHeaders {
+				if v == "" {
+					resp.Header.Del(k)
+				} else {
+					resp.Header.Set(k, v)
+				}
+			}
+			return nil
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !rule.Websocket && isWebsocketUpgrade(r) {
+			http.Error(w, "websocket passthrough is not enabled for "+rule.Path, http.StatusBadRequest)
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	}), nil
+}
+
+// parseUpstream parses and validates the rule's upstream URL.
+func parseUpstream(rule proxyRule) (*url.URL, error) {
+	target, err := url.Parse(rule.Upstream)
+	if err != nil {
+		return nil, fmt.Errorf("server proxy %q: invalid upstream: %s", rule.Path, err)
+	}
+
+	if target.Scheme == "" || target.Host == "" {
+		return nil, fmt.Errorf("server proxy %q: upstream %q must be an absolute URL", rule.Path, rule.Upstream)
+	}
+
+	return target, nil
+}
+
+// mountProxies registers a handler for every proxy rule on the given mux.
+func mountProxies(mu *http.ServeMux, rules []proxyRule) error {
+	for _, rule := range rules {
+		h, err := newProxyHandler(rule)
+		if err != nil {
+			return err
+		}
+		mu.Handle(rule.Path, h)
+	}
+	return nil
+}
+
+func isWebsocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+		strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
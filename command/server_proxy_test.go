@@ -0,0 +1,129 @@
+package command
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/govenue/configurator"
+	"github.com/govenue/require"
+)
+
+func TestDecodeProxyRules(t *testing.T) {
+	assert := require.New(t)
+
+	v := configurator.New()
+	v.Set("server", map[string]interface{}{
+		"proxy": []map[string]interface{}{
+			{"path": "api/", "upstream": "http://localhost:8080", "stripPrefix": true},
+		},
+	})
+
+	rules, err := decodeProxyRules(v)
+	assert.NoError(err)
+	assert.Len(rules, 1)
+	assert.Equal("/api/", rules[0].Path)
+	assert.True(rules[0].StripPrefix)
+
+	v.Set("server", map[string]interface{}{
+		"proxy": []map[string]interface{}{
+			{"path": "/api/"},
+		},
+	})
+
+	_, err = decodeProxyRules(v)
+	assert.Error(err)
+
+	v.Set("server", map[string]interface{}{
+		"proxy": []map[string]interface{}{
+			{"path": "/", "upstream": "http://localhost:8080"},
+		},
+	})
+
+	_, err = decodeProxyRules(v)
+	assert.Error(err)
+
+	v.Set("server", map[string]interface{}{
+		"proxy": []map[string]interface{}{
+			{"path": "/api", "upstream": "http://localhost:8080"},
+		},
+	})
+
+	rules, err = decodeProxyRules(v)
+	assert.NoError(err)
+	assert.Equal("/api/", rules[0].Path)
+
+	for _, upstream := range []string{"localhost:8080", "/api", "http://%zz"} {
+		v.Set("server", map[string]interface{}{
+			"proxy": []map[string]interface{}{
+				{"path": "/api/", "upstream": upstream},
+			},
+		})
+
+		_, err = decodeProxyRules(v)
+		assert.Error(err, upstream)
+	}
+}
+
+func TestProxyHandler(t *testing.T) {
+	assert := require.New(t)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Upstream", "yes")
+		w.Write([]byte(r.URL.Path + "|" + r.Header.Get("Authorization") + "|" + r.Header.Get("Cookie")))
+	}))
+	defer upstream.Close()
+
+	mu := http.NewServeMux()
+	mu.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("static"))
+	})
+
+	assert.NoError(mountProxies(mu, []proxyRule{
+		{
+			Path:            "/api/",
+			Upstream:        upstream.URL,
+			StripPrefix:     true,
+			Headers:         map[string]string{"Authorization": "Bearer dev", "Cookie": ""},
+			ResponseHeaders: map[string]string{"X-Upstream": "", "X-Proxied": "gean"},
+		},
+		{
+			Path:     "/search/",
+			Upstream: upstream.URL,
+		},
+	}))
+
+	srv := httptest.NewServer(mu)
+	defer srv.Close()
+
+	get := func(path string, header http.Header) (*http.Response, string) {
+		req, err := http.NewRequest("GET", srv.URL+path, nil)
+		assert.NoError(err)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(err)
+		defer resp.Body.Close()
+		b, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(err)
+		return resp, string(b)
+	}
+
+	resp, body := get("/api/stats", http.Header{"Cookie": []string{"a=b"}})
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.Equal("/stats|Bearer dev|", body)
+	assert.Equal("gean", resp.Header.Get("X-Proxied"))
+	assert.Equal("", resp.Header.Get("X-Upstream"))
+
+	resp, body = get("/search/q", nil)
+	assert.Equal("/search/q||", body)
+	assert.Equal("yes", resp.Header.Get("X-Upstream"))
+
+	_, body = get("/index.html", nil)
+	assert.Equal("static", body)
+
+	resp, _ = get("/search/ws", http.Header{"Upgrade": []string{"websocket"}, "Connection": []string{"Upgrade"}})
+	assert.Equal(http.StatusBadRequest, resp.StatusCode)
+}